@@ -0,0 +1,202 @@
+// Package calendar computes deadlines in business hours: time only counts on working days,
+// between the opening and closing time of the configured time zone, and never on holidays.
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout of holiday dates
+const dateLayout = "2006-01-02"
+
+// maxHolidayDays bounds the length of a single holiday, longer ranges are most likely a typo in the year
+const maxHolidayDays = 366
+
+// Config is the business calendar as it is written in the config file
+type Config struct {
+	// TimeZone is the IANA time zone the working hours and holidays are in, UTC by default
+	TimeZone     string       `yaml:"time_zone"`
+	WorkingHours WorkingHours `yaml:"working_hours"`
+	// WorkingDays are the lowercase English weekday names, monday to friday by default
+	WorkingDays []string  `yaml:"working_days"`
+	Holidays    []Holiday `yaml:"holidays"`
+}
+
+// WorkingHours are the opening and closing time of a working day as "15:04", the closing time may be "24:00"
+type WorkingHours struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// Holiday is a single day off, or the days from From to To inclusive
+type Holiday struct {
+	Name string `yaml:"name"`
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// Calendar adds business time to points in time
+type Calendar struct {
+	loc         *time.Location
+	start       clock
+	end         clock
+	workingDays [7]bool
+	holidays    map[string]bool
+}
+
+// clock is a time of day
+type clock struct {
+	hour, minute int
+}
+
+// New validates the config and returns its calendar
+func New(cfg Config) (*Calendar, error) {
+	cal := Calendar{
+		loc:      time.UTC,
+		holidays: map[string]bool{},
+	}
+
+	if cfg.TimeZone != "" {
+		loc, err := time.LoadLocation(cfg.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("business calendar: unknown time zone %q: %w", cfg.TimeZone, err)
+		}
+		cal.loc = loc
+	}
+
+	var err error
+	if cal.start, err = parseClock(cfg.WorkingHours.Start, "09:00"); err != nil {
+		return nil, fmt.Errorf("business calendar: working hours start: %w", err)
+	}
+	if cal.end, err = parseClock(cfg.WorkingHours.End, "17:00"); err != nil {
+		return nil, fmt.Errorf("business calendar: working hours end: %w", err)
+	}
+	if !cal.start.before(cal.end) {
+		return nil, errors.New("business calendar: working hours must end after they start")
+	}
+
+	workingDays := cfg.WorkingDays
+	if len(workingDays) == 0 {
+		workingDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+	}
+	for _, v := range workingDays {
+		day, ok := parseWeekday(v)
+		if !ok {
+			return nil, fmt.Errorf("business calendar: unknown working day %q", v)
+		}
+		cal.workingDays[day] = true
+	}
+
+	for _, v := range cfg.Holidays {
+		if err := cal.addHoliday(v); err != nil {
+			return nil, fmt.Errorf("business calendar: holiday %q: %w", v.Name, err)
+		}
+	}
+
+	return &cal, nil
+}
+
+// Add returns the point in time when the given business duration has passed since start.
+// Time before opening, after closing, on days off and on holidays doesn't count, so a start outside
+// of the working hours counts from the next opening time. The result is in the calendar's time zone.
+func (rc *Calendar) Add(start time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return start
+	}
+
+	cur := start.In(rc.loc)
+	for {
+		if rc.IsWorkingDay(cur) {
+			// the wall clock is used for opening and closing, so that a DST change shifts neither of them
+			year, month, day := cur.Date()
+			open := time.Date(year, month, day, rc.start.hour, rc.start.minute, 0, 0, rc.loc)
+			closing := time.Date(year, month, day, rc.end.hour, rc.end.minute, 0, 0, rc.loc)
+
+			if cur.Before(open) {
+				cur = open
+			}
+			if cur.Before(closing) {
+				left := closing.Sub(cur)
+				if d <= left {
+					return cur.Add(d)
+				}
+				d -= left
+			}
+		}
+
+		year, month, day := cur.Date()
+		cur = time.Date(year, month, day+1, 0, 0, 0, 0, rc.loc)
+	}
+}
+
+// IsWorkingDay reports whether the day of t, in the calendar's time zone, is a working day and not a holiday
+func (rc *Calendar) IsWorkingDay(t time.Time) bool {
+	t = t.In(rc.loc)
+
+	return rc.workingDays[t.Weekday()] && !rc.holidays[t.Format(dateLayout)]
+}
+
+func (rc *Calendar) addHoliday(holiday Holiday) error {
+	from, err := time.ParseInLocation(dateLayout, holiday.From, rc.loc)
+	if err != nil {
+		return fmt.Errorf("invalid from date: %w", err)
+	}
+
+	to := from
+	if holiday.To != "" {
+		to, err = time.ParseInLocation(dateLayout, holiday.To, rc.loc)
+		if err != nil {
+			return fmt.Errorf("invalid to date: %w", err)
+		}
+	}
+
+	if to.Before(from) {
+		return errors.New("to date is before the from date")
+	}
+
+	for day := 0; ; day++ {
+		date := from.AddDate(0, 0, day)
+		if date.After(to) {
+			return nil
+		}
+		if day >= maxHolidayDays {
+			return fmt.Errorf("holiday is longer than %d days", maxHolidayDays)
+		}
+		rc.holidays[date.Format(dateLayout)] = true
+	}
+}
+
+func parseClock(value, fallback string) (clock, error) {
+	if value == "" {
+		value = fallback
+	}
+
+	// time.Parse rejects 24:00, which is the only way to close at midnight
+	if value == "24:00" {
+		return clock{hour: 24}, nil
+	}
+
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return clock{}, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+
+	return clock{hour: t.Hour(), minute: t.Minute()}, nil
+}
+
+func (rc clock) before(other clock) bool {
+	return rc.hour < other.hour || rc.hour == other.hour && rc.minute < other.minute
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+
+	return 0, false
+}
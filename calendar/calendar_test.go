@@ -0,0 +1,243 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func mustNew(t *testing.T, cfg Config) *Calendar {
+	t.Helper()
+
+	cal, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	return cal
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+
+	return loc
+}
+
+func TestCalendarAdd(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, berlin)
+	}
+
+	office := mustNew(t, Config{
+		TimeZone:     "Europe/Berlin",
+		WorkingHours: WorkingHours{Start: "09:00", End: "18:00"},
+		Holidays: []Holiday{
+			{Name: "christmas", From: "2026-12-24", To: "2026-12-26"},
+			{Name: "new year", From: "2026-12-31", To: "2027-01-01"},
+		},
+	})
+	everyDay := mustNew(t, Config{
+		TimeZone:     "Europe/Berlin",
+		WorkingHours: WorkingHours{Start: "09:00", End: "18:00"},
+		WorkingDays:  []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"},
+	})
+	roundTheClock := mustNew(t, Config{
+		TimeZone:     "Europe/Berlin",
+		WorkingHours: WorkingHours{Start: "00:00", End: "24:00"},
+		WorkingDays:  []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"},
+	})
+
+	tests := []struct {
+		name     string
+		calendar *Calendar
+		start    time.Time
+		duration time.Duration
+		want     time.Time
+	}{
+		{
+			name:     "within the working day",
+			calendar: office,
+			start:    at(2026, time.October, 14, 10, 0),
+			duration: 2 * time.Hour,
+			want:     at(2026, time.October, 14, 12, 0),
+		},
+		{
+			name:     "ends exactly at closing time",
+			calendar: office,
+			start:    at(2026, time.October, 14, 9, 0),
+			duration: 9 * time.Hour,
+			want:     at(2026, time.October, 14, 18, 0),
+		},
+		{
+			name:     "starts before opening",
+			calendar: office,
+			start:    at(2026, time.October, 14, 7, 0),
+			duration: time.Hour,
+			want:     at(2026, time.October, 14, 10, 0),
+		},
+		{
+			name:     "starts after closing",
+			calendar: office,
+			start:    at(2026, time.October, 14, 19, 0),
+			duration: time.Hour,
+			want:     at(2026, time.October, 15, 10, 0),
+		},
+		{
+			name:     "spans several working days",
+			calendar: office,
+			start:    at(2026, time.October, 19, 9, 0),
+			duration: 27 * time.Hour,
+			want:     at(2026, time.October, 21, 18, 0),
+		},
+		{
+			name:     "friday evening carries over the weekend",
+			calendar: office,
+			start:    at(2026, time.October, 16, 17, 55),
+			duration: time.Hour,
+			want:     at(2026, time.October, 19, 9, 55),
+		},
+		{
+			name:     "starts on a saturday",
+			calendar: office,
+			start:    at(2026, time.October, 17, 12, 0),
+			duration: 30 * time.Minute,
+			want:     at(2026, time.October, 19, 9, 30),
+		},
+		{
+			name:     "start in another time zone",
+			calendar: office,
+			start:    time.Date(2026, time.October, 14, 6, 30, 0, 0, time.UTC),
+			duration: time.Hour,
+			want:     at(2026, time.October, 14, 10, 0),
+		},
+		{
+			name:     "weekend with the end of daylight saving time",
+			calendar: office,
+			start:    at(2026, time.October, 23, 17, 0),
+			duration: 2 * time.Hour,
+			want:     at(2026, time.October, 26, 10, 0),
+		},
+		{
+			name:     "weekend with the start of daylight saving time",
+			calendar: office,
+			start:    at(2026, time.March, 27, 17, 30),
+			duration: time.Hour,
+			want:     at(2026, time.March, 30, 9, 30),
+		},
+		{
+			name:     "working day that starts daylight saving time keeps the opening time",
+			calendar: everyDay,
+			start:    at(2026, time.March, 28, 17, 30),
+			duration: time.Hour,
+			want:     at(2026, time.March, 29, 9, 30),
+		},
+		{
+			name:     "short day at the start of daylight saving time",
+			calendar: roundTheClock,
+			start:    at(2026, time.March, 28, 12, 0),
+			duration: 24 * time.Hour,
+			want:     at(2026, time.March, 29, 13, 0),
+		},
+		{
+			name:     "long day at the end of daylight saving time",
+			calendar: roundTheClock,
+			start:    at(2026, time.October, 24, 12, 0),
+			duration: 24 * time.Hour,
+			want:     at(2026, time.October, 25, 11, 0),
+		},
+		{
+			name:     "multi-day holiday followed by the weekend",
+			calendar: office,
+			start:    at(2026, time.December, 23, 17, 0),
+			duration: 2 * time.Hour,
+			want:     at(2026, time.December, 28, 10, 0),
+		},
+		{
+			name:     "holiday across the new year",
+			calendar: office,
+			start:    at(2026, time.December, 30, 17, 0),
+			duration: 2 * time.Hour,
+			want:     at(2027, time.January, 4, 10, 0),
+		},
+		{
+			name:     "starts on a holiday",
+			calendar: office,
+			start:    at(2026, time.December, 24, 10, 0),
+			duration: time.Hour,
+			want:     at(2026, time.December, 28, 10, 0),
+		},
+		{
+			name:     "zero duration",
+			calendar: office,
+			start:    at(2026, time.October, 17, 12, 0),
+			duration: 0,
+			want:     at(2026, time.October, 17, 12, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.calendar.Add(tt.start, tt.duration)
+			if !got.Equal(tt.want) {
+				t.Errorf("Add(%s, %s) = %s, want %s", tt.start, tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarIsWorkingDay(t *testing.T) {
+	cal := mustNew(t, Config{
+		TimeZone: "Asia/Tokyo",
+		Holidays: []Holiday{{Name: "golden week", From: "2026-05-03", To: "2026-05-06"}},
+	})
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{name: "weekday", t: time.Date(2026, time.May, 7, 12, 0, 0, 0, time.UTC), want: true},
+		{name: "saturday", t: time.Date(2026, time.May, 9, 12, 0, 0, 0, time.UTC), want: false},
+		{name: "first day of the holiday", t: time.Date(2026, time.May, 3, 12, 0, 0, 0, time.UTC), want: false},
+		{name: "last day of the holiday", t: time.Date(2026, time.May, 6, 12, 0, 0, 0, time.UTC), want: false},
+		{name: "holiday already started in the calendar's time zone", t: time.Date(2026, time.May, 5, 20, 0, 0, 0, time.UTC), want: false},
+		{name: "day after the holiday in the calendar's time zone", t: time.Date(2026, time.May, 6, 16, 0, 0, 0, time.UTC), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.IsWorkingDay(tt.t); got != tt.want {
+				t.Errorf("IsWorkingDay(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "unknown time zone", cfg: Config{TimeZone: "Mars/Olympus"}},
+		{name: "invalid opening time", cfg: Config{WorkingHours: WorkingHours{Start: "9am"}}},
+		{name: "closing before opening", cfg: Config{WorkingHours: WorkingHours{Start: "18:00", End: "09:00"}}},
+		{name: "closing at opening", cfg: Config{WorkingHours: WorkingHours{Start: "09:00", End: "09:00"}}},
+		{name: "unknown working day", cfg: Config{WorkingDays: []string{"funday"}}},
+		{name: "invalid holiday date", cfg: Config{Holidays: []Holiday{{From: "25.12.2026"}}}},
+		{name: "holiday ends before it starts", cfg: Config{Holidays: []Holiday{{From: "2026-12-26", To: "2026-12-24"}}}},
+		{name: "holiday longer than a year", cfg: Config{Holidays: []Holiday{{From: "2026-01-01", To: "2036-01-01"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("New() returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+// Package changerequest runs the maker-checker flow for changes to any resource type.
+// A resource registers an Applier, the engine stores proposed changes as change requests
+// and applies them once enough approvers signed off before the request expired.
+package changerequest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+	"github.com/fleimkeipa/maker-checker/util"
+)
+
+// Applier validates and applies the change requests of a resource type
+type Applier interface {
+	// Validate is called when a change is submitted, so that invalid changes never wait for approval
+	Validate(ctx context.Context, change *model.ChangeRequest) error
+	// Apply makes the change once the change request is approved
+	Apply(ctx context.Context, change *model.ChangeRequest) error
+}
+
+// Redactor is implemented by appliers whose payloads hold secrets, which are removed before
+// a change request is returned or written to the audit trail
+type Redactor interface {
+	Redact(payload json.RawMessage) json.RawMessage
+}
+
+// ApproverChecker is implemented by appliers that restrict who may decide on a change beyond the policy's
+// approver roles, e.g. to apply the segregation of duties rules of the resource
+type ApproverChecker interface {
+	CheckApprover(ctx context.Context, change *model.ChangeRequest, approverID string) error
+}
+
+// Auditor records change request events in the audit trail
+type Auditor interface {
+	Record(ctx context.Context, event model.AuditEvent) error
+}
+
+type Engine struct {
+	repo     interfaces.ChangeRequestInterfaces
+	audit    Auditor
+	policies model.ChangeRequestPolicies
+	appliers map[string]Applier
+}
+
+func NewEngine(repo interfaces.ChangeRequestInterfaces, audit Auditor, policies model.ChangeRequestPolicies) *Engine {
+	return &Engine{
+		repo:     repo,
+		audit:    audit,
+		policies: policies,
+		appliers: map[string]Applier{},
+	}
+}
+
+// Register makes the engine accept change requests for the resource type
+func (rc *Engine) Register(resourceType string, applier Applier) {
+	rc.appliers[resourceType] = applier
+}
+
+// Submit validates the proposed change and stores it as a pending change request
+func (rc *Engine) Submit(ctx context.Context, resourceType, resourceID, operation string, payload any) (*model.ChangeRequest, error) {
+	applier, err := rc.applier(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to encode change request payload", http.StatusInternalServerError)
+	}
+
+	policy := rc.policies.For(resourceType)
+	now := time.Now()
+	change := model.ChangeRequest{
+		RequestedAt:       now,
+		ExpiresAt:         now.Add(policy.TTL),
+		Payload:           rawPayload,
+		Approvals:         []model.ChangeApproval{},
+		ResourceType:      resourceType,
+		ResourceID:        resourceID,
+		Operation:         operation,
+		RequestedBy:       util.GetOwnerIDFromCtx(ctx),
+		Status:            model.ChangeRequestStatusPending,
+		RequiredApprovals: policy.RequiredApprovals,
+	}
+
+	if err := applier.Validate(ctx, &change); err != nil {
+		return nil, err
+	}
+
+	newChange, err := rc.repo.Create(ctx, &change)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to create change request", http.StatusInternalServerError)
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionChangeRequestSubmit,
+		ResourceType: model.AuditResourceChangeRequest,
+		ResourceID:   newChange.ID,
+		After:        rc.redact(newChange),
+	}); err != nil {
+		return nil, err
+	}
+
+	return rc.redact(newChange), nil
+}
+
+// Approve records the caller's approval and applies the change once the policy's required approvals are reached.
+// The requester can't approve their own change request.
+func (rc *Engine) Approve(ctx context.Context, changeID string, req model.ChangeRequestDecision) (*model.ChangeRequest, error) {
+	change, err := rc.open(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+
+	approverID := util.GetOwnerIDFromCtx(ctx)
+	if err := rc.checkApprover(ctx, change, approverID); err != nil {
+		return nil, err
+	}
+
+	applier, err := rc.applier(change.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	before := rc.redact(change)
+
+	approved, err := rc.repo.AddApproval(ctx, changeID, &model.ChangeApproval{
+		ApprovedAt: time.Now(),
+		ApproverID: approverID,
+		Comment:    req.Comment,
+	})
+	if errors.Is(err, interfaces.ErrChangeRequestClosed) {
+		return nil, pkg.NewError(err, "change request was decided meanwhile, has expired or you already approved it", http.StatusConflict)
+	}
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to approve change request", http.StatusInternalServerError)
+	}
+
+	if len(approved.Approvals) < approved.RequiredApprovals {
+		if err := rc.audit.Record(ctx, model.AuditEvent{
+			Action:       model.AuditActionChangeRequestApprove,
+			ResourceType: model.AuditResourceChangeRequest,
+			ResourceID:   changeID,
+			Before:       before,
+			After:        rc.redact(approved),
+		}); err != nil {
+			return nil, err
+		}
+
+		return rc.redact(approved), nil
+	}
+
+	// claim the change request first, so that concurrent approvals can't apply it twice
+	if err := rc.decide(ctx, approved, model.ChangeRequestStatusApproved, approverID, req.Comment); err != nil {
+		return nil, err
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionChangeRequestApprove,
+		ResourceType: model.AuditResourceChangeRequest,
+		ResourceID:   changeID,
+		Before:       before,
+		After:        rc.redact(approved),
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := applier.Apply(ctx, approved); err != nil {
+		return nil, rc.fail(ctx, approved, err)
+	}
+
+	return rc.redact(approved), nil
+}
+
+// Reject closes a pending change request without applying it
+func (rc *Engine) Reject(ctx context.Context, changeID string, req model.ChangeRequestDecision) (*model.ChangeRequest, error) {
+	change, err := rc.open(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+
+	deciderID := util.GetOwnerIDFromCtx(ctx)
+	if err := rc.checkApprover(ctx, change, deciderID); err != nil {
+		return nil, err
+	}
+
+	before := rc.redact(change)
+
+	if err := rc.decide(ctx, change, model.ChangeRequestStatusRejected, deciderID, req.Comment); err != nil {
+		return nil, err
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionChangeRequestReject,
+		ResourceType: model.AuditResourceChangeRequest,
+		ResourceID:   changeID,
+		Before:       before,
+		After:        rc.redact(change),
+	}); err != nil {
+		return nil, err
+	}
+
+	return rc.redact(change), nil
+}
+
+func (rc *Engine) GetByID(ctx context.Context, changeID string) (*model.ChangeRequest, error) {
+	if err := rc.expireStale(ctx); err != nil {
+		return nil, err
+	}
+
+	change, err := rc.repo.GetByID(ctx, changeID)
+	if err != nil {
+		return nil, pkg.NewError(err, "change request not found", http.StatusNotFound)
+	}
+
+	// change requests the caller can't decide on are not shown to them
+	if !rc.isApprover(ctx, change.ResourceType) {
+		return nil, pkg.NewError(errors.New("role is not an approver role"), "change request not found", http.StatusNotFound)
+	}
+
+	return rc.redact(change), nil
+}
+
+// List returns the change requests of the resource types the caller can decide on newest first, after marking
+// pending ones past their expiry as expired
+func (rc *Engine) List(ctx context.Context, opts model.ChangeRequestFindOpts) ([]model.ChangeRequest, error) {
+	if err := rc.expireStale(ctx); err != nil {
+		return nil, err
+	}
+
+	opts.ResourceTypes = make([]string, 0, len(rc.appliers))
+	for resourceType := range rc.appliers {
+		if rc.isApprover(ctx, resourceType) {
+			opts.ResourceTypes = append(opts.ResourceTypes, resourceType)
+		}
+	}
+
+	if opts.ResourceType.IsSended && !slices.Contains(opts.ResourceTypes, opts.ResourceType.Value) {
+		return []model.ChangeRequest{}, nil
+	}
+
+	changes, err := rc.repo.List(ctx, opts)
+	if err != nil {
+		return nil, pkg.NewError(err, "change requests not found", http.StatusNotFound)
+	}
+
+	for i := range changes {
+		changes[i] = *rc.redact(&changes[i])
+	}
+
+	return changes, nil
+}
+
+// HasPending reports whether a change request of the resource is waiting for approval, whoever the caller is
+func (rc *Engine) HasPending(ctx context.Context, resourceType, resourceID string) (bool, error) {
+	pending, err := rc.repo.List(ctx, model.ChangeRequestFindOpts{
+		PaginationOpts: model.PaginationOpts{Limit: 1},
+		ResourceType:   model.Filter{IsSended: true, Value: resourceType},
+		ResourceID:     model.Filter{IsSended: true, Value: resourceID},
+		Status:         model.Filter{IsSended: true, Value: model.ChangeRequestStatusPending},
+	})
+	if err != nil {
+		return false, pkg.NewError(err, "failed to find change requests", http.StatusInternalServerError)
+	}
+
+	return len(pending) > 0, nil
+}
+
+// isApprover reports whether the caller's role is an approver role of the resource type's policy
+func (rc *Engine) isApprover(ctx context.Context, resourceType string) bool {
+	return slices.Contains(rc.policies.For(resourceType).ApproverRoles, util.GetOwnerRoleFromCtx(ctx))
+}
+
+func (rc *Engine) applier(resourceType string) (Applier, error) {
+	applier, ok := rc.appliers[resourceType]
+	if !ok {
+		return nil, pkg.NewError(nil, "unknown change request resource type: "+resourceType, http.StatusUnprocessableEntity)
+	}
+
+	return applier, nil
+}
+
+// open returns the change request if it can still be decided
+func (rc *Engine) open(ctx context.Context, changeID string) (*model.ChangeRequest, error) {
+	change, err := rc.repo.GetByID(ctx, changeID)
+	if err != nil {
+		return nil, pkg.NewError(err, "change request not found", http.StatusNotFound)
+	}
+
+	if change.Status != model.ChangeRequestStatusPending {
+		return nil, pkg.NewError(nil, "change request is already "+change.Status, http.StatusConflict)
+	}
+
+	if !change.ExpiresAt.After(time.Now()) {
+		return nil, pkg.NewError(nil, "change request has expired", http.StatusConflict)
+	}
+
+	return change, nil
+}
+
+// checkApprover makes sure the caller has an approver role of the resource type's policy and is not the requester,
+// and passes the resource's own approver checks
+func (rc *Engine) checkApprover(ctx context.Context, change *model.ChangeRequest, approverID string) error {
+	if !rc.isApprover(ctx, change.ResourceType) {
+		return pkg.NewError(errors.New("role is not an approver role"), "you are not allowed to decide on this change request", http.StatusForbidden)
+	}
+
+	if approverID == change.RequestedBy {
+		return pkg.NewError(errors.New("approver is the requester"), "you cannot decide on your own change request", http.StatusForbidden)
+	}
+
+	if change.HasApproved(approverID) {
+		return pkg.NewError(nil, "you already approved this change request", http.StatusConflict)
+	}
+
+	if checker, ok := rc.appliers[change.ResourceType].(ApproverChecker); ok {
+		return checker.CheckApprover(ctx, change, approverID)
+	}
+
+	return nil
+}
+
+func (rc *Engine) decide(ctx context.Context, change *model.ChangeRequest, status, deciderID, comment string) error {
+	err := rc.repo.Decide(ctx, change.ID, status, deciderID, comment)
+	if errors.Is(err, interfaces.ErrChangeRequestClosed) {
+		return pkg.NewError(err, "change request was decided meanwhile or has expired", http.StatusConflict)
+	}
+	if err != nil {
+		return pkg.NewError(err, "failed to decide change request", http.StatusInternalServerError)
+	}
+
+	change.Status = status
+	change.DecidedBy = deciderID
+	change.DecidedAt = time.Now()
+	change.Comment = comment
+
+	return nil
+}
+
+// fail marks an approved change request that could not be applied and returns the apply error
+func (rc *Engine) fail(ctx context.Context, change *model.ChangeRequest, applyErr error) error {
+	before := rc.redact(change)
+
+	if err := rc.repo.MarkFailed(ctx, change.ID, applyErr.Error()); err != nil {
+		return pkg.NewError(err, "failed to mark change request as failed", http.StatusInternalServerError)
+	}
+
+	change.Status = model.ChangeRequestStatusFailed
+	change.Error = applyErr.Error()
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionChangeRequestFail,
+		ResourceType: model.AuditResourceChangeRequest,
+		ResourceID:   change.ID,
+		Before:       before,
+		After:        rc.redact(change),
+	}); err != nil {
+		return err
+	}
+
+	return applyErr
+}
+
+func (rc *Engine) expireStale(ctx context.Context) error {
+	if err := rc.repo.ExpireStale(ctx); err != nil {
+		return pkg.NewError(err, "failed to expire change requests", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// redact returns a copy of the change request with the payload redacted by the resource type's applier
+func (rc *Engine) redact(change *model.ChangeRequest) *model.ChangeRequest {
+	redacted := *change
+
+	if redactor, ok := rc.appliers[change.ResourceType].(Redactor); ok {
+		redacted.Payload = redactor.Redact(change.Payload)
+	}
+
+	return &redacted
+}
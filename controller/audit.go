@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fleimkeipa/maker-checker/uc"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditHandlers struct {
+	auditUC *uc.AuditUC
+}
+
+func NewAuditHandlers(uc *uc.AuditUC) *AuditHandlers {
+	return &AuditHandlers{
+		auditUC: uc,
+	}
+}
+
+// Verify godoc
+//
+//	@Summary		Verify verifies the audit trail
+//	@Description	This endpoint walks the hash-chained audit trail end to end and reports the first broken link, if any.
+//	@Tags			audit
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse	"audit verification result"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/audit/verify [get]
+func (rc *AuditHandlers) Verify(c echo.Context) error {
+	result, err := rc.auditUC.Verify(c.Request().Context())
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	message := "Audit trail is intact."
+	if !result.Valid {
+		message = "Audit trail is broken."
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    result,
+		Message: message,
+	})
+}
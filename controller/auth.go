@@ -132,18 +132,11 @@ func (rc *AuthHandlers) Login(c echo.Context) error {
 		})
 	}
 
-	user, err := rc.userUC.GetByUsernameOrEmail(c.Request().Context(), input.Username)
+	user, err := rc.userUC.Login(c.Request().Context(), input)
 	if err != nil {
 		return HandleEchoError(c, err)
 	}
 
-	if err := model.ValidateUserPassword(user.Password, input.Password); err != nil {
-		return c.JSON(http.StatusBadRequest, FailureResponse{
-			Error:   fmt.Sprintf("Invalid password: %v", err),
-			Message: "Invalid password. Please check the password and try again.",
-		})
-	}
-
 	jwt, err := util.GenerateJWT(user)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, FailureResponse{
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fleimkeipa/maker-checker/changerequest"
+	"github.com/fleimkeipa/maker-checker/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ChangeRequestHandlers struct {
+	engine *changerequest.Engine
+}
+
+func NewChangeRequestHandlers(engine *changerequest.Engine) *ChangeRequestHandlers {
+	return &ChangeRequestHandlers{
+		engine: engine,
+	}
+}
+
+// List godoc
+//
+//	@Summary		List lists change requests
+//	@Description	This endpoint lists the change requests of the resource types whose approver roles include the caller's role, newest first. Pending requests past their expiry are marked as expired.
+//	@Tags			change-requests
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			limit			query		int				false	"Change requests limit"
+//	@Param			skip			query		int				false	"Skip change requests"
+//	@Param			resource_type	query		string			false	"Resource type, e.g. user"
+//	@Param			resource_id		query		string			false	"Resource ID"
+//	@Param			status			query		string			false	"Status: pending, approved, rejected, expired, failed"
+//	@Success		200				{object}	SuccessResponse	"change requests"
+//	@Failure		403				{object}	FailureResponse	"Permission denied"
+//	@Failure		500				{object}	FailureResponse	"Interval error"
+//	@Router			/change-requests [get]
+func (rc *ChangeRequestHandlers) List(c echo.Context) error {
+	opts := model.ChangeRequestFindOpts{
+		PaginationOpts: getPagination(c),
+		ResourceType:   getFilter(c, "resource_type"),
+		ResourceID:     getFilter(c, "resource_id"),
+		Status:         getFilter(c, "status"),
+	}
+
+	changes, err := rc.engine.List(c.Request().Context(), opts)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    changes,
+		Message: "Change requests retrieved successfully.",
+	})
+}
+
+// GetByID godoc
+//
+//	@Summary		GetByID retrieves a change request
+//	@Description	This endpoint returns a change request with its payload and approvals. Change requests of resource types the caller's role can't approve are not found.
+//	@Tags			change-requests
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string			true	"Change request ID"
+//	@Success		200	{object}	SuccessResponse	"change request"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
+//	@Failure		404	{object}	FailureResponse	"Change request not found"
+//	@Router			/change-requests/{id} [get]
+func (rc *ChangeRequestHandlers) GetByID(c echo.Context) error {
+	id := c.Param("id")
+
+	change, err := rc.engine.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    change,
+		Message: "Change request retrieved successfully.",
+	})
+}
+
+// Approve godoc
+//
+//	@Summary		Approve approves a change request
+//	@Description	This endpoint records the caller's approval. The change is applied once the resource type's required approvals are reached. The requester cannot approve their own change request.
+//	@Tags			change-requests
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string						true	"Change request ID"
+//	@Param			body	body		model.ChangeRequestDecision	false	"Optional comment"
+//	@Success		200		{object}	SuccessResponse				"change request"
+//	@Failure		403		{object}	FailureResponse				"Permission denied or approver is the requester"
+//	@Failure		409		{object}	FailureResponse				"Change request is not pending, has expired or was already approved by the caller"
+//	@Failure		500		{object}	FailureResponse				"Interval error"
+//	@Router			/change-requests/{id}/approve [post]
+func (rc *ChangeRequestHandlers) Approve(c echo.Context) error {
+	id := c.Param("id")
+	var input model.ChangeRequestDecision
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	change, err := rc.engine.Approve(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    change,
+		Message: "Change request approved successfully.",
+	})
+}
+
+// Reject godoc
+//
+//	@Summary		Reject rejects a change request
+//	@Description	This endpoint closes a pending change request without applying it.
+//	@Tags			change-requests
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string						true	"Change request ID"
+//	@Param			body	body		model.ChangeRequestDecision	false	"Optional comment"
+//	@Success		200		{object}	SuccessResponse				"change request"
+//	@Failure		403		{object}	FailureResponse				"Permission denied"
+//	@Failure		409		{object}	FailureResponse				"Change request is not pending or has expired"
+//	@Failure		500		{object}	FailureResponse				"Interval error"
+//	@Router			/change-requests/{id}/reject [post]
+func (rc *ChangeRequestHandlers) Reject(c echo.Context) error {
+	id := c.Param("id")
+	var input model.ChangeRequestDecision
+
+	if err := c.Bind(&input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	change, err := rc.engine.Reject(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    change,
+		Message: "Change request rejected successfully.",
+	})
+}
@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/fleimkeipa/maker-checker/model"
 	"github.com/labstack/echo/v4"
@@ -10,6 +12,7 @@ import (
 type FailureResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
+	Reason  string `json:"reason,omitempty"`
 }
 
 type SuccessResponse struct {
@@ -58,3 +61,25 @@ func getFilter(c echo.Context, query string) model.Filter {
 		Value:    param,
 	}
 }
+
+// setETag sets the ETag header to the version of the returned resource
+func setETag(c echo.Context, version int) {
+	c.Response().Header().Set("ETag", fmt.Sprintf("%q", strconv.Itoa(version)))
+}
+
+// getIfMatch returns the version sent in the If-Match header, or 0 if the header is missing or "*"
+func getIfMatch(c echo.Context) (int, error) {
+	header := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	header = strings.TrimPrefix(header, "W/")
+
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil {
+		return 0, fmt.Errorf("invalid If-Match header %s", header)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/uc"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DelegationHandlers struct {
+	delegationUC *uc.DelegationUC
+}
+
+func NewDelegationHandlers(uc *uc.DelegationUC) *DelegationHandlers {
+	return &DelegationHandlers{
+		delegationUC: uc,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Create delegates the caller's decisions to a substitute
+//	@Description	This endpoint lets the delegate decide on messages on behalf of the caller between starts_at and ends_at, optionally only for the given message types. The delegate votes through PATCH /messages/{id} with on_behalf_of set to the caller.
+//	@Tags			delegations
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		model.DelegationCreateRequest	true	"Delegation window"
+//	@Success		201		{object}	SuccessResponse					"delegation"
+//	@Failure		400		{object}	FailureResponse					"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse					"Permission denied"
+//	@Failure		404		{object}	FailureResponse					"Delegate not found"
+//	@Failure		422		{object}	FailureResponse					"Delegate is the caller or not a checker"
+//	@Failure		500		{object}	FailureResponse					"Interval error"
+//	@Router			/delegations [post]
+func (rc *DelegationHandlers) Create(c echo.Context) error {
+	input := new(model.DelegationCreateRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	delegation, err := rc.delegationUC.Create(c.Request().Context(), input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, SuccessResponse{
+		Data:    delegation,
+		Message: "Delegation created successfully.",
+	})
+}
+
+// List godoc
+//
+//	@Summary		List lists the caller's delegations
+//	@Description	This endpoint lists the delegations the caller gave or received, newest first.
+//	@Tags			delegations
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse	"delegations"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/delegations [get]
+func (rc *DelegationHandlers) List(c echo.Context) error {
+	delegations, err := rc.delegationUC.List(c.Request().Context())
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    delegations,
+		Message: "Delegations retrieved successfully.",
+	})
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoke ends a delegation
+//	@Description	This endpoint ends one of the caller's delegations right away.
+//	@Tags			delegations
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string			true	"Delegation id"
+//	@Success		200	{object}	SuccessResponse	"delegation"
+//	@Failure		403	{object}	FailureResponse	"Permission denied or caller is not the delegator"
+//	@Failure		404	{object}	FailureResponse	"Delegation not found"
+//	@Failure		409	{object}	FailureResponse	"Delegation is already revoked"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/delegations/{id} [delete]
+func (rc *DelegationHandlers) Revoke(c echo.Context) error {
+	id := c.Param("id")
+
+	delegation, err := rc.delegationUC.Revoke(c.Request().Context(), id)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    delegation,
+		Message: "Delegation revoked successfully.",
+	})
+}
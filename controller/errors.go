@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/fleimkeipa/maker-checker/model"
 	"github.com/fleimkeipa/maker-checker/pkg"
 
 	"github.com/labstack/echo/v4"
@@ -12,11 +13,19 @@ import (
 // HandleEchoError handles errors that occur within the Echo framework.
 func HandleEchoError(c echo.Context, err error) error {
 	var pe *pkg.Error
+	var ce *model.VersionConflictError
 
-	if errors.As(err, &pe) {
+	if errors.As(err, &ce) {
+		return c.JSON(http.StatusConflict, FailureResponse{
+			Error:   ce.Error(),
+			Message: "The resource was changed by another request. Please reload it and try again.",
+			Reason:  model.ConflictReasonVersion,
+		})
+	} else if errors.As(err, &pe) {
 		return c.JSON(pe.StatusCode(), FailureResponse{
 			Error:   pe.Error(),
 			Message: pe.Message(),
+			Reason:  pe.Reason(),
 		})
 	} else {
 		return c.JSON(http.StatusInternalServerError, FailureResponse{
@@ -23,7 +23,7 @@ func NewMessageHandlers(uc *uc.MsgUC) *MessageHandlers {
 // Create godoc
 //
 //	@Summary		Create creates a new message
-//	@Description	This endpoint creates a new message by providing sender id, receiver id, text, and status.
+//	@Description	This endpoint creates a new message by providing sender id, receiver id, text, and status. With draft set the message is saved as a draft that only the sender sees, until it is submitted through POST /messages/{id}/submit.
 //	@Tags			messages
 //	@Accept			json
 //	@Produce		json
@@ -31,6 +31,7 @@ func NewMessageHandlers(uc *uc.MsgUC) *MessageHandlers {
 //	@Param			body	body		model.MessageCreateRequest	true	"Message creation input"
 //	@Success		201		{object}	SuccessResponse				"message id"
 //	@Failure		400		{object}	FailureResponse				"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse				"Permission denied"
 //	@Failure		500		{object}	FailureResponse				"Interval error"
 //	@Router			/messages [post]
 func (rc *MessageHandlers) Create(c echo.Context) error {
@@ -54,19 +55,59 @@ func (rc *MessageHandlers) Create(c echo.Context) error {
 	})
 }
 
-// Update godoc
+// Simulate godoc
 //
-//	@Summary		Update updates an existing message
-//	@Description	This endpoint updates a message by providing message id, sender id, receiver id, text, and status.
+//	@Summary		Simulate routes a message without storing it
+//	@Description	This endpoint runs the routing rules, the auto-approval rules and the approval chain on the message as if it was submitted now. It returns the resulting status, approval steps with the checkers eligible for each step, SLA and every rule that matched, the first one decides. Nothing is stored, assigned or audited.
 //	@Tags			messages
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
-//	@Param			id		path		string						true	"Message id"
-//	@Param			body	body		model.MessageUpdateRequest	true	"Message update input, status= pending:1, approved:2, rejected:3"
-//	@Success		200		{object}	SuccessResponse				"message id"
+//	@Param			body	body		model.MessageCreateRequest	true	"Message creation input"
+//	@Success		200		{object}	SuccessResponse				"message simulation"
 //	@Failure		400		{object}	FailureResponse				"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse				"Permission denied"
 //	@Failure		500		{object}	FailureResponse				"Interval error"
+//	@Router			/messages/simulate [post]
+func (rc *MessageHandlers) Simulate(c echo.Context) error {
+	req := new(model.MessageCreateRequest)
+
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	simulation, err := rc.msgUC.Simulate(c.Request().Context(), req)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    simulation,
+		Message: "Message simulated successfully.",
+	})
+}
+
+// Update godoc
+//
+//	@Summary		Update updates an existing message
+//	@Description	This endpoint records the checker's vote on the current approval step of a message. The step is decided once its quorum is reached or can no longer be reached. The message is accepted after the last step, or rejected by any step.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string						true	"Message id"
+//	@Param			If-Match	header		string						false	"ETag of the message version the checker decided on"
+//	@Param			body		body		model.MessageUpdateRequest	true	"Message update input, status= accepted:2, rejected:3, changes requested:8, reason_code is required when rejecting, comment when requesting changes"
+//	@Success		200			{object}	SuccessResponse				"message id"
+//	@Header			200			{string}	ETag						"Version of the updated message"
+//	@Failure		400			{object}	FailureResponse				"Error message including details on failure"
+//	@Failure		403			{object}	FailureResponse				"Permission denied, or the checker is the sender, the receiver or related to the sender (reason checker_is_sender, checker_is_receiver or checker_is_related)"
+//	@Failure		409			{object}	FailureResponse				"Transition not allowed or the message was changed meanwhile"
+//	@Failure		422			{object}	FailureResponse				"Unknown status"
+//	@Failure		500			{object}	FailureResponse				"Interval error"
 //	@Router			/messages/{id} [patch]
 func (rc *MessageHandlers) Update(c echo.Context) error {
 	id := c.Param("id")
@@ -79,17 +120,317 @@ func (rc *MessageHandlers) Update(c echo.Context) error {
 		})
 	}
 
+	// If-Match takes precedence over the version in the body
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   err.Error(),
+			Message: "Invalid If-Match header. Please send the ETag of the message.",
+		})
+	}
+	if version != 0 {
+		input.Version = version
+	}
+
 	message, err := rc.msgUC.Update(c.Request().Context(), id, input)
 	if err != nil {
 		return HandleEchoError(c, err)
 	}
 
+	setETag(c, message.Version)
+
 	return c.JSON(http.StatusOK, SuccessResponse{
 		Data:    message.ID,
 		Message: "Message updated successfully.",
 	})
 }
 
+// Resubmit godoc
+//
+//	@Summary		Resubmit resubmits a message after changes were requested
+//	@Description	This endpoint lets the sender edit the text of a message that a checker sent back, creating a new revision that is reviewed again.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string							true	"Message id"
+//	@Param			If-Match	header		string							false	"ETag of the message version the sender resubmits"
+//	@Param			body		body		model.MessageResubmitRequest	true	"Message resubmit input"
+//	@Success		200			{object}	SuccessResponse					"message revision"
+//	@Header			200			{string}	ETag							"Version of the resubmitted message"
+//	@Failure		400			{object}	FailureResponse					"Error message including details on failure"
+//	@Failure		403			{object}	FailureResponse					"Caller is not the sender"
+//	@Failure		409			{object}	FailureResponse					"Transition not allowed, or the message was changed meanwhile"
+//	@Failure		500			{object}	FailureResponse					"Interval error"
+//	@Router			/messages/{id}/resubmit [post]
+func (rc *MessageHandlers) Resubmit(c echo.Context) error {
+	id := c.Param("id")
+	input := new(model.MessageResubmitRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	// If-Match takes precedence over the version in the body
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   err.Error(),
+			Message: "Invalid If-Match header. Please send the ETag of the message.",
+		})
+	}
+	if version != 0 {
+		input.Version = version
+	}
+
+	message, err := rc.msgUC.Resubmit(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, message.Version)
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    message.Revision,
+		Message: "Message resubmitted successfully.",
+	})
+}
+
+// Edit godoc
+//
+//	@Summary		Edit replaces the content of a draft
+//	@Description	This endpoint lets the sender replace the receiver, text, type and delivery time of a draft. The receiver and text may stay empty until the draft is submitted.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string						true	"Message id"
+//	@Param			If-Match	header		string						false	"ETag of the draft version the sender edited"
+//	@Param			body		body		model.MessageEditRequest	true	"Draft edit input"
+//	@Success		200			{object}	SuccessResponse				"edited draft"
+//	@Header			200			{string}	ETag						"Version of the edited draft"
+//	@Failure		400			{object}	FailureResponse				"Error message including details on failure"
+//	@Failure		403			{object}	FailureResponse				"Caller is not the sender"
+//	@Failure		404			{object}	FailureResponse				"Message not found"
+//	@Failure		409			{object}	FailureResponse				"Message is not a draft or was changed meanwhile"
+//	@Failure		500			{object}	FailureResponse				"Interval error"
+//	@Router			/messages/{id} [put]
+func (rc *MessageHandlers) Edit(c echo.Context) error {
+	id := c.Param("id")
+	input := new(model.MessageEditRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	// If-Match takes precedence over the version in the body
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   err.Error(),
+			Message: "Invalid If-Match header. Please send the ETag of the message.",
+		})
+	}
+	if version != 0 {
+		input.Version = version
+	}
+
+	message, err := rc.msgUC.Edit(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, message.Version)
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    message,
+		Message: "Draft edited successfully.",
+	})
+}
+
+// Submit godoc
+//
+//	@Summary		Submit submits a draft for review
+//	@Description	This endpoint validates a draft and moves it to pending: it needs a receiver and a text, a message type with an approval chain and a delivery time in the future, if any. The message then shows up in the checkers' review queues.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string						true	"Message id"
+//	@Param			If-Match	header		string						false	"ETag of the draft version the sender submits"
+//	@Param			body		body		model.MessageSubmitRequest	false	"Draft submit input"
+//	@Success		200			{object}	SuccessResponse				"submitted message"
+//	@Header			200			{string}	ETag						"Version of the submitted message"
+//	@Failure		400			{object}	FailureResponse				"Error message including details on failure"
+//	@Failure		403			{object}	FailureResponse				"Caller is not the sender"
+//	@Failure		404			{object}	FailureResponse				"Message not found"
+//	@Failure		409			{object}	FailureResponse				"Message is not a draft or was changed meanwhile"
+//	@Failure		500			{object}	FailureResponse				"Interval error"
+//	@Router			/messages/{id}/submit [post]
+func (rc *MessageHandlers) Submit(c echo.Context) error {
+	id := c.Param("id")
+	input := new(model.MessageSubmitRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	// If-Match takes precedence over the version in the body
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   err.Error(),
+			Message: "Invalid If-Match header. Please send the ETag of the message.",
+		})
+	}
+	if version != 0 {
+		input.Version = version
+	}
+
+	message, err := rc.msgUC.Submit(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, message.Version)
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    message,
+		Message: "Draft submitted successfully.",
+	})
+}
+
+// Withdraw godoc
+//
+//	@Summary		Withdraw withdraws a message
+//	@Description	This endpoint lets the sender retract a message that is still pending, or discard a draft, or any other status its workflow allows withdrawing from. The message is removed from the review queues and stays in the sender's history as withdrawn.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string							true	"Message id"
+//	@Param			If-Match	header		string							false	"ETag of the message version the sender withdraws"
+//	@Param			body		body		model.MessageWithdrawRequest	false	"Message withdraw input"
+//	@Success		200			{object}	SuccessResponse					"withdrawn message"
+//	@Header			200			{string}	ETag							"Version of the withdrawn message"
+//	@Failure		400			{object}	FailureResponse					"Error message including details on failure"
+//	@Failure		403			{object}	FailureResponse					"Caller is not the sender"
+//	@Failure		404			{object}	FailureResponse					"Message not found"
+//	@Failure		409			{object}	FailureResponse					"Message is neither pending nor a draft, or was changed meanwhile"
+//	@Failure		500			{object}	FailureResponse					"Interval error"
+//	@Router			/messages/{id}/withdraw [post]
+func (rc *MessageHandlers) Withdraw(c echo.Context) error {
+	id := c.Param("id")
+	input := new(model.MessageWithdrawRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	// If-Match takes precedence over the version in the body
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   err.Error(),
+			Message: "Invalid If-Match header. Please send the ETag of the message.",
+		})
+	}
+	if version != 0 {
+		input.Version = version
+	}
+
+	message, err := rc.msgUC.Withdraw(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, message.Version)
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    message,
+		Message: "Message withdrawn successfully.",
+	})
+}
+
+// Assign godoc
+//
+//	@Summary		Assign reassigns a message to another checker
+//	@Description	This endpoint assigns the current approval step of a pending message to the given checker, who must be allowed to decide on it.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string						true	"Message id"
+//	@Param			body	body		model.MessageAssignRequest	true	"Checker to assign the message to"
+//	@Success		200		{object}	SuccessResponse				"message"
+//	@Failure		400		{object}	FailureResponse				"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse				"Permission denied, or the checker may not decide on the message, is its receiver or is related to the sender"
+//	@Failure		404		{object}	FailureResponse				"Message or checker not found"
+//	@Failure		409		{object}	FailureResponse				"Message is not pending"
+//	@Failure		422		{object}	FailureResponse				"User is not a checker"
+//	@Failure		500		{object}	FailureResponse				"Interval error"
+//	@Router			/messages/{id}/assignee [patch]
+func (rc *MessageHandlers) Assign(c echo.Context) error {
+	id := c.Param("id")
+	input := new(model.MessageAssignRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	message, err := rc.msgUC.Assign(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    message,
+		Message: "Message assigned successfully.",
+	})
+}
+
+// Revisions godoc
+//
+//	@Summary		Revisions lists the revisions of a message
+//	@Description	This endpoint lists every revision of a message text, oldest first, with a line diff against the previous revision.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string			true	"Message id"
+//	@Success		200	{object}	SuccessResponse	"revisions"
+//	@Failure		404	{object}	FailureResponse	"Message not found"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/messages/{id}/revisions [get]
+func (rc *MessageHandlers) Revisions(c echo.Context) error {
+	id := c.Param("id")
+
+	revisions, err := rc.msgUC.Revisions(c.Request().Context(), id)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    revisions,
+		Message: "Revisions retrieved successfully.",
+	})
+}
+
 // List godoc
 //
 //	@Summary		List lists messages
@@ -124,13 +465,14 @@ func (rc *MessageHandlers) List(c echo.Context) error {
 // GetByID godoc
 //
 //	@Summary		GetByID gets a message by id
-//	@Description	This endpoint gets a message by providing message id.
+//	@Description	This endpoint gets a message by providing message id, including its approval steps and the active step.
 //	@Tags			messages
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
 //	@Param			id	path		string			true	"Message id"
 //	@Success		200	{object}	SuccessResponse	"message"
+//	@Header			200	{string}	ETag			"Version of the message, send it as If-Match when deciding"
 //	@Failure		400	{object}	FailureResponse	"Error message including details on failure"
 //	@Failure		500	{object}	FailureResponse	"Interval error"
 //	@Router			/messages/{id} [get]
@@ -142,12 +484,31 @@ func (rc *MessageHandlers) GetByID(c echo.Context) error {
 		return HandleEchoError(c, err)
 	}
 
+	setETag(c, message.Version)
+
 	return c.JSON(http.StatusOK, SuccessResponse{
 		Data:    message,
 		Message: "Message retrieved successfully.",
 	})
 }
 
+// RejectionReasons godoc
+//
+//	@Summary		RejectionReasons lists the rejection reason catalog
+//	@Description	This endpoint lists the reason codes a checker can use to reject a message.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse	"rejection reasons"
+//	@Router			/messages/rejection-reasons [get]
+func (rc *MessageHandlers) RejectionReasons(c echo.Context) error {
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    rc.msgUC.RejectionReasons(),
+		Message: "Rejection reasons retrieved successfully.",
+	})
+}
+
 func getMessageFindOpts(c echo.Context) model.MessageFindOpts {
 	return model.MessageFindOpts{
 		PaginationOpts: getPagination(c),
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/uc"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RecallHandlers struct {
+	recallUC *uc.RecallUC
+}
+
+func NewRecallHandlers(uc *uc.RecallUC) *RecallHandlers {
+	return &RecallHandlers{
+		recallUC: uc,
+	}
+}
+
+// Request godoc
+//
+//	@Summary		Request asks to recall an accepted message
+//	@Description	This endpoint lets the sender take back an accepted message, or a message in any other status its workflow allows recalling from. The recall is a change request of the message resource type, the message is only recalled once checkers approved it through /change-requests. The receiver then only sees that the message was recalled, the content stays available to the sender, checkers and auditors.
+//	@Tags			messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string						true	"Message id"
+//	@Param			body	body		model.MessageRecallRequest	true	"Message recall input"
+//	@Success		202		{object}	SuccessResponse				"recall change request"
+//	@Failure		400		{object}	FailureResponse				"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse				"Caller is not the sender"
+//	@Failure		404		{object}	FailureResponse				"Message not found"
+//	@Failure		409		{object}	FailureResponse				"Workflow does not allow recalling the message or a recall is already pending"
+//	@Failure		500		{object}	FailureResponse				"Interval error"
+//	@Router			/messages/{id}/recall [post]
+func (rc *RecallHandlers) Request(c echo.Context) error {
+	id := c.Param("id")
+	input := new(model.MessageRecallRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	change, err := rc.recallUC.Request(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, SuccessResponse{
+		Data:    change,
+		Message: "Message recall requested successfully, it takes effect once it is approved.",
+	})
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/uc"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewHandlers struct {
+	reviewUC *uc.ReviewUC
+}
+
+func NewReviewHandlers(uc *uc.ReviewUC) *ReviewHandlers {
+	return &ReviewHandlers{
+		reviewUC: uc,
+	}
+}
+
+// Queue godoc
+//
+//	@Summary		Queue lists the messages waiting for the caller
+//	@Description	This endpoint lists the pending messages the caller may decide on, oldest first. Messages claimed by another checker are left out until the claim expires.
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			limit	query		int				false	"Messages limit"
+//	@Param			skip	query		int				false	"Skip messages"
+//	@Success		200		{object}	SuccessResponse	"pending messages"
+//	@Failure		403		{object}	FailureResponse	"Permission denied"
+//	@Failure		500		{object}	FailureResponse	"Interval error"
+//	@Router			/reviews/queue [get]
+func (rc *ReviewHandlers) Queue(c echo.Context) error {
+	messages, err := rc.reviewUC.Queue(c.Request().Context(), getPagination(c))
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    messages,
+		Message: "Review queue retrieved successfully.",
+	})
+}
+
+// Claim godoc
+//
+//	@Summary		Claim claims a message for review
+//	@Description	This endpoint takes a time-limited lease on a pending message, so that no other checker decides on it meanwhile. Claiming a message the caller already holds renews the lease.
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string			true	"Message id"
+//	@Success		200	{object}	SuccessResponse	"claimed message"
+//	@Failure		403	{object}	FailureResponse	"Permission denied or caller may not decide on the message"
+//	@Failure		409	{object}	FailureResponse	"Message is not pending or is claimed by another checker"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/reviews/{id}/claim [post]
+func (rc *ReviewHandlers) Claim(c echo.Context) error {
+	id := c.Param("id")
+
+	message, err := rc.reviewUC.Claim(c.Request().Context(), id)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, message.Version)
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    message,
+		Message: "Message claimed successfully.",
+	})
+}
+
+// Release godoc
+//
+//	@Summary		Release releases a claimed message
+//	@Description	This endpoint gives up the caller's lease on a message, so that other checkers can review it.
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string			true	"Message id"
+//	@Success		200	{object}	SuccessResponse	"message id"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
+//	@Failure		409	{object}	FailureResponse	"Caller holds no claim on the message"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/reviews/{id}/claim [delete]
+func (rc *ReviewHandlers) Release(c echo.Context) error {
+	id := c.Param("id")
+
+	if err := rc.reviewUC.Release(c.Request().Context(), id); err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    id,
+		Message: "Message released successfully.",
+	})
+}
+
+// Bulk godoc
+//
+//	@Summary		Bulk decides on many messages at once
+//	@Description	This endpoint takes the same decision on up to 500 messages. Every message is checked on its own against the same rules as PATCH /messages/{id}: it must be pending, the caller must be allowed to decide on its current step under the segregation of duties rules, and an optional version must still match. The response lists the result of every message in the order of the request, a failed message doesn't stop the others.
+//	@Tags			reviews
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		model.BulkReviewRequest	true	"Bulk decision input"
+//	@Success		200		{object}	SuccessResponse			"result per message"
+//	@Failure		400		{object}	FailureResponse			"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse			"Permission denied"
+//	@Failure		422		{object}	FailureResponse			"Unknown reason code"
+//	@Failure		500		{object}	FailureResponse			"Interval error"
+//	@Router			/reviews/bulk [post]
+func (rc *ReviewHandlers) Bulk(c echo.Context) error {
+	input := new(model.BulkReviewRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	results, err := rc.reviewUC.Bulk(c.Request().Context(), input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    results,
+		Message: "Bulk decision processed, see the result of each message.",
+	})
+}
@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/uc"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RoutingRuleHandlers struct {
+	routingRuleUC *uc.RoutingRuleUC
+}
+
+func NewRoutingRuleHandlers(uc *uc.RoutingRuleUC) *RoutingRuleHandlers {
+	return &RoutingRuleHandlers{
+		routingRuleUC: uc,
+	}
+}
+
+// Create godoc
+//
+//	@Summary		Create creates a routing rule
+//	@Description	This endpoint compiles the expression and stores the rule as its first version. The rule applies to the next message that goes up for review. Expressions can refer to text, type, sender.id, sender.username, sender.role, sender.groups, sender.team, receiver.id, receiver.username, receiver.role, receiver.groups and receiver.team.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		model.RoutingRuleCreateRequest	true	"Routing rule input"
+//	@Success		201		{object}	SuccessResponse					"routing rule"
+//	@Failure		400		{object}	FailureResponse					"Invalid expression, action or group"
+//	@Failure		403		{object}	FailureResponse					"Permission denied"
+//	@Failure		500		{object}	FailureResponse					"Interval error"
+//	@Router			/routing-rules [post]
+func (rc *RoutingRuleHandlers) Create(c echo.Context) error {
+	input := new(model.RoutingRuleCreateRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	rule, err := rc.routingRuleUC.Create(c.Request().Context(), input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, rule.Version)
+
+	return c.JSON(http.StatusCreated, SuccessResponse{
+		Data:    rule,
+		Message: "Routing rule created successfully.",
+	})
+}
+
+// Simulate godoc
+//
+//	@Summary		Simulate evaluates a draft routing rule against recent messages
+//	@Description	This endpoint evaluates the draft rule, as if it was enabled, against the last submitted messages, newest first. For each message it returns the decision of the current rules, the decision with the draft rule, and whether the draft rule matched. Nothing is stored.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			body	body		model.RoutingRuleSimulateRequest	true	"Draft routing rule and the number of messages, 100 by default"
+//	@Success		200		{object}	SuccessResponse						"routing rule simulation"
+//	@Failure		400		{object}	FailureResponse						"Invalid expression, action, group or limit"
+//	@Failure		403		{object}	FailureResponse						"Permission denied"
+//	@Failure		500		{object}	FailureResponse						"Interval error"
+//	@Router			/routing-rules/simulate [post]
+func (rc *RoutingRuleHandlers) Simulate(c echo.Context) error {
+	input := new(model.RoutingRuleSimulateRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	simulation, err := rc.routingRuleUC.Simulate(c.Request().Context(), input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    simulation,
+		Message: "Routing rule simulated successfully.",
+	})
+}
+
+// List godoc
+//
+//	@Summary		List lists the routing rules
+//	@Description	This endpoint lists every routing rule, enabled or not, in the order they are evaluated in.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	SuccessResponse	"routing rules"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/routing-rules [get]
+func (rc *RoutingRuleHandlers) List(c echo.Context) error {
+	rules, err := rc.routingRuleUC.List(c.Request().Context())
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    rules,
+		Message: "Routing rules retrieved successfully.",
+	})
+}
+
+// GetByID godoc
+//
+//	@Summary		GetByID returns a routing rule
+//	@Description	This endpoint returns the current version of a routing rule, with the version as ETag header.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string			true	"Routing rule id"
+//	@Success		200	{object}	SuccessResponse	"routing rule"
+//	@Header			200	{string}	ETag			"Version of the routing rule"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
+//	@Failure		404	{object}	FailureResponse	"Routing rule not found"
+//	@Router			/routing-rules/{id} [get]
+func (rc *RoutingRuleHandlers) GetByID(c echo.Context) error {
+	rule, err := rc.routingRuleUC.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, rule.Version)
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    rule,
+		Message: "Routing rule retrieved successfully.",
+	})
+}
+
+// Update godoc
+//
+//	@Summary		Update stores a new version of a routing rule
+//	@Description	This endpoint compiles the expression and replaces the name, expression, action, group and priority of the rule with a new version. The previous versions stay listed under /routing-rules/{id}/versions. Expressions can refer to text, type, sender.id, sender.username, sender.role, sender.groups, sender.team, receiver.id, receiver.username, receiver.role, receiver.groups and receiver.team.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string							true	"Routing rule id"
+//	@Param			If-Match	header		string							false	"ETag of the rule version the admin edited"
+//	@Param			body		body		model.RoutingRuleUpdateRequest	true	"Routing rule input"
+//	@Success		200			{object}	SuccessResponse					"routing rule"
+//	@Header			200			{string}	ETag							"Version of the routing rule"
+//	@Failure		400			{object}	FailureResponse					"Invalid expression, action or group"
+//	@Failure		403			{object}	FailureResponse					"Permission denied"
+//	@Failure		404			{object}	FailureResponse					"Routing rule not found"
+//	@Failure		409			{object}	FailureResponse					"Routing rule was changed meanwhile"
+//	@Failure		500			{object}	FailureResponse					"Interval error"
+//	@Router			/routing-rules/{id} [put]
+func (rc *RoutingRuleHandlers) Update(c echo.Context) error {
+	id := c.Param("id")
+	input := new(model.RoutingRuleUpdateRequest)
+
+	if err := c.Bind(input); err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   fmt.Sprintf("Failed to bind request: %v", err),
+			Message: "Invalid request data. Please check your input and try again.",
+		})
+	}
+
+	// If-Match takes precedence over the version in the body
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   err.Error(),
+			Message: "Invalid If-Match header. Please send the ETag of the routing rule.",
+		})
+	}
+	if version != 0 {
+		input.Version = version
+	}
+
+	rule, err := rc.routingRuleUC.Update(c.Request().Context(), id, input)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, rule.Version)
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    rule,
+		Message: "Routing rule updated successfully.",
+	})
+}
+
+// Disable godoc
+//
+//	@Summary		Disable disables a routing rule
+//	@Description	This endpoint stores a new version of the rule that is skipped when messages are routed.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string			true	"Routing rule id"
+//	@Param			If-Match	header		string			false	"ETag of the rule version the admin disables"
+//	@Success		200			{object}	SuccessResponse	"routing rule"
+//	@Header			200			{string}	ETag			"Version of the routing rule"
+//	@Failure		403			{object}	FailureResponse	"Permission denied"
+//	@Failure		404			{object}	FailureResponse	"Routing rule not found"
+//	@Failure		409			{object}	FailureResponse	"Routing rule is already disabled or was changed meanwhile"
+//	@Failure		500			{object}	FailureResponse	"Interval error"
+//	@Router			/routing-rules/{id}/disable [post]
+func (rc *RoutingRuleHandlers) Disable(c echo.Context) error {
+	return rc.setDisabled(c, true)
+}
+
+// Enable godoc
+//
+//	@Summary		Enable enables a disabled routing rule
+//	@Description	This endpoint stores a new version of the rule that is evaluated again when messages are routed.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		string			true	"Routing rule id"
+//	@Param			If-Match	header		string			false	"ETag of the rule version the admin enables"
+//	@Success		200			{object}	SuccessResponse	"routing rule"
+//	@Header			200			{string}	ETag			"Version of the routing rule"
+//	@Failure		403			{object}	FailureResponse	"Permission denied"
+//	@Failure		404			{object}	FailureResponse	"Routing rule not found"
+//	@Failure		409			{object}	FailureResponse	"Routing rule is already enabled or was changed meanwhile"
+//	@Failure		500			{object}	FailureResponse	"Interval error"
+//	@Router			/routing-rules/{id}/enable [post]
+func (rc *RoutingRuleHandlers) Enable(c echo.Context) error {
+	return rc.setDisabled(c, false)
+}
+
+// Versions godoc
+//
+//	@Summary		Versions lists the versions of a routing rule
+//	@Description	This endpoint lists every version of the rule, oldest first. Messages record the rule id and version that routed them.
+//	@Tags			routing-rules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string			true	"Routing rule id"
+//	@Success		200	{object}	SuccessResponse	"routing rule versions"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
+//	@Failure		404	{object}	FailureResponse	"Routing rule not found"
+//	@Failure		500	{object}	FailureResponse	"Interval error"
+//	@Router			/routing-rules/{id}/versions [get]
+func (rc *RoutingRuleHandlers) Versions(c echo.Context) error {
+	versions, err := rc.routingRuleUC.Versions(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    versions,
+		Message: "Routing rule versions retrieved successfully.",
+	})
+}
+
+func (rc *RoutingRuleHandlers) setDisabled(c echo.Context, disabled bool) error {
+	version, err := getIfMatch(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, FailureResponse{
+			Error:   err.Error(),
+			Message: "Invalid If-Match header. Please send the ETag of the routing rule.",
+		})
+	}
+
+	rule, err := rc.routingRuleUC.SetDisabled(c.Request().Context(), c.Param("id"), disabled, version)
+	if err != nil {
+		return HandleEchoError(c, err)
+	}
+
+	setETag(c, rule.Version)
+
+	message := "Routing rule enabled successfully."
+	if disabled {
+		message = "Routing rule disabled successfully."
+	}
+
+	return c.JSON(http.StatusOK, SuccessResponse{
+		Data:    rule,
+		Message: message,
+	})
+}
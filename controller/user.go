@@ -22,15 +22,16 @@ func NewUserHandlers(uc *uc.UserUC) *UserHandlers {
 
 // Create godoc
 //
-//	@Summary		Create creates a new user
-//	@Description	This endpoint creates a new user by providing username, email, password, and role ID.
+//	@Summary		Create requests a new user
+//	@Description	This endpoint creates a change request for a new user by providing username, email, password, and role. The user is created once another admin approves it.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
 //	@Param			body	body		model.UserCreateRequest	true	"User creation input"
-//	@Success		201		{object}	SuccessResponse			"user username"
+//	@Success		202		{object}	SuccessResponse			"change request"
 //	@Failure		400		{object}	FailureResponse			"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse			"Permission denied"
 //	@Failure		500		{object}	FailureResponse			"Interval error"
 //	@Router			/users [post]
 func (rc *UserHandlers) Create(c echo.Context) error {
@@ -43,33 +44,35 @@ func (rc *UserHandlers) Create(c echo.Context) error {
 		})
 	}
 
-	user, err := rc.userUC.Create(c.Request().Context(), input)
+	change, err := rc.userUC.RequestCreate(c.Request().Context(), input)
 	if err != nil {
 		return HandleEchoError(c, err)
 	}
 
-	return c.JSON(http.StatusCreated, SuccessResponse{
-		Data:    user.Username,
-		Message: "User created successfully.",
+	return c.JSON(http.StatusAccepted, SuccessResponse{
+		Data:    change,
+		Message: "User creation is waiting for approval.",
 	})
 }
 
 // UpdateUser godoc
 //
-//	@Summary		UpdateUser updates an existing user
-//	@Description	This endpoint updates a user by providing username, email, password, and role ID.
+//	@Summary		UpdateUser requests an update of an existing user
+//	@Description	This endpoint creates a change request for the fields of a user present in the body: username, email, password, role, related user ids and groups. The fields that are omitted keep their value. The user is updated once another admin approves it. An empty password keeps the current one.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
-//	@Param			body	body		model.UserCreateRequest	true	"User update input"
-//	@Success		200		{object}	SuccessResponse			"user username"
+//	@Param			id		path		string					true	"User ID"
+//	@Param			body	body		model.UserUpdateRequest	true	"User update input, omitted fields keep their value"
+//	@Success		202		{object}	SuccessResponse			"change request"
 //	@Failure		400		{object}	FailureResponse			"Error message including details on failure"
+//	@Failure		403		{object}	FailureResponse			"Permission denied"
 //	@Failure		500		{object}	FailureResponse			"Interval error"
 //	@Router			/users/{id} [patch]
 func (rc *UserHandlers) UpdateUser(c echo.Context) error {
 	id := c.Param("id")
-	var input model.UserCreateRequest
+	var input model.UserUpdateRequest
 
 	if err := c.Bind(&input); err != nil {
 		return c.JSON(http.StatusBadRequest, FailureResponse{
@@ -78,14 +81,14 @@ func (rc *UserHandlers) UpdateUser(c echo.Context) error {
 		})
 	}
 
-	user, err := rc.userUC.Update(c.Request().Context(), id, input)
+	change, err := rc.userUC.RequestUpdate(c.Request().Context(), id, input)
 	if err != nil {
 		return HandleEchoError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, SuccessResponse{
-		Data:    user.Username,
-		Message: "User updated successfully.",
+	return c.JSON(http.StatusAccepted, SuccessResponse{
+		Data:    change,
+		Message: "User update is waiting for approval.",
 	})
 }
 
@@ -99,6 +102,7 @@ func (rc *UserHandlers) UpdateUser(c echo.Context) error {
 //	@Security		ApiKeyAuth
 //	@Param			id	path		string			true	"User ID"
 //	@Success		200	{object}	SuccessResponse	"Successful response containing the user information"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
 //	@Failure		500	{object}	FailureResponse	"Internal server error"
 //	@Router			/users/{id} [get]
 func (rc *UserHandlers) GetByID(c echo.Context) error {
@@ -120,23 +124,27 @@ func (rc *UserHandlers) GetByID(c echo.Context) error {
 
 // DeleteUser godoc
 //
-//	@Summary		DeleteUser deletes an existing user
-//	@Description	This endpoint deletes a user by providing user id.
+//	@Summary		DeleteUser requests the deletion of an existing user
+//	@Description	This endpoint creates a change request to delete a user by providing user id. The user is deleted once another admin approves it.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Security		ApiKeyAuth
-//	@Success		200	{object}	SuccessResponse	"user username"
+//	@Param			id	path		string			true	"User ID"
+//	@Success		202	{object}	SuccessResponse	"change request"
+//	@Failure		403	{object}	FailureResponse	"Permission denied"
 //	@Failure		500	{object}	FailureResponse	"Interval error"
 //	@Router			/users/{id} [delete]
 func (rc *UserHandlers) DeleteUser(c echo.Context) error {
 	id := c.Param("id")
 
-	if err := rc.userUC.Delete(c.Request().Context(), id); err != nil {
+	change, err := rc.userUC.RequestDelete(c.Request().Context(), id)
+	if err != nil {
 		return HandleEchoError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, SuccessResponse{
-		Message: "User deleted successfully.",
+	return c.JSON(http.StatusAccepted, SuccessResponse{
+		Data:    change,
+		Message: "User deletion is waiting for approval.",
 	})
 }
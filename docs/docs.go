@@ -15,6 +15,46 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/audit/verify": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint walks the hash-chained audit trail end to end and reports the first broken link, if any.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Verify verifies the audit trail",
+                "responses": {
+                    "200": {
+                        "description": "audit verification result",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/login": {
             "post": {
                 "description": "This endpoint allows a user to log in by providing a valid username and password.",
@@ -107,14 +147,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/messages": {
+        "/change-requests": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "This endpoint lists messages by providing limit and skip.",
+                "description": "This endpoint lists the change requests of the resource types whose approver roles include the caller's role, newest first. Pending requests past their expiry are marked as expired.",
                 "consumes": [
                     "application/json"
                 ],
@@ -122,50 +162,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "change-requests"
                 ],
-                "summary": "List lists messages",
+                "summary": "List lists change requests",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Messages limit",
+                        "description": "Change requests limit",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Skip messages",
+                        "description": "Skip change requests",
                         "name": "skip",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Receiver id",
-                        "name": "receiver_id",
+                        "description": "Resource type, e.g. user",
+                        "name": "resource_type",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sender id",
-                        "name": "sender_id",
+                        "description": "Resource ID",
+                        "name": "resource_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Status",
+                        "description": "Status: pending, approved, rejected, expired, failed",
                         "name": "status",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "messages",
+                        "description": "change requests",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Error message including details on failure",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
@@ -177,14 +217,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/change-requests/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "This endpoint creates a new message by providing sender id, receiver id, text, and status.",
+                "description": "This endpoint returns a change request with its payload and approvals. Change requests of resource types the caller's role can't approve are not found.",
                 "consumes": [
                     "application/json"
                 ],
@@ -192,35 +234,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "change-requests"
                 ],
-                "summary": "Create creates a new message",
+                "summary": "GetByID retrieves a change request",
                 "parameters": [
                     {
-                        "description": "Message creation input",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.MessageCreateRequest"
-                        }
+                        "type": "string",
+                        "description": "Change request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "message id",
+                    "200": {
+                        "description": "change request",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Error message including details on failure",
+                    "403": {
+                        "description": "Permission denied",
                         "schema": {
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
                     },
-                    "500": {
-                        "description": "Interval error",
+                    "404": {
+                        "description": "Change request not found",
                         "schema": {
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
@@ -228,14 +268,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/messages/{id}": {
-            "get": {
+        "/change-requests/{id}/approve": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "This endpoint gets a message by providing message id.",
+                "description": "This endpoint records the caller's approval. The change is applied once the resource type's required approvals are reached. The requester cannot approve their own change request.",
                 "consumes": [
                     "application/json"
                 ],
@@ -243,27 +283,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "change-requests"
                 ],
-                "summary": "GetByID gets a message by id",
+                "summary": "Approve approves a change request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Message id",
+                        "description": "Change request ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Optional comment",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.ChangeRequestDecision"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message",
+                        "description": "change request",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Error message including details on failure",
+                    "403": {
+                        "description": "Permission denied or approver is the requester",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Change request is not pending, has expired or was already approved by the caller",
                         "schema": {
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
@@ -275,14 +329,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/change-requests/{id}/reject": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "This endpoint updates a message by providing message id, sender id, receiver id, text, and status.",
+                "description": "This endpoint closes a pending change request without applying it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -290,36 +346,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "messages"
+                    "change-requests"
                 ],
-                "summary": "Update updates an existing message",
+                "summary": "Reject rejects a change request",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Message id",
+                        "description": "Change request ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Message update input, status= pending:1, approved:2, rejected:3",
+                        "description": "Optional comment",
                         "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.MessageUpdateRequest"
+                            "$ref": "#/definitions/model.ChangeRequestDecision"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message id",
+                        "description": "change request",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Error message including details on failure",
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Change request is not pending or has expired",
                         "schema": {
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
@@ -333,14 +394,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/delegations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists the delegations the caller gave or received, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "delegations"
+                ],
+                "summary": "List lists the caller's delegations",
+                "responses": {
+                    "200": {
+                        "description": "delegations",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "This endpoint creates a new user by providing username, email, password, and role ID.",
+                "description": "This endpoint lets the delegate decide on messages on behalf of the caller between starts_at and ends_at, optionally only for the given message types. The delegate votes through PATCH /messages/{id} with on_behalf_of set to the caller.",
                 "consumes": [
                     "application/json"
                 ],
@@ -348,23 +447,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "delegations"
                 ],
-                "summary": "Create creates a new user",
+                "summary": "Create delegates the caller's decisions to a substitute",
                 "parameters": [
                     {
-                        "description": "User creation input",
+                        "description": "Delegation window",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.UserCreateRequest"
+                            "$ref": "#/definitions/model.DelegationCreateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "user username",
+                        "description": "delegation",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
@@ -375,6 +474,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Delegate not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Delegate is the caller or not a checker",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
                     "500": {
                         "description": "Interval error",
                         "schema": {
@@ -384,14 +501,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
+        "/delegations/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches a user by their unique ID from the database.",
+                "description": "This endpoint ends one of the caller's delegations right away.",
                 "consumes": [
                     "application/json"
                 ],
@@ -399,13 +516,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "delegations"
                 ],
-                "summary": "Retrieve user by ID",
+                "summary": "Revoke ends a delegation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Delegation id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -413,26 +530,46 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response containing the user information",
+                        "description": "delegation",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied or caller is not the delegator",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Delegation not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Delegation is already revoked",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Interval error",
                         "schema": {
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/messages": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "This endpoint deletes a user by providing user id.",
+                "description": "This endpoint lists messages by providing limit and skip.",
                 "consumes": [
                     "application/json"
                 ],
@@ -440,16 +577,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "messages"
+                ],
+                "summary": "List lists messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Messages limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Skip messages",
+                        "name": "skip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Receiver id",
+                        "name": "receiver_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sender id",
+                        "name": "sender_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    }
                 ],
-                "summary": "DeleteUser deletes an existing user",
                 "responses": {
                     "200": {
-                        "description": "user username",
+                        "description": "messages",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
                     },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
                     "500": {
                         "description": "Interval error",
                         "schema": {
@@ -458,13 +633,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "This endpoint updates a user by providing username, email, password, and role ID.",
+                "description": "This endpoint creates a new message by providing sender id, receiver id, text, and status. With draft set the message is saved as a draft that only the sender sees, until it is submitted through POST /messages/{id}/submit.",
                 "consumes": [
                     "application/json"
                 ],
@@ -472,23 +647,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "messages"
                 ],
-                "summary": "UpdateUser updates an existing user",
+                "summary": "Create creates a new message",
                 "parameters": [
                     {
-                        "description": "User update input",
+                        "description": "Message creation input",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.UserCreateRequest"
+                            "$ref": "#/definitions/model.MessageCreateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "user username",
+                    "201": {
+                        "description": "message id",
                         "schema": {
                             "$ref": "#/definitions/controller.SuccessResponse"
                         }
@@ -499,6 +674,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.FailureResponse"
                         }
                     },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
                     "500": {
                         "description": "Interval error",
                         "schema": {
@@ -507,20 +688,1729 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller.AuthResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                },
-                "token": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string",
+        },
+        "/messages/rejection-reasons": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists the reason codes a checker can use to reject a message.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "RejectionReasons lists the rejection reason catalog",
+                "responses": {
+                    "200": {
+                        "description": "rejection reasons",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/simulate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint runs the routing rules, the auto-approval rules and the approval chain on the message as if it was submitted now. It returns the resulting status, approval steps with the checkers eligible for each step, SLA and every rule that matched, the first one decides. Nothing is stored, assigned or audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Simulate routes a message without storing it",
+                "parameters": [
+                    {
+                        "description": "Message creation input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message simulation",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint gets a message by providing message id, including its approval steps and the active step.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "GetByID gets a message by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the message, send it as If-Match when deciding"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lets the sender replace the receiver, text, type and delivery time of a draft. The receiver and text may stay empty until the draft is submitted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Edit replaces the content of a draft",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the draft version the sender edited",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Draft edit input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageEditRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "edited draft",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the edited draft"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the sender",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Message is not a draft or was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint records the checker's vote on the current approval step of a message. The step is decided once its quorum is reached or can no longer be reached. The message is accepted after the last step, or rejected by any step.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Update updates an existing message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the message version the checker decided on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Message update input, status= accepted:2, rejected:3, changes requested:8, reason_code is required when rejecting, comment when requesting changes",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message id",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied, or the checker is the sender, the receiver or related to the sender (reason checker_is_sender, checker_is_receiver or checker_is_related)",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed or the message was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown status",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/assignee": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint assigns the current approval step of a pending message to the given checker, who must be allowed to decide on it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Assign reassigns a message to another checker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Checker to assign the message to",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageAssignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied, or the checker may not decide on the message, is its receiver or is related to the sender",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Message or checker not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Message is not pending",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "User is not a checker",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/recall": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lets the sender take back an accepted message, or a message in any other status its workflow allows recalling from. The recall is a change request of the message resource type, the message is only recalled once checkers approved it through /change-requests. The receiver then only sees that the message was recalled, the content stays available to the sender, checkers and auditors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Request asks to recall an accepted message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message recall input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageRecallRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "recall change request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the sender",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Workflow does not allow recalling the message or a recall is already pending",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/resubmit": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lets the sender edit the text of a message that a checker sent back, creating a new revision that is reviewed again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Resubmit resubmits a message after changes were requested",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the message version the sender resubmits",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Message resubmit input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageResubmitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message revision",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the resubmitted message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the sender",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed, or the message was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists every revision of a message text, oldest first, with a line diff against the previous revision.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Revisions lists the revisions of a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "revisions",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint validates a draft and moves it to pending: it needs a receiver and a text, a message type with an approval chain and a delivery time in the future, if any. The message then shows up in the checkers' review queues.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Submit submits a draft for review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the draft version the sender submits",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Draft submit input",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageSubmitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "submitted message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the submitted message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the sender",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Message is not a draft or was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lets the sender retract a message that is still pending, or discard a draft, or any other status its workflow allows withdrawing from. The message is removed from the review queues and stays in the sender's history as withdrawn.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Withdraw withdraws a message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the message version the sender withdraws",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Message withdraw input",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.MessageWithdrawRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "withdrawn message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the withdrawn message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the sender",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Message not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Message is neither pending nor a draft, or was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint takes the same decision on up to 500 messages. Every message is checked on its own against the same rules as PATCH /messages/{id}: it must be pending, the caller must be allowed to decide on its current step under the segregation of duties rules, and an optional version must still match. The response lists the result of every message in the order of the request, a failed message doesn't stop the others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Bulk decides on many messages at once",
+                "parameters": [
+                    {
+                        "description": "Bulk decision input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "result per message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown reason code",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/queue": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists the pending messages the caller may decide on, oldest first. Messages claimed by another checker are left out until the claim expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Queue lists the messages waiting for the caller",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Messages limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Skip messages",
+                        "name": "skip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "pending messages",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/{id}/claim": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint takes a time-limited lease on a pending message, so that no other checker decides on it meanwhile. Claiming a message the caller already holds renews the lease.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Claim claims a message for review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "claimed message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied or caller may not decide on the message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Message is not pending or is claimed by another checker",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint gives up the caller's lease on a message, so that other checkers can review it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reviews"
+                ],
+                "summary": "Release releases a claimed message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message id",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Caller holds no claim on the message",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/routing-rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists every routing rule, enabled or not, in the order they are evaluated in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "List lists the routing rules",
+                "responses": {
+                    "200": {
+                        "description": "routing rules",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint compiles the expression and stores the rule as its first version. The rule applies to the next message that goes up for review. Expressions can refer to text, type, sender.id, sender.username, sender.role, sender.groups, sender.team, receiver.id, receiver.username, receiver.role, receiver.groups and receiver.team.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "Create creates a routing rule",
+                "parameters": [
+                    {
+                        "description": "Routing rule input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RoutingRuleCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "routing rule",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid expression, action or group",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/routing-rules/simulate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint evaluates the draft rule, as if it was enabled, against the last submitted messages, newest first. For each message it returns the decision of the current rules, the decision with the draft rule, and whether the draft rule matched. Nothing is stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "Simulate evaluates a draft routing rule against recent messages",
+                "parameters": [
+                    {
+                        "description": "Draft routing rule and the number of messages, 100 by default",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RoutingRuleSimulateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "routing rule simulation",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid expression, action, group or limit",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/routing-rules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint returns the current version of a routing rule, with the version as ETag header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "GetByID returns a routing rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Routing rule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "routing rule",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the routing rule"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Routing rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint compiles the expression and replaces the name, expression, action, group and priority of the rule with a new version. The previous versions stay listed under /routing-rules/{id}/versions. Expressions can refer to text, type, sender.id, sender.username, sender.role, sender.groups, sender.team, receiver.id, receiver.username, receiver.role, receiver.groups and receiver.team.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "Update stores a new version of a routing rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Routing rule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rule version the admin edited",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Routing rule input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RoutingRuleUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "routing rule",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the routing rule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid expression, action or group",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Routing rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Routing rule was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/routing-rules/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint stores a new version of the rule that is skipped when messages are routed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "Disable disables a routing rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Routing rule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rule version the admin disables",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "routing rule",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the routing rule"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Routing rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Routing rule is already disabled or was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/routing-rules/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint stores a new version of the rule that is evaluated again when messages are routed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "Enable enables a disabled routing rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Routing rule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rule version the admin enables",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "routing rule",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the routing rule"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Routing rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Routing rule is already enabled or was changed meanwhile",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/routing-rules/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint lists every version of the rule, oldest first. Messages record the rule id and version that routed them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing-rules"
+                ],
+                "summary": "Versions lists the versions of a routing rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Routing rule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "routing rule versions",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Routing rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint creates a change request for a new user by providing username, email, password, and role. The user is created once another admin approves it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create requests a new user",
+                "parameters": [
+                    {
+                        "description": "User creation input",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UserCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "change request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a user by their unique ID from the database.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Retrieve user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response containing the user information",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint creates a change request to delete a user by providing user id. The user is deleted once another admin approves it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "DeleteUser requests the deletion of an existing user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "change request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This endpoint creates a change request for the fields of a user present in the body: username, email, password, role, related user ids and groups. The fields that are omitted keep their value. The user is updated once another admin approves it. An empty password keeps the current one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "UpdateUser requests an update of an existing user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User update input, omitted fields keep their value",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UserUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "change request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Error message including details on failure",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Interval error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.FailureResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
                     "example": "basic,oauth2"
                 },
                 "user_id": {
@@ -539,6 +2429,9 @@ const docTemplate = `{
                 },
                 "message": {
                     "type": "string"
+                },
+                "reason": {
+                    "type": "string"
                 }
             }
         },
@@ -551,6 +2444,71 @@ const docTemplate = `{
                 }
             }
         },
+        "model.BulkReviewItem": {
+            "type": "object",
+            "properties": {
+                "message_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the message version the checker decided on, it is optional",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.BulkReviewRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BulkReviewItem"
+                    }
+                },
+                "on_behalf_of": {
+                    "description": "OnBehalfOf is the checker the caller decides for, through an active delegation of that checker",
+                    "type": "string"
+                },
+                "reason_code": {
+                    "description": "ReasonCode is required when rejecting and must be in the rejection reasons catalog",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ChangeRequestDecision": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DelegationCreateRequest": {
+            "type": "object",
+            "properties": {
+                "delegate_id": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "message_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Login": {
             "type": "object",
             "required": [
@@ -566,22 +2524,116 @@ const docTemplate = `{
                 }
             }
         },
+        "model.MessageAssignRequest": {
+            "type": "object",
+            "properties": {
+                "checker_id": {
+                    "type": "string"
+                }
+            }
+        },
         "model.MessageCreateRequest": {
             "type": "object",
             "properties": {
+                "deliver_at": {
+                    "description": "DeliverAt schedules the delivery, the receiver only sees the accepted message from then on. It is optional\nand must be in the future.",
+                    "type": "string"
+                },
+                "draft": {
+                    "description": "Draft saves the message without submitting it for review, the receiver and text may still be incomplete",
+                    "type": "boolean"
+                },
+                "receiver_id": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MessageEditRequest": {
+            "type": "object",
+            "properties": {
+                "deliver_at": {
+                    "type": "string"
+                },
                 "receiver_id": {
                     "type": "string"
                 },
                 "text": {
                     "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the draft version the sender edited, the edit is refused if the draft changed since.\nIt is optional, PUT /messages/:id also takes it from the If-Match header.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MessageRecallRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Reason tells the checkers why the accepted message should be recalled",
+                    "type": "string"
+                }
+            }
+        },
+        "model.MessageResubmitRequest": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the message version the sender resubmits, the resubmission is refused if the message changed since.\nIt is optional, POST /messages/:id/resubmit also takes it from the If-Match header.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MessageSubmitRequest": {
+            "type": "object",
+            "properties": {
+                "version": {
+                    "description": "Version is the draft version the sender submits, the submission is refused if the draft changed since.\nIt is optional, POST /messages/:id/submit also takes it from the If-Match header.",
+                    "type": "integer"
                 }
             }
         },
         "model.MessageUpdateRequest": {
             "type": "object",
             "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "on_behalf_of": {
+                    "description": "OnBehalfOf is the checker the caller decides for, through an active delegation of that checker",
+                    "type": "string"
+                },
+                "reason_code": {
+                    "description": "ReasonCode is required when rejecting and must be in the rejection reasons catalog",
+                    "type": "string"
+                },
                 "status": {
                     "type": "integer"
+                },
+                "version": {
+                    "description": "Version is the message version the checker decided on, the decision is refused if the message changed since.\nIt is optional, PATCH /messages/:id also takes it from the If-Match header.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MessageWithdrawRequest": {
+            "type": "object",
+            "properties": {
+                "version": {
+                    "description": "Version is the message version the sender withdraws, the withdrawal is refused if the message changed since.\nIt is optional, POST /messages/:id/withdraw also takes it from the If-Match header.",
+                    "type": "integer"
                 }
             }
         },
@@ -607,6 +2659,64 @@ const docTemplate = `{
                 }
             }
         },
+        "model.RoutingRuleCreateRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "accept,checker,senior_checker"
+                },
+                "expression": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RoutingRuleSimulateRequest": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "Limit is the number of most recent submitted messages the rule is simulated against, 100 by default and at most 1000",
+                    "type": "integer"
+                },
+                "rule": {
+                    "$ref": "#/definitions/model.RoutingRuleCreateRequest"
+                }
+            }
+        },
+        "model.RoutingRuleUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "accept,checker,senior_checker"
+                },
+                "expression": {
+                    "type": "string"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "Version is the rule version the admin edited, the update is refused if the rule changed since.\nIt is optional, PUT /routing-rules/:id also takes it from the If-Match header.",
+                    "type": "integer"
+                }
+            }
+        },
         "model.UserCreateRequest": {
             "type": "object",
             "required": [
@@ -618,7 +2728,65 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "groups": {
+                    "description": "Groups are the named checker groups, e.g. compliance or team-leads",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "password": {
+                    "type": "string"
+                },
+                "related_user_ids": {
+                    "description": "RelatedUserIDs lists users with a conflict of interest, e.g. family members or direct reports",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string",
+                    "example": "maker,checker,admin"
+                },
+                "team": {
+                    "description": "Team is the department the user works in, e.g. finance",
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UserUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "groups": {
+                    "description": "Groups replaces the checker groups, an empty list removes them all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "password": {
+                    "description": "Password is empty or omitted to keep the current one",
+                    "type": "string"
+                },
+                "related_user_ids": {
+                    "description": "RelatedUserIDs replaces the users with a conflict of interest, an empty list removes them all",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string",
+                    "example": "maker,checker,admin"
+                },
+                "team": {
                     "type": "string"
                 },
                 "username": {
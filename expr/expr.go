@@ -0,0 +1,130 @@
+// Package expr compiles and evaluates boolean expressions over named fields, e.g.
+//
+//	len(text) > 500 && "finance" in receiver.groups
+//
+// An expression is type checked against the declared fields when it is compiled, so a compiled program always
+// evaluates to a bool and can't fail. The language has:
+//
+//   - literals: integers, double-quoted strings, true, false and lists of strings like ["a", "b"]
+//   - fields: the declared names, which may contain dots, like sender.role
+//   - comparisons: == and != on values of the same type, <, <=, > and >= on integers and strings
+//   - membership: s in list, true if the list contains the string s
+//   - logic: !, && and || on bools, && binds tighter than ||, and both stop at the first operand that decides
+//   - functions: len(string or list), lower(string), upper(string), contains(string, substring),
+//     startsWith(string, prefix) and endsWith(string, suffix)
+//   - parentheses for grouping
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxSourceLength and maxDepth bound the work done by a single expression
+const (
+	maxSourceLength = 4096
+	maxDepth        = 64
+)
+
+// Type is the static type of a value
+type Type int
+
+const (
+	TypeBool Type = iota + 1
+	TypeInt
+	TypeString
+	// TypeList is a list of strings
+	TypeList
+)
+
+// Fields declares the names an expression can refer to and their types
+type Fields map[string]Type
+
+// Env holds the field values an expression is evaluated with: bool, int, string or []string as declared.
+// A missing field evaluates to the zero value of its type.
+type Env map[string]any
+
+// Program is a compiled expression
+type Program struct {
+	source string
+	eval   evalFunc
+}
+
+// Error is a syntax or type error of an expression, Pos is the 1-based character offset it was found at
+type Error struct {
+	Pos int
+	Msg string
+}
+
+type evalFunc func(env Env) any
+
+// Compile parses and type checks the expression against the fields, the expression must evaluate to a bool
+func Compile(source string, fields Fields) (*Program, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, &Error{Pos: 1, Msg: "expression is empty"}
+	}
+
+	if len(source) > maxSourceLength {
+		return nil, &Error{Pos: maxSourceLength, Msg: fmt.Sprintf("expression is longer than %d bytes", maxSourceLength)}
+	}
+
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens, fields: fields}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if root.typ != TypeBool {
+		return nil, &Error{Pos: root.pos, Msg: fmt.Sprintf("expression must be a bool, not %s", root.typ)}
+	}
+
+	return &Program{source: source, eval: root.eval}, nil
+}
+
+// Eval evaluates the program with the field values of the environment
+func (rc *Program) Eval(env Env) bool {
+	return rc.eval(env).(bool)
+}
+
+// String returns the source of the program
+func (rc *Program) String() string {
+	return rc.source
+}
+
+func (rc *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", rc.Pos, rc.Msg)
+}
+
+func (rc Type) String() string {
+	switch rc {
+	case TypeBool:
+		return "bool"
+	case TypeInt:
+		return "int"
+	case TypeString:
+		return "string"
+	case TypeList:
+		return "list"
+	}
+
+	return fmt.Sprintf("type(%d)", int(rc))
+}
+
+// zero returns the value of a field that is missing from the environment
+func (rc Type) zero() any {
+	switch rc {
+	case TypeBool:
+		return false
+	case TypeInt:
+		return 0
+	case TypeString:
+		return ""
+	}
+
+	return []string(nil)
+}
@@ -0,0 +1,113 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+)
+
+var testFields = Fields{
+	"text":            TypeString,
+	"type":            TypeString,
+	"urgent":          TypeBool,
+	"attempts":        TypeInt,
+	"receiver.groups": TypeList,
+	"receiver.role":   TypeString,
+}
+
+func TestProgramEval(t *testing.T) {
+	env := Env{
+		"text":            "Please pay the Invoice",
+		"type":            "payment",
+		"urgent":          true,
+		"attempts":        3,
+		"receiver.groups": []string{"finance", "team-leads"},
+		"receiver.role":   "checker",
+	}
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{source: `len(text) > 10 && "finance" in receiver.groups`, want: true},
+		{source: `len(text) > 500 && "finance" in receiver.groups`, want: false},
+		{source: `type == "payment" || type == "wire"`, want: true},
+		{source: `type in ["wire", "transfer"]`, want: false},
+		{source: `contains(lower(text), "invoice")`, want: true},
+		{source: `contains(text, "invoice")`, want: false},
+		{source: `startsWith(text, "Please") && endsWith(upper(text), "INVOICE")`, want: true},
+		{source: `!urgent`, want: false},
+		{source: `!(attempts >= 3)`, want: false},
+		{source: `attempts != 3 || urgent == true`, want: true},
+		{source: `attempts <= 2 || attempts < 3`, want: false},
+		{source: `receiver.role >= "admin" && receiver.role < "maker"`, want: true},
+		{source: `len(receiver.groups) == 2`, want: true},
+		{source: `false || true && false`, want: false},
+		{source: `(false || true) && true`, want: true},
+		{source: `"team-\"leads\"" in []`, want: false},
+		{source: `len("żółw") == 4`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := Compile(tt.source, testFields)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+
+			if got := program.Eval(env); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgramEvalMissingFields(t *testing.T) {
+	program, err := Compile(`text == "" && len(receiver.groups) == 0 && !urgent && attempts == 0`, testFields)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	if !program.Eval(Env{}) {
+		t.Error("Eval() = false, want missing fields to be zero values")
+	}
+}
+
+func TestCompileRejectsInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		pos    int
+	}{
+		{name: "empty", source: "  ", pos: 1},
+		{name: "not a bool", source: "len(text)", pos: 1},
+		{name: "unknown field", source: `receiver.team == "finance"`, pos: 1},
+		{name: "unknown function", source: `size(text) > 1`, pos: 1},
+		{name: "wrong argument types", source: `contains(text, 1)`, pos: 1},
+		{name: "comparing different types", source: `attempts == "3"`, pos: 10},
+		{name: "ordering bools", source: `urgent > false`, pos: 8},
+		{name: "comparing lists", source: `receiver.groups == ["a"]`, pos: 17},
+		{name: "in needs a list", source: `"a" in text`, pos: 8},
+		{name: "and needs bools", source: `urgent && text`, pos: 11},
+		{name: "unterminated string", source: `text == "abc`, pos: 9},
+		{name: "unexpected character", source: `text = "a"`, pos: 6},
+		{name: "missing parenthesis", source: `(urgent`, pos: 8},
+		{name: "trailing tokens", source: `urgent urgent`, pos: 8},
+		{name: "list of fields", source: `type in [text]`, pos: 10},
+		{name: "integer out of range", source: `attempts > 99999999999999999999`, pos: 12},
+		{name: "nested too deep", source: "!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!urgent", pos: 65},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source, testFields)
+
+			var exprErr *Error
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("Compile() error = %v, want an *Error", err)
+			}
+			if exprErr.Pos != tt.pos {
+				t.Errorf("Compile() error at position %d, want %d: %v", exprErr.Pos, tt.pos, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package expr
+
+import (
+	"errors"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenString
+	// tokenOp is an operator or punctuation, its text tells which one
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the unquoted value of a string token
+	value string
+	pos   int
+}
+
+// operators are matched longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","}
+
+func lex(source string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(source); {
+		r, size := utf8.DecodeRuneInString(source[i:])
+		pos := utf8.RuneCountInString(source[:i]) + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case isIdentStart(r):
+			end := i + size
+			for end < len(source) {
+				r, size := utf8.DecodeRuneInString(source[end:])
+				if !isIdentPart(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[i:end], pos: pos})
+			i = end
+		case r >= '0' && r <= '9':
+			end := i + 1
+			for end < len(source) && source[end] >= '0' && source[end] <= '9' {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenInt, text: source[i:end], pos: pos})
+			i = end
+		case r == '"':
+			end, err := stringEnd(source, i)
+			if err != nil {
+				return nil, &Error{Pos: pos, Msg: err.Error()}
+			}
+			value, err := strconv.Unquote(source[i:end])
+			if err != nil {
+				return nil, &Error{Pos: pos, Msg: "invalid string literal " + source[i:end]}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[i:end], value: value, pos: pos})
+			i = end
+		default:
+			op := matchOperator(source[i:])
+			if op == "" {
+				return nil, &Error{Pos: pos, Msg: "unexpected character " + strconv.QuoteRune(r)}
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: pos})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: utf8.RuneCountInString(source) + 1}), nil
+}
+
+// stringEnd returns the offset after the closing quote of the string literal starting at start
+func stringEnd(source string, start int) (int, error) {
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		case '\n':
+			return 0, errors.New("string literal is not terminated")
+		}
+	}
+
+	return 0, errors.New("string literal is not terminated")
+}
+
+func matchOperator(source string) string {
+	for _, v := range operators {
+		if len(source) >= len(v) && source[:len(v)] == v {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isIdentPart allows dots inside identifiers, field names like sender.role are a single identifier
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,429 @@
+package expr
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// node is a type checked expression, compiled to the function that evaluates it
+type node struct {
+	typ  Type
+	pos  int
+	eval evalFunc
+}
+
+// function is a builtin function, args are the types of its parameters
+type function struct {
+	args []Type
+	ret  Type
+	call func(args []any) any
+}
+
+var functions = map[string][]function{
+	"len": {
+		{args: []Type{TypeString}, ret: TypeInt, call: func(args []any) any { return utf8.RuneCountInString(args[0].(string)) }},
+		{args: []Type{TypeList}, ret: TypeInt, call: func(args []any) any { return len(args[0].([]string)) }},
+	},
+	"lower": {
+		{args: []Type{TypeString}, ret: TypeString, call: func(args []any) any { return strings.ToLower(args[0].(string)) }},
+	},
+	"upper": {
+		{args: []Type{TypeString}, ret: TypeString, call: func(args []any) any { return strings.ToUpper(args[0].(string)) }},
+	},
+	"contains": {
+		{args: []Type{TypeString, TypeString}, ret: TypeBool, call: func(args []any) any {
+			return strings.Contains(args[0].(string), args[1].(string))
+		}},
+	},
+	"startsWith": {
+		{args: []Type{TypeString, TypeString}, ret: TypeBool, call: func(args []any) any {
+			return strings.HasPrefix(args[0].(string), args[1].(string))
+		}},
+	},
+	"endsWith": {
+		{args: []Type{TypeString, TypeString}, ret: TypeBool, call: func(args []any) any {
+			return strings.HasSuffix(args[0].(string), args[1].(string))
+		}},
+	},
+}
+
+// parser is a recursive descent parser, from the lowest precedence to the highest:
+//
+//	or         = and { "||" and }
+//	and        = comparison { "&&" comparison }
+//	comparison = unary [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "in" ) unary ]
+//	unary      = "!" unary | primary
+//	primary    = int | string | "true" | "false" | list | field | call | "(" or ")"
+//	list       = "[" [ string { "," string } ] "]"
+//	call       = name "(" [ or { "," or } ] ")"
+type parser struct {
+	tokens []token
+	cur    int
+	depth  int
+	fields Fields
+}
+
+func (rc *parser) parse() (*node, error) {
+	root, err := rc.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := rc.peek(); tok.kind != tokenEOF {
+		return nil, &Error{Pos: tok.pos, Msg: "unexpected " + describe(tok)}
+	}
+
+	return root, nil
+}
+
+func (rc *parser) or() (*node, error) {
+	return rc.logical("||", rc.and, func(left, right evalFunc) evalFunc {
+		return func(env Env) any { return left(env).(bool) || right(env).(bool) }
+	})
+}
+
+func (rc *parser) and() (*node, error) {
+	return rc.logical("&&", rc.comparison, func(left, right evalFunc) evalFunc {
+		return func(env Env) any { return left(env).(bool) && right(env).(bool) }
+	})
+}
+
+func (rc *parser) logical(op string, operand func() (*node, error), combine func(left, right evalFunc) evalFunc) (*node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for rc.acceptOp(op) {
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := expectType(left, TypeBool, op); err != nil {
+			return nil, err
+		}
+		if err := expectType(right, TypeBool, op); err != nil {
+			return nil, err
+		}
+
+		left = &node{typ: TypeBool, pos: left.pos, eval: combine(left.eval, right.eval)}
+	}
+
+	return left, nil
+}
+
+func (rc *parser) comparison() (*node, error) {
+	left, err := rc.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := rc.peek()
+	isIn := tok.kind == tokenIdent && tok.text == "in"
+	if !isIn && (tok.kind != tokenOp || !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, tok.text)) {
+		return left, nil
+	}
+	rc.cur++
+
+	right, err := rc.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	if isIn {
+		if err := expectType(left, TypeString, "in"); err != nil {
+			return nil, err
+		}
+		if err := expectType(right, TypeList, "in"); err != nil {
+			return nil, err
+		}
+
+		return &node{typ: TypeBool, pos: left.pos, eval: func(env Env) any {
+			return slices.Contains(right.eval(env).([]string), left.eval(env).(string))
+		}}, nil
+	}
+
+	if left.typ != right.typ {
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("can't compare %s with %s", left.typ, right.typ)}
+	}
+
+	ordered := tok.text != "==" && tok.text != "!="
+	if left.typ == TypeList || ordered && left.typ == TypeBool {
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("operator %s is not defined on %s", tok.text, left.typ)}
+	}
+
+	compare := comparator(tok.text)
+	if left.typ == TypeInt {
+		return &node{typ: TypeBool, pos: left.pos, eval: func(env Env) any {
+			a, b := left.eval(env).(int), right.eval(env).(int)
+			return compare(a < b, a == b)
+		}}, nil
+	}
+	if left.typ == TypeString {
+		return &node{typ: TypeBool, pos: left.pos, eval: func(env Env) any {
+			a, b := left.eval(env).(string), right.eval(env).(string)
+			return compare(a < b, a == b)
+		}}, nil
+	}
+
+	return &node{typ: TypeBool, pos: left.pos, eval: func(env Env) any {
+		return compare(false, left.eval(env).(bool) == right.eval(env).(bool))
+	}}, nil
+}
+
+// comparator returns the outcome of the comparison operator from whether the left operand is less than or equal to the right one
+func comparator(op string) func(less, equal bool) bool {
+	switch op {
+	case "==":
+		return func(_, equal bool) bool { return equal }
+	case "!=":
+		return func(_, equal bool) bool { return !equal }
+	case "<":
+		return func(less, _ bool) bool { return less }
+	case "<=":
+		return func(less, equal bool) bool { return less || equal }
+	case ">":
+		return func(less, equal bool) bool { return !less && !equal }
+	}
+
+	return func(less, _ bool) bool { return !less }
+}
+
+func (rc *parser) unary() (*node, error) {
+	tok := rc.peek()
+	if !rc.acceptOp("!") {
+		return rc.primary()
+	}
+
+	if err := rc.enter(tok); err != nil {
+		return nil, err
+	}
+	defer rc.leave()
+
+	operand, err := rc.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expectType(operand, TypeBool, "!"); err != nil {
+		return nil, err
+	}
+
+	return &node{typ: TypeBool, pos: tok.pos, eval: func(env Env) any { return !operand.eval(env).(bool) }}, nil
+}
+
+func (rc *parser) primary() (*node, error) {
+	tok := rc.next()
+
+	switch tok.kind {
+	case tokenInt:
+		value, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, &Error{Pos: tok.pos, Msg: "integer " + tok.text + " is out of range"}
+		}
+		return constant(TypeInt, tok.pos, value), nil
+	case tokenString:
+		return constant(TypeString, tok.pos, tok.value), nil
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return constant(TypeBool, tok.pos, tok.text == "true"), nil
+		case "in":
+			return nil, &Error{Pos: tok.pos, Msg: "unexpected in"}
+		}
+		if rc.peek().kind == tokenOp && rc.peek().text == "(" {
+			return rc.call(tok)
+		}
+		return rc.field(tok)
+	case tokenOp:
+		switch tok.text {
+		case "(":
+			if err := rc.enter(tok); err != nil {
+				return nil, err
+			}
+			defer rc.leave()
+
+			inner, err := rc.or()
+			if err != nil {
+				return nil, err
+			}
+			if err := rc.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			return rc.list(tok)
+		}
+	}
+
+	return nil, &Error{Pos: tok.pos, Msg: "unexpected " + describe(tok)}
+}
+
+func (rc *parser) field(tok token) (*node, error) {
+	typ, ok := rc.fields[tok.text]
+	if !ok {
+		return nil, &Error{Pos: tok.pos, Msg: "unknown field " + tok.text}
+	}
+
+	name := tok.text
+
+	return &node{typ: typ, pos: tok.pos, eval: func(env Env) any {
+		value, ok := env[name]
+		if !ok || value == nil {
+			return typ.zero()
+		}
+		return value
+	}}, nil
+}
+
+func (rc *parser) list(open token) (*node, error) {
+	var values []string
+
+	if !rc.acceptOp("]") {
+		for {
+			tok := rc.next()
+			if tok.kind != tokenString {
+				return nil, &Error{Pos: tok.pos, Msg: "lists can only hold string literals, found " + describe(tok)}
+			}
+			values = append(values, tok.value)
+
+			if rc.acceptOp("]") {
+				break
+			}
+			if err := rc.expectOp(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return constant(TypeList, open.pos, values), nil
+}
+
+func (rc *parser) call(name token) (*node, error) {
+	overloads, ok := functions[name.text]
+	if !ok {
+		return nil, &Error{Pos: name.pos, Msg: "unknown function " + name.text}
+	}
+
+	if err := rc.enter(name); err != nil {
+		return nil, err
+	}
+	defer rc.leave()
+
+	rc.cur++ // the opening parenthesis
+
+	var args []*node
+	if !rc.acceptOp(")") {
+		for {
+			arg, err := rc.or()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if rc.acceptOp(")") {
+				break
+			}
+			if err := rc.expectOp(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	argTypes := make([]Type, 0, len(args))
+	for _, v := range args {
+		argTypes = append(argTypes, v.typ)
+	}
+
+	idx := slices.IndexFunc(overloads, func(v function) bool { return slices.Equal(v.args, argTypes) })
+	if idx < 0 {
+		return nil, &Error{Pos: name.pos, Msg: fmt.Sprintf("%s can't be called with (%s)", name.text, joinTypes(argTypes))}
+	}
+	fn := overloads[idx]
+
+	return &node{typ: fn.ret, pos: name.pos, eval: func(env Env) any {
+		values := make([]any, 0, len(args))
+		for _, v := range args {
+			values = append(values, v.eval(env))
+		}
+		return fn.call(values)
+	}}, nil
+}
+
+// enter guards against nesting that would exhaust the stack
+func (rc *parser) enter(tok token) error {
+	rc.depth++
+	if rc.depth > maxDepth {
+		return &Error{Pos: tok.pos, Msg: fmt.Sprintf("expression is nested deeper than %d levels", maxDepth)}
+	}
+
+	return nil
+}
+
+func (rc *parser) leave() {
+	rc.depth--
+}
+
+func (rc *parser) peek() token {
+	return rc.tokens[rc.cur]
+}
+
+func (rc *parser) next() token {
+	tok := rc.tokens[rc.cur]
+	if tok.kind != tokenEOF {
+		rc.cur++
+	}
+
+	return tok
+}
+
+func (rc *parser) acceptOp(op string) bool {
+	tok := rc.peek()
+	if tok.kind != tokenOp || tok.text != op {
+		return false
+	}
+	rc.cur++
+
+	return true
+}
+
+func (rc *parser) expectOp(op string) error {
+	if tok := rc.peek(); !rc.acceptOp(op) {
+		return &Error{Pos: tok.pos, Msg: fmt.Sprintf("expected %s, found %s", op, describe(tok))}
+	}
+
+	return nil
+}
+
+func expectType(n *node, typ Type, op string) error {
+	if n.typ != typ {
+		return &Error{Pos: n.pos, Msg: fmt.Sprintf("%s needs a %s operand, not %s", op, typ, n.typ)}
+	}
+
+	return nil
+}
+
+func constant(typ Type, pos int, value any) *node {
+	return &node{typ: typ, pos: pos, eval: func(Env) any { return value }}
+}
+
+func describe(tok token) string {
+	if tok.kind == tokenEOF {
+		return "end of expression"
+	}
+
+	return strconv.Quote(tok.text)
+}
+
+func joinTypes(types []Type) string {
+	names := make([]string, 0, len(types))
+	for _, v := range types {
+		names = append(names, v.String())
+	}
+
+	return strings.Join(names, ", ")
+}
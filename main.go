@@ -3,9 +3,13 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	"strings"
 
+	"github.com/fleimkeipa/maker-checker/changerequest"
 	"github.com/fleimkeipa/maker-checker/controller"
 	_ "github.com/fleimkeipa/maker-checker/docs" // which is the generated folder after swag init
+	"github.com/fleimkeipa/maker-checker/model"
 	"github.com/fleimkeipa/maker-checker/pkg"
 	"github.com/fleimkeipa/maker-checker/repositories"
 	"github.com/fleimkeipa/maker-checker/uc"
@@ -41,19 +45,83 @@ func serveApplication() {
 	sugar := configureLogger(e)
 	defer sugar.Sync() // Clean up logger at the end
 
+	cfg := loadConfig()
+
 	mongoClient := initMongo()
 	defer mongoClient.Client().Disconnect(context.TODO())
 
+	// Initialize the audit trail
+	auditMongoRepo := repositories.NewAuditMongoRepo(mongoClient)
+	if err := auditMongoRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Fatalf("failed to init audit trail: %v", err)
+	}
+	auditUC := uc.NewAuditUC(auditMongoRepo)
+	auditController := controller.NewAuditHandlers(auditUC)
+
+	// Initialize the change request engine, resources register their appliers on it
+	changeRequestMongoRepo := repositories.NewChangeRequestMongoRepo(mongoClient)
+	changeEngine := changerequest.NewEngine(changeRequestMongoRepo, auditUC, cfg.ChangeRequestPolicies)
+	changeRequestController := controller.NewChangeRequestHandlers(changeEngine)
+
 	// Initialize the user use case
 	userMongoRepo := repositories.NewUserMongoRepo(mongoClient)
-	userUC := uc.NewUserUC(userMongoRepo)
+	userUC := uc.NewUserUC(userMongoRepo, changeEngine, auditUC)
+	changeEngine.Register(model.ChangeResourceUser, uc.NewUserApplier(userUC))
 	userController := controller.NewUserHandlers(userUC)
 
+	// Create the initial admin account, if configured
+	ensureAdmin(userUC)
+
 	authHandlers := controller.NewAuthHandlers(userUC)
 
 	messageMongoRepo := repositories.NewMsgMongoRepo(mongoClient)
-	messageUC := uc.NewMessageUC(messageMongoRepo)
+	revisionMongoRepo := repositories.NewRevisionMongoRepo(mongoClient)
+	delegationMongoRepo := repositories.NewDelegationMongoRepo(mongoClient)
+	conflictRules := uc.NewConflictRuleEngine(uc.DefaultConflictRules(userMongoRepo)...)
+	assignmentStrategy, err := uc.NewAssignmentStrategy(cfg.AssignmentStrategy, messageMongoRepo, userMongoRepo)
+	if err != nil {
+		log.Fatalf("failed to init assignment strategy: %v", err)
+	}
+	assigner := uc.NewAssigner(userMongoRepo, conflictRules, assignmentStrategy)
+	businessCalendar, err := cfg.Calendar()
+	if err != nil {
+		log.Fatalf("failed to init business calendar: %v", err)
+	}
+	// Routing rules managed through the API are evaluated before the configured auto-approval rules
+	routingRuleMongoRepo := repositories.NewRoutingRuleMongoRepo(mongoClient)
+	expressionPolicy := uc.NewExpressionPolicy(routingRuleMongoRepo)
+	rulePolicy := uc.NewRulePolicy(cfg.AutoApproval)
+	routingRuleUC := uc.NewRoutingRuleUC(routingRuleMongoRepo, messageMongoRepo, userMongoRepo, auditUC, expressionPolicy, rulePolicy)
+	routingRuleController := controller.NewRoutingRuleHandlers(routingRuleUC)
+	approvalPolicy := uc.PolicyChain{expressionPolicy, rulePolicy}
+	// Message types without a workflow definition follow the default workflow with their approval chain and SLA policy
+	workflows := uc.NewWorkflows(cfg.Workflows, cfg.ApprovalChains, cfg.SLAPolicies, pkg.NewLogNotifier(sugar), func(err error) {
+		sugar.Errorw("notification failed", "error", err)
+	})
+	messageUC := uc.NewMessageUC(messageMongoRepo, userMongoRepo, revisionMongoRepo, delegationMongoRepo, auditUC, conflictRules, assigner, workflows, cfg.RejectionReasons, businessCalendar, approvalPolicy)
 	messageController := controller.NewMessageHandlers(messageUC)
+	changeEngine.Register(model.ChangeResourceMessage, uc.NewMessageRecallApplier(messageUC, changeEngine))
+
+	recallUC := uc.NewRecallUC(changeEngine)
+	recallController := controller.NewRecallHandlers(recallUC)
+
+	delegationUC := uc.NewDelegationUC(delegationMongoRepo, userMongoRepo, auditUC, workflows)
+	delegationController := controller.NewDelegationHandlers(delegationUC)
+
+	reviewUC := uc.NewReviewUC(messageUC, cfg.ReviewLeaseTTL)
+	reviewController := controller.NewReviewHandlers(reviewUC)
+
+	// Start the SLA scheduler, it reminds, escalates and expires pending messages in the background
+	slaScheduler := uc.NewSLAScheduler(messageUC, pkg.NewLogNotifier(sugar), cfg.SLACheckInterval)
+	go slaScheduler.Run(context.Background(), func(err error) {
+		sugar.Errorw("sla check failed", "error", err)
+	})
+
+	// Start the delivery worker, it delivers accepted messages once their scheduled delivery time has come
+	deliveryWorker := uc.NewDeliveryWorker(messageUC, pkg.NewLogNotifier(sugar), cfg.DeliveryCheckInterval)
+	go deliveryWorker.Run(context.Background(), func(err error) {
+		sugar.Errorw("message delivery failed", "error", err)
+	})
 
 	// Define authentication routes and handlers
 	authRoutes := e.Group("/auth")
@@ -62,10 +130,10 @@ func serveApplication() {
 
 	// Define user routes
 	userRoutes := e.Group("")
-	userRoutes.Use(util.JWTAuthUser)
+	userRoutes.Use(util.JWTAuthUser, util.CurrentOwner(userMongoRepo))
 
 	// Define user routes
-	usersRoutes := userRoutes.Group("/users")
+	usersRoutes := userRoutes.Group("/users", util.RequireRoles(model.RoleAdmin))
 	usersRoutes.GET("/:id", userController.GetByID)
 	usersRoutes.POST("", userController.Create)
 	usersRoutes.PATCH("/:id", userController.UpdateUser)
@@ -73,11 +141,55 @@ func serveApplication() {
 
 	// Define message routes
 	messageRoutes := userRoutes.Group("/messages")
+	messageRoutes.GET("/rejection-reasons", messageController.RejectionReasons)
 	messageRoutes.GET("/:id", messageController.GetByID)
-	messageRoutes.POST("", messageController.Create)
-	messageRoutes.PATCH("/:id", messageController.Update)
+	messageRoutes.POST("", messageController.Create, util.RequireRoles(model.RoleMaker))
+	messageRoutes.POST("/simulate", messageController.Simulate, util.RequireRoles(model.RoleMaker))
+	messageRoutes.PATCH("/:id", messageController.Update, util.RequireRoles(model.RoleChecker))
+	messageRoutes.PUT("/:id", messageController.Edit, util.RequireRoles(model.RoleMaker))
+	messageRoutes.POST("/:id/submit", messageController.Submit, util.RequireRoles(model.RoleMaker))
+	messageRoutes.POST("/:id/resubmit", messageController.Resubmit, util.RequireRoles(model.RoleMaker))
+	messageRoutes.POST("/:id/withdraw", messageController.Withdraw, util.RequireRoles(model.RoleMaker))
+	messageRoutes.POST("/:id/recall", recallController.Request, util.RequireRoles(model.RoleMaker))
+	messageRoutes.PATCH("/:id/assignee", messageController.Assign, util.RequireRoles(model.RoleAdmin))
+	messageRoutes.GET("/:id/revisions", messageController.Revisions)
 	messageRoutes.GET("", messageController.List)
 
+	// Define review routes
+	reviewRoutes := userRoutes.Group("/reviews", util.RequireRoles(model.RoleChecker))
+	reviewRoutes.GET("/queue", reviewController.Queue)
+	reviewRoutes.POST("/bulk", reviewController.Bulk)
+	reviewRoutes.POST("/:id/claim", reviewController.Claim)
+	reviewRoutes.DELETE("/:id/claim", reviewController.Release)
+
+	// Define delegation routes
+	delegationRoutes := userRoutes.Group("/delegations", util.RequireRoles(model.RoleChecker))
+	delegationRoutes.GET("", delegationController.List)
+	delegationRoutes.POST("", delegationController.Create)
+	delegationRoutes.DELETE("/:id", delegationController.Revoke)
+
+	// Define routing rule routes
+	routingRuleRoutes := userRoutes.Group("/routing-rules", util.RequireRoles(model.RoleAdmin))
+	routingRuleRoutes.GET("", routingRuleController.List)
+	routingRuleRoutes.POST("", routingRuleController.Create)
+	routingRuleRoutes.POST("/simulate", routingRuleController.Simulate)
+	routingRuleRoutes.GET("/:id", routingRuleController.GetByID)
+	routingRuleRoutes.PUT("/:id", routingRuleController.Update)
+	routingRuleRoutes.POST("/:id/disable", routingRuleController.Disable)
+	routingRuleRoutes.POST("/:id/enable", routingRuleController.Enable)
+	routingRuleRoutes.GET("/:id/versions", routingRuleController.Versions)
+
+	// Define change request routes, the approver roles are checked per resource type
+	changeRequestRoutes := userRoutes.Group("/change-requests", util.RequireRoles(model.RoleAdmin, model.RoleChecker))
+	changeRequestRoutes.GET("", changeRequestController.List)
+	changeRequestRoutes.GET("/:id", changeRequestController.GetByID)
+	changeRequestRoutes.POST("/:id/approve", changeRequestController.Approve)
+	changeRequestRoutes.POST("/:id/reject", changeRequestController.Reject)
+
+	// Define audit routes
+	auditRoutes := userRoutes.Group("/audit", util.RequireRoles(model.RoleAdmin))
+	auditRoutes.GET("/verify", auditController.Verify)
+
 	e.Logger.Fatal(e.Start(":8080"))
 }
 
@@ -91,6 +203,10 @@ func configureEcho(e *echo.Echo) {
 
 	// Add Recover middleware
 	e.Use(middleware.Recover())
+
+	// Add request id and client ip to the request context, for the audit trail
+	e.Use(middleware.RequestID())
+	e.Use(util.RequestMeta)
 }
 
 // Configures CORS settings
@@ -99,8 +215,9 @@ func configureCORS(e *echo.Echo) {
 		UnsafeWildcardOriginWithAllowCredentials: true,
 		AllowCredentials:                         true,
 		AllowOrigins:                             []string{"*"},
-		AllowMethods:                             []string{echo.GET, echo.POST, echo.PATCH, echo.DELETE},
-		AllowHeaders:                             []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowMethods:                             []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
+		AllowHeaders:                             []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "If-Match"},
+		ExposeHeaders:                            []string{"ETag"},
 	})
 
 	e.Use(corsConfig)
@@ -122,6 +239,15 @@ func configureLogger(e *echo.Echo) *zap.SugaredLogger {
 	return sugar
 }
 
+func loadConfig() *pkg.Config {
+	cfg, err := pkg.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	return cfg
+}
+
 func initMongo() *mongo.Database {
 	mongo, err := pkg.MongoConnect()
 	if err != nil {
@@ -132,3 +258,23 @@ func initMongo() *mongo.Database {
 
 	return mongo
 }
+
+// Creates the admin accounts from ADMIN_USERNAME and ADMIN_PASSWORD, so that there is someone to manage users.
+// Both can be comma separated lists, since approving a user change takes two admins.
+func ensureAdmin(userUC *uc.UserUC) {
+	username, password := os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD")
+	if username == "" || password == "" {
+		return
+	}
+
+	usernames, passwords := strings.Split(username, ","), strings.Split(password, ",")
+	if len(usernames) != len(passwords) {
+		log.Fatal("ADMIN_USERNAME and ADMIN_PASSWORD must have the same number of entries")
+	}
+
+	for i := range usernames {
+		if err := userUC.EnsureAdmin(context.Background(), usernames[i], passwords[i]); err != nil {
+			log.Fatalf("failed to create admin user: %v", err)
+		}
+	}
+}
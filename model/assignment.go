@@ -0,0 +1,23 @@
+package model
+
+// Assignment strategies pick the checker a new pending message is assigned to
+const (
+	// AssignmentNone leaves messages unassigned, every eligible checker sees them in their queue
+	AssignmentNone = "none"
+	// AssignmentRoundRobin takes turns among the eligible checkers
+	AssignmentRoundRobin = "round_robin"
+	// AssignmentLeastLoaded picks the eligible checker with the fewest pending assigned messages
+	AssignmentLeastLoaded = "least_loaded"
+	// AssignmentSenderTeam picks the least loaded eligible checker sharing a group with the sender
+	AssignmentSenderTeam = "sender_team"
+)
+
+// IsValidAssignmentStrategy reports whether the given assignment strategy is known
+func IsValidAssignmentStrategy(strategy string) bool {
+	switch strategy {
+	case AssignmentNone, AssignmentRoundRobin, AssignmentLeastLoaded, AssignmentSenderTeam:
+		return true
+	}
+
+	return false
+}
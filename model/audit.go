@@ -0,0 +1,128 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SystemActorID is the actor of changes that are not made by a logged in user
+const SystemActorID = "system"
+
+// Audited actions
+const (
+	AuditActionMessageCreate   = "message.create"
+	AuditActionMessageEdit     = "message.edit"
+	AuditActionMessageSubmit   = "message.submit"
+	AuditActionMessageVote     = "message.vote"
+	AuditActionMessageResubmit = "message.resubmit"
+	AuditActionMessageAssign   = "message.assign"
+	AuditActionMessageEscalate = "message.escalate"
+	AuditActionMessageExpire   = "message.expire"
+	AuditActionMessageWithdraw = "message.withdraw"
+	AuditActionMessageRecall   = "message.recall"
+	AuditActionMessageDeliver  = "message.deliver"
+
+	// AuditActionMessageAutoApprove is the system decision of a message accepted by an auto-approval rule
+	AuditActionMessageAutoApprove = "message.auto_approve"
+
+	AuditActionUserCreate      = "user.create"
+	AuditActionUserUpdate      = "user.update"
+	AuditActionUserDelete      = "user.delete"
+	AuditActionUserLogin       = "user.login"
+	AuditActionUserLoginFailed = "user.login_failed"
+
+	AuditActionDelegationCreate = "delegation.create"
+	AuditActionDelegationRevoke = "delegation.revoke"
+
+	AuditActionRoutingRuleCreate  = "routing_rule.create"
+	AuditActionRoutingRuleUpdate  = "routing_rule.update"
+	AuditActionRoutingRuleDisable = "routing_rule.disable"
+	AuditActionRoutingRuleEnable  = "routing_rule.enable"
+
+	AuditActionChangeRequestSubmit  = "change_request.submit"
+	AuditActionChangeRequestApprove = "change_request.approve"
+	AuditActionChangeRequestReject  = "change_request.reject"
+	AuditActionChangeRequestFail    = "change_request.fail"
+)
+
+// Audited resource types
+const (
+	AuditResourceMessage = "message"
+	AuditResourceUser    = "user"
+
+	AuditResourceDelegation = "delegation"
+
+	AuditResourceRoutingRule = "routing_rule"
+
+	AuditResourceChangeRequest = "change_request"
+)
+
+// AuditEvent describes a change to be appended to the audit trail
+type AuditEvent struct {
+	Before any
+	After  any
+	// ActorID defaults to the logged in user
+	ActorID string
+	// OnBehalfOfID is the user the actor acted for through a delegation, if any
+	OnBehalfOfID string
+	Action       string
+	ResourceType string
+	ResourceID   string
+}
+
+// AuditRecord is an immutable entry of the audit trail. Each record holds the hash of the previous one,
+// so changing or removing a record breaks the chain from that point on.
+type AuditRecord struct {
+	CreatedAt    time.Time       `json:"created_at"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	ID           string          `json:"id"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	ActorID      string          `json:"actor_id"`
+	OnBehalfOfID string          `json:"on_behalf_of_id,omitempty"`
+	RequestID    string          `json:"request_id"`
+	ClientIP     string          `json:"client_ip"`
+	PrevHash     string          `json:"prev_hash"`
+	Hash         string          `json:"hash"`
+	Sequence     int64           `json:"sequence"`
+}
+
+// AuditVerification is the result of checking the audit trail hash chain
+type AuditVerification struct {
+	// BrokenAt is the first record that does not match the chain, it is empty if the chain is intact
+	BrokenAt *AuditRecord `json:"broken_at,omitempty"`
+	Reason   string       `json:"reason,omitempty"`
+	Checked  int64        `json:"checked"`
+	Valid    bool         `json:"valid"`
+}
+
+// ComputeHash returns the SHA-256 of the record content chained to the previous record hash
+func (rc *AuditRecord) ComputeHash() string {
+	fields := []string{
+		rc.PrevHash,
+		strconv.FormatInt(rc.Sequence, 10),
+		rc.CreatedAt.UTC().Format(time.RFC3339Nano),
+		rc.Action,
+		rc.ResourceType,
+		rc.ResourceID,
+		rc.ActorID,
+		rc.RequestID,
+		rc.ClientIP,
+		string(rc.Before),
+		string(rc.After),
+	}
+	// added after the chain was introduced, so that the hashes of older records stay the same
+	if rc.OnBehalfOfID != "" {
+		fields = append(fields, rc.OnBehalfOfID)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+
+	return hex.EncodeToString(sum[:])
+}
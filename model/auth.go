@@ -21,6 +21,7 @@ type Register struct {
 type TokenOwner struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     string `json:"role"`
 	ID       string `json:"id"`
 }
 
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Change request statuses
+const (
+	ChangeRequestStatusPending  = "pending"
+	ChangeRequestStatusApproved = "approved"
+	ChangeRequestStatusRejected = "rejected"
+	ChangeRequestStatusExpired  = "expired"
+	// ChangeRequestStatusFailed is an approved change that could not be applied
+	ChangeRequestStatusFailed = "failed"
+)
+
+// Resource types with change requests
+const (
+	ChangeResourceUser    = "user"
+	ChangeResourceMessage = "message"
+)
+
+// Change request operations
+const (
+	ChangeOperationCreate = "create"
+	ChangeOperationUpdate = "update"
+	ChangeOperationDelete = "delete"
+	// ChangeOperationRecall takes back an accepted message
+	ChangeOperationRecall = "recall"
+)
+
+// ChangeRequest is a proposed change to any resource that only takes effect once it is approved
+type ChangeRequest struct {
+	RequestedAt time.Time `json:"requested_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	DecidedAt   time.Time `json:"decided_at"`
+	// Payload is the proposed change, its shape depends on the resource type and operation
+	Payload      json.RawMessage  `json:"payload"`
+	Approvals    []ChangeApproval `json:"approvals"`
+	ID           string           `json:"id"`
+	ResourceType string           `json:"resource_type"`
+	ResourceID   string           `json:"resource_id"`
+	Operation    string           `json:"operation"`
+	RequestedBy  string           `json:"requested_by"`
+	DecidedBy    string           `json:"decided_by"`
+	Comment      string           `json:"comment"`
+	// Error explains why an approved change could not be applied
+	Error             string `json:"error,omitempty"`
+	Status            string `json:"status"`
+	RequiredApprovals int    `json:"required_approvals"`
+}
+
+// ChangeApproval is an approver's sign-off on a change request
+type ChangeApproval struct {
+	ApprovedAt time.Time `json:"approved_at"`
+	ApproverID string    `json:"approver_id"`
+	Comment    string    `json:"comment"`
+}
+
+// ChangeRequestPolicy is how change requests of a resource type are approved
+type ChangeRequestPolicy struct {
+	ApproverRoles     []string      `json:"approver_roles" yaml:"approver_roles"`
+	RequiredApprovals int           `json:"required_approvals" yaml:"required_approvals"`
+	TTL               time.Duration `json:"ttl" yaml:"ttl"`
+}
+
+// ChangeRequestPolicies maps resource types to their approval policy
+type ChangeRequestPolicies map[string]ChangeRequestPolicy
+
+type ChangeRequestDecision struct {
+	Comment string `json:"comment"`
+}
+
+type ChangeRequestFindOpts struct {
+	PaginationOpts
+	ResourceType Filter
+	ResourceID   Filter
+	Status       Filter
+	// ResourceTypes limits the change requests to the resource types the caller can decide on
+	ResourceTypes []string
+}
+
+// HasApproved reports whether the user already approved the change request
+func (rc *ChangeRequest) HasApproved(userID string) bool {
+	for _, v := range rc.Approvals {
+		if v.ApproverID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// For returns the policy of the resource type, or the default policy: one admin approval within three days
+func (rc ChangeRequestPolicies) For(resourceType string) ChangeRequestPolicy {
+	policy := rc[resourceType]
+	if len(policy.ApproverRoles) == 0 {
+		policy.ApproverRoles = []string{RoleAdmin}
+	}
+	if policy.RequiredApprovals <= 0 {
+		policy.RequiredApprovals = 1
+	}
+	if policy.TTL <= 0 {
+		policy.TTL = 72 * time.Hour
+	}
+
+	return policy
+}
+
+// Validate checks that every policy names known approver roles and a non-negative number of approvals
+func (rc ChangeRequestPolicies) Validate() error {
+	for resourceType, policy := range rc {
+		for _, role := range policy.ApproverRoles {
+			if !IsValidRole(role) {
+				return fmt.Errorf("change request policy %q: unknown approver role %q", resourceType, role)
+			}
+		}
+
+		if policy.RequiredApprovals < 0 {
+			return fmt.Errorf("change request policy %q: required approvals can't be negative", resourceType)
+		}
+
+		if policy.TTL < 0 {
+			return fmt.Errorf("change request policy %q: ttl can't be negative", resourceType)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package model
+
+import "fmt"
+
+// ConflictReasonVersion is the machine-readable reason returned for a VersionConflictError
+const ConflictReasonVersion = "version_conflict"
+
+// Machine-readable reasons of a segregation of duties violation, all of them are refused with 403
+const (
+	ConflictReasonSender   = "checker_is_sender"
+	ConflictReasonReceiver = "checker_is_receiver"
+	ConflictReasonRelated  = "checker_is_related"
+)
+
+// VersionConflictError is returned by a conditional write when the resource is no longer at the version
+// or in the state the caller read it in, because another request changed it meanwhile
+type VersionConflictError struct {
+	Resource string
+	ID       string
+	Version  int
+}
+
+func (rc *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %s was changed by another request, expected version %d", rc.Resource, rc.ID, rc.Version)
+}
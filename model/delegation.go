@@ -0,0 +1,41 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Delegation lets the delegate decide on messages on behalf of a checker who is out of office, between StartsAt and EndsAt
+type Delegation struct {
+	CreatedAt time.Time `json:"created_at"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	// RevokedAt ends the delegation early, it is zero while the delegation is not revoked
+	RevokedAt   time.Time `json:"revoked_at"`
+	ID          string    `json:"id"`
+	DelegatorID string    `json:"delegator_id"`
+	DelegateID  string    `json:"delegate_id"`
+	// MessageTypes limits the delegation to these message types, an empty list covers every message type
+	MessageTypes []string `json:"message_types"`
+}
+
+type DelegationCreateRequest struct {
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	DelegateID   string    `json:"delegate_id"`
+	MessageTypes []string  `json:"message_types"`
+}
+
+// IsActive reports whether the delegation is in effect at the given time
+func (rc *Delegation) IsActive(now time.Time) bool {
+	return rc.RevokedAt.IsZero() && !now.Before(rc.StartsAt) && now.Before(rc.EndsAt)
+}
+
+// Covers reports whether the delegation applies to messages of the given type
+func (rc *Delegation) Covers(messageType string) bool {
+	if messageType == "" {
+		messageType = DefaultMessageType
+	}
+
+	return len(rc.MessageTypes) == 0 || slices.Contains(rc.MessageTypes, messageType)
+}
@@ -1,30 +1,174 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
 
+// Message statuses, each workflow allows a subset of the transitions between them, see message_status.go and workflow.go
 const (
-	MessageStatusPending  = 1
-	MessageStatusAccepted = 2
-	MessageStatusRejected = 3
+	MessageStatusPending   = 1
+	MessageStatusAccepted  = 2
+	MessageStatusRejected  = 3
+	MessageStatusWithdrawn = 4
+	MessageStatusExpired   = 5
+	MessageStatusRecalled  = 6
+	MessageStatusDraft     = 7
+	// MessageStatusChangesRequested sends the message back to the sender to edit and resubmit it
+	MessageStatusChangesRequested = 8
 )
 
+// DefaultMessageType is the message type used when a message is created without one
+const DefaultMessageType = "default"
+
+// RecalledMessageText replaces the text of a recalled message for its receiver
+const RecalledMessageText = "This message was recalled by its sender."
+
 type Message struct {
 	CreatedAt  time.Time `json:"created_at"`
 	DeletedAt  time.Time `json:"deleted_at"`
-	ID         string    `json:"id"`
-	SenderID   string    `json:"sender_id"`
-	ReceiverID string    `json:"receiver_id"`
-	Text       string    `json:"text"`
-	Status     int       `json:"status"`
+	RecalledAt time.Time `json:"recalled_at,omitempty"`
+	// DeliverAt is the scheduled delivery time, the message is delivered right away without it
+	DeliverAt   time.Time `json:"deliver_at,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at,omitempty"`
+	ID          string    `json:"id"`
+	SenderID    string    `json:"sender_id"`
+	ReceiverID  string    `json:"receiver_id"`
+	Text        string    `json:"text"`
+	Type        string    `json:"type"`
+	Status      int       `json:"status"`
+	// Revision is the number of the current text revision, starting at 1
+	Revision int `json:"revision"`
+	// Version is incremented on every change, a write based on an older version is refused
+	Version int `json:"version"`
+	// Steps is the ordered approval chain, CurrentStep is the index of the step waiting for a decision
+	Steps       []ApprovalStep `json:"steps"`
+	CurrentStep int            `json:"current_step"`
+	// AssigneeID is the checker the current approval step is assigned to, if any
+	AssigneeID string `json:"assignee_id"`
+	// Claim is the review lease of a checker, it is ignored once it expired
+	Claim *ReviewClaim `json:"claim,omitempty"`
+	// SLA holds the deadlines of the message, it is empty for message types without an SLA policy
+	SLA *MessageSLA `json:"sla,omitempty"`
+	// Escalations are the reminders, breaches and expiries recorded by the SLA scheduler
+	Escalations []Escalation `json:"escalations"`
+	// AllowedTransitions are the next statuses the message can move to, it is not stored
+	AllowedTransitions []MessageTransition `json:"allowed_transitions"`
+	// Policy is the auto-approval rule that decided how the message is reviewed, it is empty if no rule matched
+	Policy *PolicyDecision `json:"policy,omitempty"`
+	// Workflow is the workflow definition the message follows, messages without one follow the default workflow
+	Workflow *WorkflowRef `json:"workflow,omitempty"`
+	// Delivered is false while a scheduled message waits for its delivery time, the receiver doesn't see it until then
+	Delivered bool `json:"delivered"`
+	// Tombstone marks a recalled message whose content is hidden from the caller, it is not stored
+	Tombstone bool `json:"tombstone,omitempty"`
+}
+
+// ApprovalStep is one level of an approval chain, it can be decided by checkers with the given role and/or group.
+// The step needs Quorum approving votes, and Checkers optionally restricts who may vote.
+type ApprovalStep struct {
+	Votes    []ApprovalDecision `json:"votes" yaml:"-"`
+	Checkers []string           `json:"checkers,omitempty" yaml:"checkers"`
+	Name     string             `json:"name" yaml:"name"`
+	Role     string             `json:"role,omitempty" yaml:"role"`
+	Group    string             `json:"group,omitempty" yaml:"group"`
+	Quorum   int                `json:"quorum" yaml:"quorum"`
+	// Rejections is the number of rejecting votes that reject a step without a Checkers list, 1 by default. A step
+	// with a Checkers list is rejected once its listed checkers can no longer reach the quorum.
+	Rejections int `json:"rejections,omitempty" yaml:"rejections"`
+	// EscalatedTo is the fallback checker group that may decide on the step as well, once its SLA was breached
+	EscalatedTo string `json:"escalated_to,omitempty" yaml:"-"`
+}
+
+// ApprovalDecision is a checker's vote on an approval step, a rejection always carries a reason code
+type ApprovalDecision struct {
+	DecidedAt time.Time `json:"decided_at"`
+	// CheckerID is the checker the vote counts for
+	CheckerID string `json:"checker_id"`
+	// DelegateID is the user who cast the vote on behalf of the checker, it is empty if the checker voted themselves
+	DelegateID string `json:"delegate_id,omitempty"`
+	ReasonCode string `json:"reason_code,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	// RuleID is the auto-approval rule behind a system decision, CheckerID is "system" then
+	RuleID string `json:"rule_id,omitempty"`
+	Status int    `json:"status"`
+}
+
+// RejectionReason is an entry of the catalog of reasons a checker can reject a message for
+type RejectionReason struct {
+	Code        string `json:"code" yaml:"code"`
+	Description string `json:"description" yaml:"description"`
 }
 
+// RejectionReasons is the configured catalog of rejection reasons
+type RejectionReasons []RejectionReason
+
+// ApprovalChains maps message types to their approval chains
+type ApprovalChains map[string][]ApprovalStep
+
 type MessageCreateRequest struct {
-	ReceiverID string `json:"receiver_id"`
-	Text       string `json:"text"`
+	// DeliverAt schedules the delivery, the receiver only sees the accepted message from then on. It is optional
+	// and must be in the future.
+	DeliverAt  *time.Time `json:"deliver_at,omitempty"`
+	ReceiverID string     `json:"receiver_id"`
+	Text       string     `json:"text"`
+	Type       string     `json:"type"`
+	// Draft saves the message without submitting it for review, the receiver and text may still be incomplete
+	Draft bool `json:"draft"`
+}
+
+// MessageEditRequest replaces the content of a draft
+type MessageEditRequest struct {
+	DeliverAt  *time.Time `json:"deliver_at,omitempty"`
+	ReceiverID string     `json:"receiver_id"`
+	Text       string     `json:"text"`
+	Type       string     `json:"type"`
+	// Version is the draft version the sender edited, the edit is refused if the draft changed since.
+	// It is optional, PUT /messages/:id also takes it from the If-Match header.
+	Version int `json:"version,omitempty"`
+}
+
+type MessageSubmitRequest struct {
+	// Version is the draft version the sender submits, the submission is refused if the draft changed since.
+	// It is optional, POST /messages/:id/submit also takes it from the If-Match header.
+	Version int `json:"version,omitempty"`
 }
 
 type MessageUpdateRequest struct {
-	Status int `json:"status"`
+	// ReasonCode is required when rejecting and must be in the rejection reasons catalog
+	ReasonCode string `json:"reason_code"`
+	Comment    string `json:"comment"`
+	Status     int    `json:"status"`
+	// Version is the message version the checker decided on, the decision is refused if the message changed since.
+	// It is optional, PATCH /messages/:id also takes it from the If-Match header.
+	Version int `json:"version,omitempty"`
+	// OnBehalfOf is the checker the caller decides for, through an active delegation of that checker
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+}
+
+type MessageWithdrawRequest struct {
+	// Version is the message version the sender withdraws, the withdrawal is refused if the message changed since.
+	// It is optional, POST /messages/:id/withdraw also takes it from the If-Match header.
+	Version int `json:"version,omitempty"`
+}
+
+type MessageRecallRequest struct {
+	// Reason tells the checkers why the accepted message should be recalled
+	Reason string `json:"reason"`
+}
+
+type MessageResubmitRequest struct {
+	Text string `json:"text"`
+	// Version is the message version the sender resubmits, the resubmission is refused if the message changed since.
+	// It is optional, POST /messages/:id/resubmit also takes it from the If-Match header.
+	Version int `json:"version,omitempty"`
+}
+
+// MessageAssignRequest reassigns the current approval step of a message to another checker
+type MessageAssignRequest struct {
+	CheckerID string `json:"checker_id"`
 }
 
 type MessageFindOpts struct {
@@ -33,3 +177,220 @@ type MessageFindOpts struct {
 	SenderID   Filter
 	Status     Filter
 }
+
+// IsEligible reports whether the user may decide on the step
+func (rc *ApprovalStep) IsEligible(user *User) bool {
+	if rc.EscalatedTo != "" && user.InGroup(rc.EscalatedTo) {
+		return true
+	}
+
+	if len(rc.Checkers) > 0 && !slices.Contains(rc.Checkers, user.ID) {
+		return false
+	}
+
+	if rc.Role != "" && rc.Role != user.Role {
+		return false
+	}
+
+	if rc.Group != "" && !user.InGroup(rc.Group) {
+		return false
+	}
+
+	return true
+}
+
+// HasVoted reports whether the checker already voted on the step, themselves or through a delegate,
+// or cast a vote on behalf of another checker
+func (rc *ApprovalStep) HasVoted(checkerID string) bool {
+	return slices.ContainsFunc(rc.Votes, func(v ApprovalDecision) bool {
+		return v.CheckerID == checkerID || v.DelegateID == checkerID
+	})
+}
+
+// ChangesRequest returns the vote that requested changes on the step, if any
+func (rc *ApprovalStep) ChangesRequest() *ApprovalDecision {
+	for i := range rc.Votes {
+		if rc.Votes[i].Status == MessageStatusChangesRequested {
+			return &rc.Votes[i]
+		}
+	}
+
+	return nil
+}
+
+// RequiredApprovals returns the number of approving votes the step needs
+func (rc *ApprovalStep) RequiredApprovals() int {
+	return max(rc.Quorum, 1)
+}
+
+// RequiredRejections returns the number of rejecting votes that reject a step without a checkers list
+func (rc *ApprovalStep) RequiredRejections() int {
+	return max(rc.Rejections, 1)
+}
+
+// Outcome tallies the votes of the step. The step is accepted once the quorum is reached. With a checkers list it is
+// rejected once the listed checkers can no longer reach the quorum, rejections of voters outside the list, such as
+// the escalation group, don't count towards that. Without a checkers list the pool is open and can't run out, so
+// the step is rejected by RequiredRejections rejecting votes. A single changes requested vote sends the message back
+// to the sender.
+func (rc *ApprovalStep) Outcome() int {
+	var approvals, rejections, listedRejections int
+	for _, v := range rc.Votes {
+		switch v.Status {
+		case MessageStatusAccepted:
+			approvals++
+		case MessageStatusRejected:
+			rejections++
+			if slices.Contains(rc.Checkers, v.CheckerID) {
+				listedRejections++
+			}
+		case MessageStatusChangesRequested:
+			return MessageStatusChangesRequested
+		}
+	}
+
+	if approvals >= rc.RequiredApprovals() {
+		return MessageStatusAccepted
+	}
+
+	if len(rc.Checkers) == 0 && rejections >= rc.RequiredRejections() {
+		return MessageStatusRejected
+	}
+
+	if len(rc.Checkers) > 0 && len(rc.Checkers)-listedRejections < rc.RequiredApprovals() {
+		return MessageStatusRejected
+	}
+
+	return MessageStatusPending
+}
+
+// For returns a fresh copy of the approval chain of the given message type
+func (rc ApprovalChains) For(messageType string) ([]ApprovalStep, bool) {
+	if messageType == "" {
+		messageType = DefaultMessageType
+	}
+
+	chain, ok := rc[messageType]
+	if !ok {
+		return nil, false
+	}
+
+	steps := make([]ApprovalStep, len(chain))
+	copy(steps, chain)
+
+	return steps, true
+}
+
+// DefaultApprovalChains is a single step that any checker can decide
+func DefaultApprovalChains() ApprovalChains {
+	return ApprovalChains{
+		DefaultMessageType: {
+			{Name: "review", Role: RoleChecker},
+		},
+	}
+}
+
+// Validate checks that every chain has at least one step and that each step can be decided by someone
+func (rc ApprovalChains) Validate() error {
+	for messageType, chain := range rc {
+		if err := validateSteps(chain); err != nil {
+			return fmt.Errorf("approval chain %q: %w", messageType, err)
+		}
+	}
+
+	return nil
+}
+
+// validateSteps checks that the chain has at least one step and that each step can be decided by someone
+func validateSteps(chain []ApprovalStep) error {
+	if len(chain) == 0 {
+		return errors.New("no steps")
+	}
+
+	for i, step := range chain {
+		if step.Name == "" {
+			return fmt.Errorf("step %d has no name", i)
+		}
+		if step.Role == "" && step.Group == "" {
+			return fmt.Errorf("step %q needs a role or a group", step.Name)
+		}
+		if step.Role != "" && !IsValidRole(step.Role) {
+			return fmt.Errorf("step %q has invalid role %q", step.Name, step.Role)
+		}
+		if step.Quorum < 0 {
+			return fmt.Errorf("step %q has negative quorum", step.Name)
+		}
+		if step.Rejections < 0 {
+			return fmt.Errorf("step %q has negative rejections", step.Name)
+		}
+		if step.Rejections > 0 && len(step.Checkers) > 0 {
+			return fmt.Errorf("step %q: rejections only apply to steps without checkers", step.Name)
+		}
+		if len(step.Checkers) > 0 && step.RequiredApprovals() > len(step.Checkers) {
+			return fmt.Errorf("step %q needs %d approvals from only %d checkers", step.Name, step.RequiredApprovals(), len(step.Checkers))
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether the code is in the catalog
+func (rc RejectionReasons) Contains(code string) bool {
+	return slices.ContainsFunc(rc, func(v RejectionReason) bool {
+		return v.Code == code
+	})
+}
+
+// Validate checks that every reason has a unique code
+func (rc RejectionReasons) Validate() error {
+	seen := make(map[string]bool, len(rc))
+	for _, v := range rc {
+		if v.Code == "" {
+			return fmt.Errorf("rejection reason %q has no code", v.Description)
+		}
+		if seen[v.Code] {
+			return fmt.Errorf("duplicate rejection reason code %q", v.Code)
+		}
+		seen[v.Code] = true
+	}
+
+	return nil
+}
+
+// DefaultRejectionReasons is the catalog used when none is configured
+func DefaultRejectionReasons() RejectionReasons {
+	return RejectionReasons{
+		{Code: "incorrect_content", Description: "The text contains incorrect information"},
+		{Code: "wrong_receiver", Description: "The message is addressed to the wrong receiver"},
+		{Code: "policy_violation", Description: "The message violates company policy"},
+		{Code: "other", Description: "Another reason, explained in the comment"},
+	}
+}
+
+// IsVisibleToReceiver reports whether the receiver may see the message: once it is accepted and delivered, and
+// as a tombstone after it was recalled
+func (rc *Message) IsVisibleToReceiver() bool {
+	return (rc.Status == MessageStatusAccepted || rc.Status == MessageStatusRecalled) && rc.Delivered
+}
+
+// ToTombstone returns what the receiver sees of a recalled message: that it was recalled, without its content
+func (rc *Message) ToTombstone() *Message {
+	return &Message{
+		CreatedAt:          rc.CreatedAt,
+		RecalledAt:         rc.RecalledAt,
+		DeliverAt:          rc.DeliverAt,
+		DeliveredAt:        rc.DeliveredAt,
+		ID:                 rc.ID,
+		SenderID:           rc.SenderID,
+		ReceiverID:         rc.ReceiverID,
+		Text:               RecalledMessageText,
+		Type:               rc.Type,
+		Status:             rc.Status,
+		Version:            rc.Version,
+		Steps:              []ApprovalStep{},
+		Escalations:        []Escalation{},
+		AllowedTransitions: []MessageTransition{},
+		Delivered:          rc.Delivered,
+		Tombstone:          true,
+	}
+}
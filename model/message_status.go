@@ -0,0 +1,110 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Actors that may move a message from one status to another
+const (
+	ActorSender  = "sender"
+	ActorChecker = "checker"
+	ActorSystem  = "system"
+)
+
+// Machine-readable reasons of a rejected transition
+const (
+	TransitionReasonUnknownStatus   = "unknown_status"
+	TransitionReasonNotAllowed      = "transition_not_allowed"
+	TransitionReasonActorNotAllowed = "actor_not_allowed"
+)
+
+// MessageTransition is an allowed move between two statuses and the actor who may perform it
+type MessageTransition struct {
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Actor string `json:"actor"`
+}
+
+// TransitionError explains why a transition is not allowed
+type TransitionError struct {
+	Reason string
+	Actor  string
+	From   int
+	To     int
+}
+
+var messageStatusNames = map[int]string{
+	MessageStatusDraft:     "draft",
+	MessageStatusPending:   "pending",
+	MessageStatusAccepted:  "accepted",
+	MessageStatusRejected:  "rejected",
+	MessageStatusWithdrawn: "withdrawn",
+	MessageStatusExpired:   "expired",
+	MessageStatusRecalled:  "recalled",
+
+	MessageStatusChangesRequested: "changes_requested",
+}
+
+// messageTransitions make up the state machine of the default workflow, a workflow definition has its own, see
+// workflow.go
+var messageTransitions = []MessageTransition{
+	{From: MessageStatusDraft, To: MessageStatusPending, Actor: ActorSender},
+	{From: MessageStatusDraft, To: MessageStatusWithdrawn, Actor: ActorSender},
+	{From: MessageStatusPending, To: MessageStatusAccepted, Actor: ActorChecker},
+	{From: MessageStatusPending, To: MessageStatusAccepted, Actor: ActorSystem},
+	{From: MessageStatusPending, To: MessageStatusRejected, Actor: ActorChecker},
+	{From: MessageStatusPending, To: MessageStatusChangesRequested, Actor: ActorChecker},
+	{From: MessageStatusPending, To: MessageStatusWithdrawn, Actor: ActorSender},
+	{From: MessageStatusPending, To: MessageStatusExpired, Actor: ActorSystem},
+	{From: MessageStatusChangesRequested, To: MessageStatusPending, Actor: ActorSender},
+	{From: MessageStatusChangesRequested, To: MessageStatusWithdrawn, Actor: ActorSender},
+	{From: MessageStatusAccepted, To: MessageStatusRecalled, Actor: ActorChecker},
+}
+
+func (rc *TransitionError) Error() string {
+	return fmt.Sprintf("%s: %s cannot move message from %s to %s", rc.Reason, rc.Actor, StatusName(rc.From), StatusName(rc.To))
+}
+
+// StatusName returns the name of the message status
+func StatusName(status int) string {
+	name, ok := messageStatusNames[status]
+	if !ok {
+		return fmt.Sprintf("unknown(%d)", status)
+	}
+
+	return name
+}
+
+// IsValidStatus reports whether the status is part of the state machine
+func IsValidStatus(status int) bool {
+	_, ok := messageStatusNames[status]
+	return ok
+}
+
+// StatusNames returns the names of the built-in message statuses, sorted
+func StatusNames() []string {
+	names := make([]string, 0, len(messageStatusNames))
+	for _, v := range messageStatusNames {
+		names = append(names, v)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// StatusByName returns the message status of the given name
+func StatusByName(name string) (int, bool) {
+	for status, v := range messageStatusNames {
+		if v == name {
+			return status, true
+		}
+	}
+
+	return 0, false
+}
+
+// IsValidActor reports whether the actor may take part in a transition
+func IsValidActor(actor string) bool {
+	return actor == ActorSender || actor == ActorChecker || actor == ActorSystem
+}
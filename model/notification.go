@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Notification kinds
+const (
+	NotificationSLAReminder = "sla.reminder"
+	NotificationSLABreach   = "sla.breach"
+	NotificationSLAExpiry   = "sla.expiry"
+	// NotificationDelivery tells the receiver about a scheduled message that was delivered
+	NotificationDelivery = "message.delivery"
+	// NotificationWorkflowState is sent by the hooks of a workflow when a message enters a state
+	NotificationWorkflowState = "workflow.state"
+)
+
+// Notification is sent to users about a message that needs their attention
+type Notification struct {
+	CreatedAt    time.Time `json:"created_at"`
+	MessageID    string    `json:"message_id"`
+	Kind         string    `json:"kind"`
+	RecipientIDs []string  `json:"recipient_ids"`
+	Text         string    `json:"text"`
+}
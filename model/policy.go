@@ -0,0 +1,195 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sources of the rules of the approval policy
+const (
+	PolicySourceRoutingRule = "routing_rule"
+	PolicySourceConfig      = "auto_approval"
+)
+
+// Auto-approval actions, they decide how a new message is reviewed
+const (
+	// PolicyActionAccept accepts the message right away as a system decision
+	PolicyActionAccept = "accept"
+	// PolicyActionChecker sends the message through its approval chain as usual
+	PolicyActionChecker = "checker"
+	// PolicyActionSenior restricts the approval steps of the message without a group of their own to the senior checker group
+	PolicyActionSenior = "senior_checker"
+)
+
+// AutoApprovalPolicy is the ordered list of auto-approval rules, the first rule that matches a new message decides
+// how it is reviewed. Messages that match no rule go through their approval chain as usual.
+type AutoApprovalPolicy struct {
+	// SeniorGroup is the checker group senior_checker rules route messages to
+	SeniorGroup string             `yaml:"senior_group"`
+	Rules       []AutoApprovalRule `yaml:"rules"`
+}
+
+// AutoApprovalRule matches new messages on their sender, receiver, type and text. Every condition that is set
+// must hold, a rule without conditions matches every message.
+type AutoApprovalRule struct {
+	ID     string `yaml:"id"`
+	Action string `yaml:"action"`
+	// SenderIDs and SenderGroups match the sender by id or by one of their groups
+	SenderIDs    []string `yaml:"sender_ids"`
+	SenderGroups []string `yaml:"sender_groups"`
+	// ReceiverIDs and ReceiverGroups match the receiver by id or by one of their groups
+	ReceiverIDs    []string `yaml:"receiver_ids"`
+	ReceiverGroups []string `yaml:"receiver_groups"`
+	MessageTypes   []string `yaml:"message_types"`
+	// MinTextLength and MaxTextLength bound the text length in characters, zero means no bound
+	MinTextLength int `yaml:"min_text_length"`
+	MaxTextLength int `yaml:"max_text_length"`
+	// TextContains matches texts that contain any of the phrases, TextExcludes texts that contain none of them.
+	// Both ignore case.
+	TextContains []string `yaml:"text_contains"`
+	TextExcludes []string `yaml:"text_excludes"`
+}
+
+// PolicyDecision is how the auto-approval policy routes a message, and the rule that decided it
+type PolicyDecision struct {
+	RuleID string `json:"rule_id"`
+	// RuleVersion is the version of the routing rule that matched, it is empty for configured rules
+	RuleVersion int    `json:"rule_version,omitempty"`
+	Action      string `json:"action"`
+	// Group is the checker group the approval steps without a group of their own are routed to
+	Group string `json:"group,omitempty"`
+	// Skipped is set on an accept decision that the workflow of the message doesn't allow, the message is reviewed as usual
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// PolicyMatch is a rule that matches a message, with where the rule is defined
+type PolicyMatch struct {
+	PolicyDecision
+	// Source is routing_rule for the rules managed through the API, or auto_approval for the configured ones
+	Source string `json:"source"`
+	Name   string `json:"name,omitempty"`
+	// Expression is the condition of a routing rule
+	Expression string `json:"expression,omitempty"`
+}
+
+// IsValidPolicyAction reports whether the action is one of the known auto-approval actions
+func IsValidPolicyAction(action string) bool {
+	switch action {
+	case PolicyActionAccept, PolicyActionChecker, PolicyActionSenior:
+		return true
+	}
+
+	return false
+}
+
+// Validate checks that every rule has a unique id, a known action and consistent text bounds
+func (rc *AutoApprovalPolicy) Validate() error {
+	ids := make(map[string]bool, len(rc.Rules))
+	for i, rule := range rc.Rules {
+		if rule.ID == "" {
+			return fmt.Errorf("auto approval rule %d: id is required", i)
+		}
+		if ids[rule.ID] {
+			return fmt.Errorf("auto approval rule %q: id is used more than once", rule.ID)
+		}
+		ids[rule.ID] = true
+
+		if !IsValidPolicyAction(rule.Action) {
+			return fmt.Errorf("auto approval rule %q: unknown action %q", rule.ID, rule.Action)
+		}
+		if rule.Action == PolicyActionSenior && rc.SeniorGroup == "" {
+			return fmt.Errorf("auto approval rule %q: senior_group is required for %s rules", rule.ID, PolicyActionSenior)
+		}
+
+		if rule.MinTextLength < 0 || rule.MaxTextLength < 0 {
+			return fmt.Errorf("auto approval rule %q: text lengths can't be negative", rule.ID)
+		}
+		if rule.MaxTextLength != 0 && rule.MinTextLength > rule.MaxTextLength {
+			return fmt.Errorf("auto approval rule %q: min_text_length is above max_text_length", rule.ID)
+		}
+	}
+
+	return nil
+}
+
+// ValidateWorkflows refuses the accept rules that can never apply, because the latest workflows of all their message
+// types don't let the system accept a message
+func (rc *AutoApprovalPolicy) ValidateWorkflows(workflows Workflows) error {
+	for _, rule := range rc.Rules {
+		if rule.Action != PolicyActionAccept || len(rule.MessageTypes) == 0 {
+			continue
+		}
+
+		if !slices.ContainsFunc(rule.MessageTypes, workflows.AutoAccepts) {
+			return fmt.Errorf("auto approval rule %q: the workflows of its message types don't let the system accept a message", rule.ID)
+		}
+	}
+
+	return nil
+}
+
+// Matches lists the rules matching the message in the order they are evaluated, the first one decides
+func (rc *AutoApprovalPolicy) Matches(sender, receiver *User, message *Message) []PolicyMatch {
+	var matches []PolicyMatch
+	for _, rule := range rc.Rules {
+		if !rule.Matches(sender, receiver, message) {
+			continue
+		}
+
+		match := PolicyMatch{
+			PolicyDecision: PolicyDecision{RuleID: rule.ID, Action: rule.Action},
+			Source:         PolicySourceConfig,
+		}
+		if rule.Action == PolicyActionSenior {
+			match.Group = rc.SeniorGroup
+		}
+
+		matches = append(matches, match)
+	}
+
+	return matches
+}
+
+// Matches reports whether every condition of the rule holds for the message
+func (rc *AutoApprovalRule) Matches(sender, receiver *User, message *Message) bool {
+	if !matchesUser(sender, rc.SenderIDs, rc.SenderGroups) || !matchesUser(receiver, rc.ReceiverIDs, rc.ReceiverGroups) {
+		return false
+	}
+
+	if len(rc.MessageTypes) > 0 && !slices.Contains(rc.MessageTypes, message.Type) {
+		return false
+	}
+
+	length := utf8.RuneCountInString(message.Text)
+	if length < rc.MinTextLength || rc.MaxTextLength != 0 && length > rc.MaxTextLength {
+		return false
+	}
+
+	text := strings.ToLower(message.Text)
+	containsAny := func(phrases []string) bool {
+		return slices.ContainsFunc(phrases, func(v string) bool {
+			return strings.Contains(text, strings.ToLower(v))
+		})
+	}
+
+	if len(rc.TextContains) > 0 && !containsAny(rc.TextContains) {
+		return false
+	}
+
+	return !containsAny(rc.TextExcludes)
+}
+
+// matchesUser reports whether the user has one of the ids or is in one of the groups, no ids and groups match anybody
+func matchesUser(user *User, ids, groups []string) bool {
+	if len(ids) == 0 && len(groups) == 0 {
+		return true
+	}
+
+	if user == nil {
+		return false
+	}
+
+	return slices.Contains(ids, user.ID) || slices.ContainsFunc(groups, user.InGroup)
+}
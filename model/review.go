@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// ReviewClaim is a time-limited lease a checker takes on a pending message, so that no other checker reviews it meanwhile
+type ReviewClaim struct {
+	ClaimedAt time.Time `json:"claimed_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CheckerID string    `json:"checker_id"`
+}
+
+type ReviewQueueOpts struct {
+	PaginationOpts
+	CheckerID string
+}
+
+// IsActive reports whether the lease has not expired yet
+func (rc *ReviewClaim) IsActive(now time.Time) bool {
+	return rc != nil && rc.ExpiresAt.After(now)
+}
+
+// IsHeldByOther reports whether another checker holds an active lease
+func (rc *ReviewClaim) IsHeldByOther(checkerID string, now time.Time) bool {
+	return rc.IsActive(now) && rc.CheckerID != checkerID
+}
+
+// BulkReviewRequest is one decision on many messages, like PATCH /messages/:id on each of them
+type BulkReviewRequest struct {
+	Items []BulkReviewItem `json:"items"`
+	// ReasonCode is required when rejecting and must be in the rejection reasons catalog
+	ReasonCode string `json:"reason_code"`
+	Comment    string `json:"comment"`
+	Status     int    `json:"status"`
+	// OnBehalfOf is the checker the caller decides for, through an active delegation of that checker
+	OnBehalfOf string `json:"on_behalf_of,omitempty"`
+}
+
+// BulkReviewItem is a message of a bulk decision
+type BulkReviewItem struct {
+	MessageID string `json:"message_id"`
+	// Version is the message version the checker decided on, it is optional
+	Version int `json:"version,omitempty"`
+}
+
+// BulkReviewResult is the outcome of a bulk decision on one message
+type BulkReviewResult struct {
+	// Message is the message after the decision, it is only set on success
+	Message   *Message `json:"message,omitempty"`
+	MessageID string   `json:"message_id"`
+	Error     string   `json:"error,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+	// StatusCode is the HTTP status PATCH /messages/:id would have answered with
+	StatusCode int  `json:"status_code"`
+	Success    bool `json:"success"`
+}
+
+// VoteWrite records a vote on the current step of a message that is still at the given version, together with the
+// outcome of the step, so that a decided step never stays pending. Steps are the steps of the message with the vote
+// recorded, Status and NextStep what the message moves to. With Reassign set the message is assigned to AssigneeID,
+// or unassigned if it is empty.
+type VoteWrite struct {
+	Vote       ApprovalDecision
+	MessageID  string
+	AssigneeID string
+	Steps      []ApprovalStep
+	Version    int
+	Step       int
+	Status     int
+	NextStep   int
+	Reassign   bool
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Diff line operations
+const (
+	DiffOpEqual  = "="
+	DiffOpInsert = "+"
+	DiffOpDelete = "-"
+)
+
+// MessageRevision is a version of a message text, stored every time the sender submits the message
+type MessageRevision struct {
+	CreatedAt time.Time `json:"created_at"`
+	// ChangesRequested is the checker vote that asked for this revision, it is empty for the first one
+	ChangesRequested *ApprovalDecision `json:"changes_requested,omitempty"`
+	ID               string            `json:"id"`
+	MessageID        string            `json:"message_id"`
+	AuthorID         string            `json:"author_id"`
+	Text             string            `json:"text"`
+	// Diff compares the text line by line against the previous revision
+	Diff   []DiffLine `json:"diff"`
+	Number int        `json:"number"`
+}
+
+// DiffLine is a line of a text diff with its operation
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
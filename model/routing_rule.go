@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// DraftRoutingRuleID is the id of a routing rule that is simulated before it is stored
+const DraftRoutingRuleID = "draft"
+
+// RoutingRule routes the messages whose fields match its expression, like an auto-approval rule that is managed
+// through the API. Every change stores a new version of the rule, a disabled rule is skipped.
+type RoutingRule struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	// Expression is the condition of the rule, e.g. len(text) > 500 && "finance" in receiver.groups
+	Expression string `json:"expression"`
+	Action     string `json:"action"`
+	// Group restricts the approval steps without a group of their own to a checker group, it is required for
+	// senior_checker rules
+	Group string `json:"group,omitempty"`
+	// Priority orders the rules, the lowest one is evaluated first
+	Priority  int    `json:"priority"`
+	Version   int    `json:"version"`
+	Disabled  bool   `json:"disabled"`
+	CreatedBy string `json:"created_by"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+type RoutingRuleCreateRequest struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Action     string `json:"action" example:"accept,checker,senior_checker"`
+	Group      string `json:"group"`
+	Priority   int    `json:"priority"`
+}
+
+type RoutingRuleUpdateRequest struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Action     string `json:"action" example:"accept,checker,senior_checker"`
+	Group      string `json:"group"`
+	Priority   int    `json:"priority"`
+	// Version is the rule version the admin edited, the update is refused if the rule changed since.
+	// It is optional, PUT /routing-rules/:id also takes it from the If-Match header.
+	Version int `json:"version,omitempty"`
+}
@@ -0,0 +1,53 @@
+package model
+
+// MaxSimulatedMessages bounds the number of recent messages a draft routing rule is simulated against
+const MaxSimulatedMessages = 1000
+
+// MessageSimulation is the review a message would go through if it was submitted now, nothing of it is stored
+type MessageSimulation struct {
+	// Status is pending, or accepted if an auto-approval rule accepts the message
+	Status int             `json:"status"`
+	Type   string          `json:"type"`
+	Steps  []SimulatedStep `json:"steps"`
+	SLA    *MessageSLA     `json:"sla,omitempty"`
+	Policy *PolicyDecision `json:"policy,omitempty"`
+	// Workflow is the workflow definition the message would follow
+	Workflow *WorkflowRef `json:"workflow"`
+	// MatchedRules are the rules that match the message in the order they are evaluated, the first one decides
+	MatchedRules []PolicyMatch `json:"matched_rules"`
+}
+
+// SimulatedStep is an approval step of a simulated message, with the checkers who could decide on it
+type SimulatedStep struct {
+	ApprovalStep
+	EligibleCheckerIDs []string `json:"eligible_checker_ids"`
+}
+
+type RoutingRuleSimulateRequest struct {
+	Rule RoutingRuleCreateRequest `json:"rule"`
+	// Limit is the number of most recent submitted messages the rule is simulated against, 100 by default and at most 1000
+	Limit int `json:"limit"`
+}
+
+// RoutingRuleSimulation is the impact a draft routing rule would have on recent messages, if it was enabled
+type RoutingRuleSimulation struct {
+	Rule *RoutingRule `json:"rule"`
+	// Messages is the number of messages the rule was simulated against, Matched the number of messages it matches
+	// and Changed the number of messages it would route differently
+	Messages int                           `json:"messages"`
+	Matched  int                           `json:"matched"`
+	Changed  int                           `json:"changed"`
+	Results  []RoutingRuleSimulationResult `json:"results"`
+}
+
+// RoutingRuleSimulationResult compares the routing of a message by the current rules with its routing once the draft
+// rule is added
+type RoutingRuleSimulationResult struct {
+	MessageID string          `json:"message_id"`
+	Matched   bool            `json:"matched"`
+	Changed   bool            `json:"changed"`
+	Current   *PolicyDecision `json:"current,omitempty"`
+	Simulated *PolicyDecision `json:"simulated,omitempty"`
+	// Error is set when the message can't be routed anymore, e.g. because its receiver no longer exists
+	Error string `json:"error,omitempty"`
+}
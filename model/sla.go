@@ -0,0 +1,200 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Escalation levels recorded on a message by the SLA scheduler
+const (
+	// EscalationReminder reminds the checkers of a message that is still pending
+	EscalationReminder = "reminder"
+	// EscalationBreach hands the current step over to the fallback checker group once the deadline passed
+	EscalationBreach = "breach"
+	// EscalationExpiry expires the message
+	EscalationExpiry = "expiry"
+)
+
+// BusinessCalendar adds business time to a point in time, skipping the time outside of working hours
+type BusinessCalendar interface {
+	Add(start time.Time, d time.Duration) time.Time
+}
+
+// SLAPolicy sets the deadlines of pending messages of a message type, all durations count from the creation of the message
+type SLAPolicy struct {
+	// Deadline is when the SLA is breached and the current step is escalated to the escalation group
+	Deadline time.Duration `json:"deadline" yaml:"deadline"`
+	// Reminders are sent to the checkers at these points, they must come before the deadline
+	Reminders []time.Duration `json:"reminders" yaml:"reminders"`
+	// EscalationGroup is the fallback checker group that may decide on the current step once the deadline passed
+	EscalationGroup string `json:"escalation_group" yaml:"escalation_group"`
+	// ExpireAfter expires the message when it is still pending, it is optional and must come after the deadline
+	ExpireAfter time.Duration `json:"expire_after,omitempty" yaml:"expire_after"`
+}
+
+// SLAPolicies maps message types to their SLA policy, message types without a policy have no deadline
+type SLAPolicies map[string]SLAPolicy
+
+// MessageSLA holds the deadlines of a pending message, computed when the message is created
+type MessageSLA struct {
+	DueAt     time.Time   `json:"due_at"`
+	ExpiresAt time.Time   `json:"expires_at,omitempty"`
+	RemindAt  []time.Time `json:"remind_at"`
+	// NextCheckAt is when the scheduler looks at the message next, it is zero once nothing is left to do
+	NextCheckAt     time.Time `json:"next_check_at,omitempty"`
+	EscalationGroup string    `json:"escalation_group,omitempty"`
+	Breached        bool      `json:"breached"`
+}
+
+// Escalation is a reminder, breach or expiry recorded on a message, for reporting SLA breaches
+type Escalation struct {
+	CreatedAt time.Time `json:"created_at"`
+	// DueAt is the point of the SLA the escalation belongs to
+	DueAt      time.Time `json:"due_at"`
+	Level      string    `json:"level"`
+	Group      string    `json:"group,omitempty"`
+	AssigneeID string    `json:"assignee_id,omitempty"`
+	Step       int       `json:"step"`
+}
+
+// For returns the SLA policy of the message type
+func (rc SLAPolicies) For(messageType string) (SLAPolicy, bool) {
+	if messageType == "" {
+		messageType = DefaultMessageType
+	}
+
+	policy, ok := rc[messageType]
+
+	return policy, ok
+}
+
+// Validate checks every policy, see SLAPolicy.Validate
+func (rc SLAPolicies) Validate() error {
+	for messageType, policy := range rc {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("sla policy %q: %w", messageType, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that every reminder comes before the deadline and the expiry after it
+func (rc *SLAPolicy) Validate() error {
+	if rc.Deadline <= 0 {
+		return errors.New("deadline must be positive")
+	}
+
+	for _, v := range rc.Reminders {
+		if v <= 0 || v >= rc.Deadline {
+			return fmt.Errorf("reminder %s must be between zero and the deadline", v)
+		}
+	}
+
+	if rc.ExpireAfter != 0 && rc.ExpireAfter <= rc.Deadline {
+		return errors.New("expire_after must come after the deadline")
+	}
+
+	return nil
+}
+
+// Schedule computes the deadlines of a message created at the given time. The durations are business time
+// of the calendar, or wall clock time without a calendar.
+func (rc *SLAPolicy) Schedule(createdAt time.Time, calendar BusinessCalendar) *MessageSLA {
+	add := createdAt.Add
+	if calendar != nil {
+		add = func(d time.Duration) time.Time {
+			return calendar.Add(createdAt, d)
+		}
+	}
+
+	sla := MessageSLA{
+		DueAt:           add(rc.Deadline),
+		RemindAt:        make([]time.Time, 0, len(rc.Reminders)),
+		EscalationGroup: rc.EscalationGroup,
+	}
+
+	for _, v := range rc.Reminders {
+		sla.RemindAt = append(sla.RemindAt, add(v))
+	}
+	slices.SortFunc(sla.RemindAt, time.Time.Compare)
+
+	if rc.ExpireAfter > 0 {
+		sla.ExpiresAt = add(rc.ExpireAfter)
+	}
+
+	sla.NextCheckAt = sla.Next(nil)
+
+	return &sla
+}
+
+// Due returns the escalation that is due at the given time and has not been recorded yet, if any.
+// An expiry goes before a breach, and a breach before the reminders.
+func (rc *MessageSLA) Due(now time.Time, recorded []Escalation) *Escalation {
+	if !rc.ExpiresAt.IsZero() && !now.Before(rc.ExpiresAt) && !hasEscalation(recorded, EscalationExpiry, rc.ExpiresAt) {
+		return &Escalation{Level: EscalationExpiry, DueAt: rc.ExpiresAt}
+	}
+
+	if !now.Before(rc.DueAt) && !hasEscalation(recorded, EscalationBreach, rc.DueAt) {
+		return &Escalation{Level: EscalationBreach, DueAt: rc.DueAt, Group: rc.EscalationGroup}
+	}
+
+	if !now.Before(rc.DueAt) {
+		return nil
+	}
+
+	// only the latest reminder that is due is sent, older ones are skipped
+	for i := len(rc.RemindAt) - 1; i >= 0; i-- {
+		if now.Before(rc.RemindAt[i]) {
+			continue
+		}
+		if hasEscalation(recorded, EscalationReminder, rc.RemindAt[i]) {
+			break
+		}
+		return &Escalation{Level: EscalationReminder, DueAt: rc.RemindAt[i]}
+	}
+
+	return nil
+}
+
+// Next returns the earliest point of the SLA that has not been recorded yet, or zero if there is none
+func (rc *MessageSLA) Next(recorded []Escalation) time.Time {
+	var next time.Time
+	consider := func(at time.Time, level string) {
+		if at.IsZero() || hasEscalation(recorded, level, at) {
+			return
+		}
+		if next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+
+	// reminders before the latest recorded one were skipped, and none are left after a breach
+	lastReminder := rc.DueAt
+	if !hasEscalation(recorded, EscalationBreach, rc.DueAt) {
+		lastReminder = time.Time{}
+	}
+	for _, v := range recorded {
+		if v.Level == EscalationReminder && v.DueAt.After(lastReminder) {
+			lastReminder = v.DueAt
+		}
+	}
+	for _, v := range rc.RemindAt {
+		if v.After(lastReminder) {
+			consider(v, EscalationReminder)
+		}
+	}
+
+	consider(rc.DueAt, EscalationBreach)
+	consider(rc.ExpiresAt, EscalationExpiry)
+
+	return next
+}
+
+func hasEscalation(recorded []Escalation, level string, dueAt time.Time) bool {
+	return slices.ContainsFunc(recorded, func(v Escalation) bool {
+		return v.Level == level && v.DueAt.Equal(dueAt)
+	})
+}
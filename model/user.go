@@ -1,6 +1,15 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
+
+const (
+	RoleMaker   = "maker"
+	RoleChecker = "checker"
+	RoleAdmin   = "admin"
+)
 
 type User struct {
 	DeletedAt time.Time `json:"deleted_at"`
@@ -8,11 +17,92 @@ type User struct {
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	Password  string    `json:"password"`
+	Role      string    `json:"role"`
 	ID        string    `json:"id"`
+	// RelatedUserIDs lists users that this user may not check messages for, and vice versa
+	RelatedUserIDs []string `json:"related_user_ids"`
+	// Groups are the named checker groups the user belongs to
+	Groups []string `json:"groups"`
+	// Team is the department the user works in, e.g. finance
+	Team string `json:"team"`
 }
 
 type UserCreateRequest struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" example:"maker,checker,admin"`
+	// RelatedUserIDs lists users with a conflict of interest, e.g. family members or direct reports
+	RelatedUserIDs []string `json:"related_user_ids"`
+	// Groups are the named checker groups, e.g. compliance or team-leads
+	Groups []string `json:"groups"`
+	// Team is the department the user works in, e.g. finance
+	Team string `json:"team"`
+}
+
+// UserUpdateRequest changes the fields that are present, the omitted ones keep their current value
+type UserUpdateRequest struct {
+	Username *string `json:"username,omitempty"`
+	Email    *string `json:"email,omitempty"`
+	// Password is empty or omitted to keep the current one
+	Password *string `json:"password,omitempty"`
+	Role     *string `json:"role,omitempty" example:"maker,checker,admin"`
+	// RelatedUserIDs replaces the users with a conflict of interest, an empty list removes them all
+	RelatedUserIDs *[]string `json:"related_user_ids,omitempty"`
+	// Groups replaces the checker groups, an empty list removes them all
+	Groups *[]string `json:"groups,omitempty"`
+	Team   *string   `json:"team,omitempty"`
+}
+
+// IsEmpty reports whether the update changes nothing
+func (rc *UserUpdateRequest) IsEmpty() bool {
+	return rc.Username == nil &&
+		rc.Email == nil &&
+		(rc.Password == nil || *rc.Password == "") &&
+		rc.Role == nil &&
+		rc.RelatedUserIDs == nil &&
+		rc.Groups == nil &&
+		rc.Team == nil
+}
+
+// Apply returns the user with the present fields of the update
+func (rc *UserUpdateRequest) Apply(user User) User {
+	if rc.Username != nil {
+		user.Username = *rc.Username
+	}
+	if rc.Email != nil {
+		user.Email = *rc.Email
+	}
+	if rc.Password != nil && *rc.Password != "" {
+		user.Password = *rc.Password
+	}
+	if rc.Role != nil {
+		user.Role = *rc.Role
+	}
+	if rc.RelatedUserIDs != nil {
+		user.RelatedUserIDs = *rc.RelatedUserIDs
+	}
+	if rc.Groups != nil {
+		user.Groups = *rc.Groups
+	}
+	if rc.Team != nil {
+		user.Team = *rc.Team
+	}
+
+	return user
+}
+
+// InGroup reports whether the user belongs to the given checker group.
+func (rc *User) InGroup(group string) bool {
+	return slices.Contains(rc.Groups, group)
+}
+
+// IsValidRole reports whether the given role is one of the known user roles.
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleMaker, RoleChecker, RoleAdmin:
+		return true
+	}
+
+	return false
 }
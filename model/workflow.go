@@ -0,0 +1,337 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The default workflow is followed by the message types that no workflow definition covers, with the approval chain
+// and SLA policy of their type. Messages stored before workflows existed follow it as well.
+const (
+	DefaultWorkflowName    = "default"
+	DefaultWorkflowVersion = 1
+)
+
+// Recipients of the notification hooks of a workflow
+const (
+	HookRecipientSender   = "sender"
+	HookRecipientReceiver = "receiver"
+	HookRecipientAssignee = "assignee"
+	// HookRecipientCheckers are the checkers who voted on the message
+	HookRecipientCheckers = "checkers"
+)
+
+// WorkflowRef is the workflow definition a message follows, it is set when the message goes up for review
+type WorkflowRef struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// Workflow is a version of the definition of how messages of its message types are reviewed. A new version of a
+// workflow applies to the messages submitted from then on, the messages in review keep the version they started under.
+type Workflow struct {
+	Name    string `yaml:"name"`
+	Version int    `yaml:"version"`
+	// MessageTypes start on the latest version of the workflow
+	MessageTypes []string `yaml:"message_types"`
+	// States are the names of the statuses a message of the workflow can be in. They are picked from the built-in
+	// statuses in message_status.go, whose behaviour is part of the message use cases, a workflow can't add new ones.
+	States []string `yaml:"states"`
+	// Transitions are the only moves between the states, the message use cases check them for every status change
+	Transitions []WorkflowTransition `yaml:"transitions"`
+	// Steps is the approval chain, each step names the role and/or group of the checkers who decide on it
+	Steps []ApprovalStep `yaml:"steps"`
+	SLA   *SLAPolicy     `yaml:"sla"`
+	Hooks []WorkflowHook `yaml:"hooks"`
+}
+
+// WorkflowTransition allows the actor to move a message from one state to another
+type WorkflowTransition struct {
+	From  string `yaml:"from"`
+	To    string `yaml:"to"`
+	Actor string `yaml:"actor"`
+}
+
+// WorkflowHook notifies the recipients whenever a message enters the state
+type WorkflowHook struct {
+	State  string   `yaml:"state"`
+	Notify []string `yaml:"notify"`
+}
+
+// Workflows are the loaded workflow definitions, every version of them
+type Workflows []Workflow
+
+// DefaultWorkflow allows the transitions of the state table in message_status.go, without steps, SLA or hooks of its own
+func DefaultWorkflow() Workflow {
+	workflow := Workflow{
+		Name:        DefaultWorkflowName,
+		Version:     DefaultWorkflowVersion,
+		States:      make([]string, 0, len(messageStatusNames)),
+		Transitions: make([]WorkflowTransition, 0, len(messageTransitions)),
+	}
+
+	for _, v := range []int{
+		MessageStatusDraft,
+		MessageStatusPending,
+		MessageStatusAccepted,
+		MessageStatusRejected,
+		MessageStatusChangesRequested,
+		MessageStatusWithdrawn,
+		MessageStatusExpired,
+		MessageStatusRecalled,
+	} {
+		workflow.States = append(workflow.States, StatusName(v))
+	}
+
+	for _, v := range messageTransitions {
+		workflow.Transitions = append(workflow.Transitions, WorkflowTransition{
+			From:  StatusName(v.From),
+			To:    StatusName(v.To),
+			Actor: v.Actor,
+		})
+	}
+
+	return workflow
+}
+
+// Ref returns the reference a message that follows the workflow stores
+func (rc *Workflow) Ref() *WorkflowRef {
+	return &WorkflowRef{
+		Name:    rc.Name,
+		Version: rc.Version,
+	}
+}
+
+// HasState reports whether a message of the workflow can be in the status
+func (rc *Workflow) HasState(status int) bool {
+	return slices.Contains(rc.States, StatusName(status))
+}
+
+// ValidateTransition checks the state machine of the workflow for a move from one status to another by the given actor
+func (rc *Workflow) ValidateTransition(from, to int, actor string) error {
+	if !rc.HasState(from) || !rc.HasState(to) {
+		return &TransitionError{Reason: TransitionReasonUnknownStatus, Actor: actor, From: from, To: to}
+	}
+
+	allowed := false
+	for _, v := range rc.Transitions {
+		if v.From != StatusName(from) || v.To != StatusName(to) {
+			continue
+		}
+		if v.Actor == actor {
+			return nil
+		}
+		allowed = true
+	}
+
+	if allowed {
+		return &TransitionError{Reason: TransitionReasonActorNotAllowed, Actor: actor, From: from, To: to}
+	}
+
+	return &TransitionError{Reason: TransitionReasonNotAllowed, Actor: actor, From: from, To: to}
+}
+
+// AllowedTransitions returns the transitions of the workflow that can be made from the given status
+func (rc *Workflow) AllowedTransitions(from int) []MessageTransition {
+	transitions := make([]MessageTransition, 0)
+	for _, v := range rc.Transitions {
+		if v.From != StatusName(from) {
+			continue
+		}
+
+		to, _ := StatusByName(v.To)
+		transitions = append(transitions, MessageTransition{From: from, To: to, Actor: v.Actor})
+	}
+
+	return transitions
+}
+
+// Recipients returns who is notified when a message enters the status
+func (rc *Workflow) Recipients(status int) []string {
+	recipients := make([]string, 0)
+	for _, v := range rc.Hooks {
+		if v.State != StatusName(status) {
+			continue
+		}
+
+		for _, recipient := range v.Notify {
+			if !slices.Contains(recipients, recipient) {
+				recipients = append(recipients, recipient)
+			}
+		}
+	}
+
+	return recipients
+}
+
+// Validate checks that the states, transitions, steps, SLA and hooks of the workflow fit together
+func (rc *Workflow) Validate() error {
+	if len(rc.MessageTypes) == 0 {
+		return errors.New("no message types")
+	}
+
+	for i, v := range rc.States {
+		if _, ok := StatusByName(v); !ok {
+			return fmt.Errorf("unknown state %q, states are picked from the built-in statuses %s", v, strings.Join(StatusNames(), ", "))
+		}
+		if slices.Contains(rc.States[:i], v) {
+			return fmt.Errorf("duplicate state %q", v)
+		}
+	}
+
+	pending := StatusName(MessageStatusPending)
+	if !slices.Contains(rc.States, pending) {
+		return fmt.Errorf("states must include %s", pending)
+	}
+
+	for i, v := range rc.Transitions {
+		if !slices.Contains(rc.States, v.From) || !slices.Contains(rc.States, v.To) {
+			return fmt.Errorf("transition from %s to %s: both states must be listed in states", v.From, v.To)
+		}
+		if !IsValidActor(v.Actor) {
+			return fmt.Errorf("transition from %s to %s: invalid actor %q", v.From, v.To, v.Actor)
+		}
+		if slices.Contains(rc.Transitions[:i], v) {
+			return fmt.Errorf("duplicate transition from %s to %s by %s", v.From, v.To, v.Actor)
+		}
+	}
+
+	// messages start as draft or pending, any other state must be reachable
+	for _, v := range rc.States {
+		if v == pending || v == StatusName(MessageStatusDraft) {
+			continue
+		}
+		if !slices.ContainsFunc(rc.Transitions, func(t WorkflowTransition) bool { return t.To == v }) {
+			return fmt.Errorf("state %s can't be reached by any transition", v)
+		}
+	}
+
+	if err := validateSteps(rc.Steps); err != nil {
+		return err
+	}
+
+	if rc.SLA != nil {
+		if err := rc.SLA.Validate(); err != nil {
+			return fmt.Errorf("sla: %w", err)
+		}
+
+		expired := WorkflowTransition{From: pending, To: StatusName(MessageStatusExpired), Actor: ActorSystem}
+		if rc.SLA.ExpireAfter > 0 && !slices.Contains(rc.Transitions, expired) {
+			return errors.New("sla: expire_after needs the transition from pending to expired by system")
+		}
+	}
+
+	for _, v := range rc.Hooks {
+		if !slices.Contains(rc.States, v.State) {
+			return fmt.Errorf("hook on %q: state must be listed in states", v.State)
+		}
+		if len(v.Notify) == 0 {
+			return fmt.Errorf("hook on %s: nobody to notify", v.State)
+		}
+		for _, recipient := range v.Notify {
+			if !isValidHookRecipient(recipient) {
+				return fmt.Errorf("hook on %s: invalid recipient %q", v.State, recipient)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get returns the version of the workflow
+func (rc Workflows) Get(name string, version int) (*Workflow, bool) {
+	for i := range rc {
+		if rc[i].Name == name && rc[i].Version == version {
+			return &rc[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Latest returns the latest version of the workflow that covers the message type
+func (rc Workflows) Latest(messageType string) (*Workflow, bool) {
+	if messageType == "" {
+		messageType = DefaultMessageType
+	}
+
+	for _, v := range rc.latest() {
+		if slices.Contains(v.MessageTypes, messageType) {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// AutoAccepts reports whether messages of the type, submitted now, can be accepted by the system. Message types
+// without a workflow definition follow the default workflow, which allows it.
+func (rc Workflows) AutoAccepts(messageType string) bool {
+	workflow, ok := rc.Latest(messageType)
+	if !ok {
+		return true
+	}
+
+	return workflow.ValidateTransition(MessageStatusPending, MessageStatusAccepted, ActorSystem) == nil
+}
+
+// Validate checks every version of every workflow, and that the latest versions cover each message type at most once
+func (rc Workflows) Validate() error {
+	for i := range rc {
+		workflow := &rc[i]
+
+		if workflow.Name == "" {
+			return fmt.Errorf("workflow %d has no name", i)
+		}
+		if workflow.Name == DefaultWorkflowName {
+			return fmt.Errorf("workflow name %q is reserved", DefaultWorkflowName)
+		}
+		if workflow.Version < 1 {
+			return fmt.Errorf("workflow %q: version must be positive", workflow.Name)
+		}
+		if _, ok := rc[:i].Get(workflow.Name, workflow.Version); ok {
+			return fmt.Errorf("workflow %q: duplicate version %d", workflow.Name, workflow.Version)
+		}
+
+		if err := workflow.Validate(); err != nil {
+			return fmt.Errorf("workflow %q version %d: %w", workflow.Name, workflow.Version, err)
+		}
+	}
+
+	covered := make(map[string]string)
+	for _, v := range rc.latest() {
+		for _, messageType := range v.MessageTypes {
+			if name, ok := covered[messageType]; ok {
+				return fmt.Errorf("message type %q is covered by workflows %q and %q", messageType, name, v.Name)
+			}
+			covered[messageType] = v.Name
+		}
+	}
+
+	return nil
+}
+
+// latest returns the latest version of every workflow
+func (rc Workflows) latest() []*Workflow {
+	latest := make([]*Workflow, 0, len(rc))
+	for i := range rc {
+		idx := slices.IndexFunc(latest, func(v *Workflow) bool { return v.Name == rc[i].Name })
+		switch {
+		case idx < 0:
+			latest = append(latest, &rc[i])
+		case latest[idx].Version < rc[i].Version:
+			latest[idx] = &rc[i]
+		}
+	}
+
+	return latest
+}
+
+func isValidHookRecipient(recipient string) bool {
+	return recipient == HookRecipientSender ||
+		recipient == HookRecipientReceiver ||
+		recipient == HookRecipientAssignee ||
+		recipient == HookRecipientCheckers
+}
@@ -0,0 +1,198 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/calendar"
+	"github.com/fleimkeipa/maker-checker/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the application settings loaded from the YAML config file.
+type Config struct {
+	ApprovalChains   model.ApprovalChains   `yaml:"approval_chains"`
+	RejectionReasons model.RejectionReasons `yaml:"rejection_reasons"`
+	// ChangeRequestPolicies are the approver roles, required approvals and expiry of change requests per resource type
+	ChangeRequestPolicies model.ChangeRequestPolicies `yaml:"change_request_policies"`
+	// ReviewLeaseTTL is how long a checker's claim on a pending message lasts
+	ReviewLeaseTTL time.Duration `yaml:"review_lease_ttl"`
+	// AssignmentStrategy picks the checker new pending messages are assigned to
+	AssignmentStrategy string `yaml:"assignment_strategy"`
+	// SLAPolicies are the deadlines, reminders, escalation group and expiry of pending messages per message type
+	SLAPolicies model.SLAPolicies `yaml:"sla_policies"`
+	// SLACheckInterval is how often the SLA scheduler looks for due messages
+	SLACheckInterval time.Duration `yaml:"sla_check_interval"`
+	// DeliveryCheckInterval is how often the delivery worker looks for scheduled messages whose time has come
+	DeliveryCheckInterval time.Duration `yaml:"delivery_check_interval"`
+	// AutoApproval routes new messages by their sender, receiver and text: accepted right away, reviewed as usual
+	// or reviewed by senior checkers
+	AutoApproval model.AutoApprovalPolicy `yaml:"auto_approval"`
+	// BusinessCalendar makes SLA deadlines count business hours only, without it they count wall clock time
+	BusinessCalendar *calendar.Config `yaml:"business_calendar"`
+	// WorkflowsFile is the YAML file of the workflow definitions, relative to the working directory. Message types
+	// without a workflow follow the default workflow with their approval chain and SLA policy.
+	WorkflowsFile string `yaml:"workflows_file"`
+	// Workflows are loaded from WorkflowsFile
+	Workflows model.Workflows `yaml:"-"`
+}
+
+// workflowsFile is the layout of the workflows file
+type workflowsFile struct {
+	Workflows model.Workflows `yaml:"workflows"`
+}
+
+// LoadConfig reads the config file given by CONFIG_PATH, or config.yaml by default.
+// A missing file is not an error, the default settings are used instead.
+func LoadConfig() (*Config, error) {
+	path := os.Getenv("CONFIG_PATH")
+	if path == "" {
+		path = "config.yaml"
+	}
+
+	cfg := &Config{}
+
+	file, err := os.Open(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+
+	if err == nil {
+		defer file.Close()
+
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to decode config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.setDefaults(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if cfg.WorkflowsFile != "" {
+		cfg.Workflows, err = loadWorkflows(cfg.WorkflowsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.AutoApproval.ValidateWorkflows(cfg.Workflows); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// loadWorkflows reads and validates the workflows file, unlike the config file it must exist once it is configured
+func loadWorkflows(path string) (model.Workflows, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workflows file: %w", err)
+	}
+	defer file.Close()
+
+	var workflows workflowsFile
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&workflows); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode workflows file %s: %w", path, err)
+	}
+
+	if err := workflows.Workflows.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid workflows file %s: %w", path, err)
+	}
+
+	return workflows.Workflows, nil
+}
+
+func (rc *Config) setDefaults() error {
+	if rc.ApprovalChains == nil {
+		rc.ApprovalChains = model.DefaultApprovalChains()
+	}
+	if _, ok := rc.ApprovalChains[model.DefaultMessageType]; !ok {
+		rc.ApprovalChains[model.DefaultMessageType] = model.DefaultApprovalChains()[model.DefaultMessageType]
+	}
+
+	// recalls of accepted messages are decided by checkers unless configured otherwise
+	if rc.ChangeRequestPolicies == nil {
+		rc.ChangeRequestPolicies = model.ChangeRequestPolicies{}
+	}
+	if _, ok := rc.ChangeRequestPolicies[model.ChangeResourceMessage]; !ok {
+		rc.ChangeRequestPolicies[model.ChangeResourceMessage] = model.ChangeRequestPolicy{
+			ApproverRoles: []string{model.RoleChecker},
+		}
+	}
+
+	if rc.ReviewLeaseTTL <= 0 {
+		rc.ReviewLeaseTTL = 15 * time.Minute
+	}
+
+	if rc.AssignmentStrategy == "" {
+		rc.AssignmentStrategy = model.AssignmentLeastLoaded
+	}
+	if !model.IsValidAssignmentStrategy(rc.AssignmentStrategy) {
+		return fmt.Errorf("unknown assignment strategy %q", rc.AssignmentStrategy)
+	}
+
+	if rc.SLACheckInterval <= 0 {
+		rc.SLACheckInterval = time.Minute
+	}
+
+	if rc.DeliveryCheckInterval <= 0 {
+		rc.DeliveryCheckInterval = time.Minute
+	}
+
+	if len(rc.RejectionReasons) == 0 {
+		rc.RejectionReasons = model.DefaultRejectionReasons()
+	}
+
+	if err := rc.ApprovalChains.Validate(); err != nil {
+		return err
+	}
+
+	if err := rc.RejectionReasons.Validate(); err != nil {
+		return err
+	}
+
+	if err := rc.ChangeRequestPolicies.Validate(); err != nil {
+		return err
+	}
+
+	if err := rc.SLAPolicies.Validate(); err != nil {
+		return err
+	}
+
+	if err := rc.AutoApproval.Validate(); err != nil {
+		return err
+	}
+
+	if rc.BusinessCalendar != nil {
+		if _, err := calendar.New(*rc.BusinessCalendar); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Calendar returns the business calendar, or nil if none is configured
+func (rc *Config) Calendar() (model.BusinessCalendar, error) {
+	if rc.BusinessCalendar == nil {
+		return nil, nil
+	}
+
+	cal, err := calendar.New(*rc.BusinessCalendar)
+	if err != nil {
+		return nil, err
+	}
+
+	return cal, nil
+}
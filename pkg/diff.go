@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"strings"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+// DiffLines returns a line based diff between two texts, using the longest common subsequence of their lines.
+func DiffLines(oldText, newText string) []model.DiffLine {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]model.DiffLine, 0, len(oldLines)+len(newLines))
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			diff = append(diff, model.DiffLine{Op: model.DiffOpEqual, Text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, model.DiffLine{Op: model.DiffOpDelete, Text: oldLines[i]})
+			i++
+		default:
+			diff = append(diff, model.DiffLine{Op: model.DiffOpInsert, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		diff = append(diff, model.DiffLine{Op: model.DiffOpDelete, Text: oldLines[i]})
+	}
+	for ; j < len(newLines); j++ {
+		diff = append(diff, model.DiffLine{Op: model.DiffOpInsert, Text: newLines[j]})
+	}
+
+	return diff
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
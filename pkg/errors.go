@@ -2,11 +2,12 @@ package pkg
 
 import "errors"
 
-// Error struct defines a custom error type with an error, status code, and message.
+// Error struct defines a custom error type with an error, status code, message, and an optional machine-readable reason.
 type Error struct {
 	err        error
 	statusCode int
 	message    string
+	reason     string
 }
 
 // NewError creates a new instance of the Error struct.
@@ -21,6 +22,13 @@ func NewError(err error, message string, statusCode int) *Error {
 	}
 }
 
+// NewErrorWithReason creates a new instance of the Error struct with a machine-readable reason.
+func NewErrorWithReason(err error, message, reason string, statusCode int) *Error {
+	e := NewError(err, message, statusCode)
+	e.reason = reason
+	return e
+}
+
 // Error implements the error interface by returning the original error message.
 func (rc *Error) Error() string {
 	return rc.err.Error()
@@ -35,3 +43,13 @@ func (rc *Error) Message() string {
 func (rc *Error) StatusCode() int {
 	return rc.statusCode
 }
+
+// Reason returns the machine-readable reason, if any.
+func (rc *Error) Reason() string {
+	return rc.reason
+}
+
+// Unwrap returns the original error.
+func (rc *Error) Unwrap() error {
+	return rc.err
+}
@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/fleimkeipa/maker-checker/model"
+
+	"go.uber.org/zap"
+)
+
+// LogNotifier writes notifications to the log, until they are delivered through a real channel
+type LogNotifier struct {
+	log *zap.SugaredLogger
+}
+
+func NewLogNotifier(log *zap.SugaredLogger) *LogNotifier {
+	return &LogNotifier{
+		log: log,
+	}
+}
+
+// Notify logs the notification
+func (rc *LogNotifier) Notify(ctx context.Context, notification model.Notification) error {
+	rc.log.Infow("notification",
+		"kind", notification.Kind,
+		"message_id", notification.MessageID,
+		"recipient_ids", notification.RecipientIDs,
+		"text", notification.Text,
+	)
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// auditMongo keeps the snapshots as JSON strings, so that the hashed content survives the round trip unchanged
+type auditMongo struct {
+	CreatedAt    time.Time          `bson:"created_at"`
+	ID           primitive.ObjectID `bson:"_id"`
+	Before       string             `bson:"before"`
+	After        string             `bson:"after"`
+	Action       string             `bson:"action"`
+	ResourceType string             `bson:"resource_type"`
+	ResourceID   string             `bson:"resource_id"`
+	ActorID      string             `bson:"actor_id"`
+	OnBehalfOfID string             `bson:"on_behalf_of_id,omitempty"`
+	RequestID    string             `bson:"request_id"`
+	ClientIP     string             `bson:"client_ip"`
+	PrevHash     string             `bson:"prev_hash"`
+	Hash         string             `bson:"hash"`
+	Sequence     int64              `bson:"sequence"`
+}
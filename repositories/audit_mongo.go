@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditMongoRepo struct {
+	db *mongo.Database
+}
+
+func NewAuditMongoRepo(db *mongo.Database) *AuditMongoRepo {
+	return &AuditMongoRepo{
+		db: db,
+	}
+}
+
+var auditColl = "audit_trail"
+
+// EnsureIndexes creates the unique sequence index that keeps the chain linear under concurrent appends
+func (rc *AuditMongoRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := rc.
+		db.
+		Collection(auditColl).
+		Indexes().
+		CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.M{"sequence": 1},
+			Options: options.Index().SetUnique(true),
+		})
+	if err != nil {
+		return fmt.Errorf("failed to create audit index: %w", err)
+	}
+
+	return nil
+}
+
+// Create appends the record, it returns interfaces.ErrAuditSequenceTaken if another record got the sequence first
+func (rc *AuditMongoRepo) Create(ctx context.Context, record *model.AuditRecord) (*model.AuditRecord, error) {
+	mongoRecord := rc.internalToMongo(record)
+
+	_, err := rc.
+		db.
+		Collection(auditColl).
+		InsertOne(ctx, mongoRecord)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, interfaces.ErrAuditSequenceTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit record: %w", err)
+	}
+
+	record.ID = mongoRecord.ID.Hex()
+
+	return record, nil
+}
+
+// Last returns the record with the highest sequence, or nil if the trail is empty
+func (rc *AuditMongoRepo) Last(ctx context.Context) (*model.AuditRecord, error) {
+	mongoOptions := options.FindOne().
+		SetSort(bson.M{"sequence": -1})
+
+	record := new(auditMongo)
+	err := rc.
+		db.
+		Collection(auditColl).
+		FindOne(ctx, bson.M{}, mongoOptions).
+		Decode(record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find last audit record: %w", err)
+	}
+
+	return rc.mongoToInternal(record), nil
+}
+
+// ListAfter returns up to limit records with a sequence greater than the given one, in sequence order
+func (rc *AuditMongoRepo) ListAfter(ctx context.Context, sequence int64, limit uint) ([]model.AuditRecord, error) {
+	mongoOptions := options.Find().
+		SetSort(bson.M{"sequence": 1}).
+		SetLimit(int64(limit))
+
+	records := make([]auditMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(auditColl).
+		Find(ctx, bson.M{"sequence": bson.M{"$gt": sequence}}, mongoOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find audit records: %w", err)
+	}
+
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode audit records: %w", err)
+	}
+
+	res := make([]model.AuditRecord, 0, len(records))
+	for _, v := range records {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+func (rc *AuditMongoRepo) mongoToInternal(record *auditMongo) *model.AuditRecord {
+	return &model.AuditRecord{
+		CreatedAt:    record.CreatedAt,
+		Before:       json.RawMessage(record.Before),
+		After:        json.RawMessage(record.After),
+		ID:           record.ID.Hex(),
+		Action:       record.Action,
+		ResourceType: record.ResourceType,
+		ResourceID:   record.ResourceID,
+		ActorID:      record.ActorID,
+		OnBehalfOfID: record.OnBehalfOfID,
+		RequestID:    record.RequestID,
+		ClientIP:     record.ClientIP,
+		PrevHash:     record.PrevHash,
+		Hash:         record.Hash,
+		Sequence:     record.Sequence,
+	}
+}
+
+func (rc *AuditMongoRepo) internalToMongo(record *model.AuditRecord) *auditMongo {
+	return &auditMongo{
+		CreatedAt:    record.CreatedAt,
+		ID:           primitive.NewObjectID(),
+		Before:       string(record.Before),
+		After:        string(record.After),
+		Action:       record.Action,
+		ResourceType: record.ResourceType,
+		ResourceID:   record.ResourceID,
+		ActorID:      record.ActorID,
+		OnBehalfOfID: record.OnBehalfOfID,
+		RequestID:    record.RequestID,
+		ClientIP:     record.ClientIP,
+		PrevHash:     record.PrevHash,
+		Hash:         record.Hash,
+		Sequence:     record.Sequence,
+	}
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// changeRequestMongo keeps the payload as a JSON string, so that any resource payload round trips unchanged
+type changeRequestMongo struct {
+	RequestedAt       time.Time             `bson:"requested_at"`
+	ExpiresAt         time.Time             `bson:"expires_at"`
+	DecidedAt         time.Time             `bson:"decided_at"`
+	Approvals         []changeApprovalMongo `bson:"approvals"`
+	ID                primitive.ObjectID    `bson:"_id"`
+	Payload           string                `bson:"payload"`
+	ResourceType      string                `bson:"resource_type"`
+	ResourceID        string                `bson:"resource_id"`
+	Operation         string                `bson:"operation"`
+	RequestedBy       string                `bson:"requested_by"`
+	DecidedBy         string                `bson:"decided_by"`
+	Comment           string                `bson:"comment"`
+	Error             string                `bson:"error,omitempty"`
+	Status            string                `bson:"status"`
+	RequiredApprovals int                   `bson:"required_approvals"`
+}
+
+type changeApprovalMongo struct {
+	ApprovedAt time.Time `bson:"approved_at"`
+	ApproverID string    `bson:"approver_id"`
+	Comment    string    `bson:"comment"`
+}
@@ -0,0 +1,290 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChangeRequestMongoRepo struct {
+	db *mongo.Database
+}
+
+func NewChangeRequestMongoRepo(db *mongo.Database) *ChangeRequestMongoRepo {
+	return &ChangeRequestMongoRepo{
+		db: db,
+	}
+}
+
+var changeRequestColl = "change_requests"
+
+func (rc *ChangeRequestMongoRepo) Create(ctx context.Context, change *model.ChangeRequest) (*model.ChangeRequest, error) {
+	mongoChange := rc.internalToMongo(change)
+
+	query, err := rc.
+		db.
+		Collection(changeRequestColl).
+		InsertOne(ctx, mongoChange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create change request: %w", err)
+	}
+
+	oid, ok := query.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("can't get inserted ID")
+	}
+
+	change.ID = oid.Hex()
+
+	return change, nil
+}
+
+func (rc *ChangeRequestMongoRepo) GetByID(ctx context.Context, changeID string) (*model.ChangeRequest, error) {
+	oID, err := primitive.ObjectIDFromHex(changeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert change request id: %w", err)
+	}
+
+	change := new(changeRequestMongo)
+	err = rc.
+		db.
+		Collection(changeRequestColl).
+		FindOne(ctx, bson.M{"_id": oID}).
+		Decode(change)
+	if err != nil {
+		return nil, err
+	}
+
+	return rc.mongoToInternal(change), nil
+}
+
+func (rc *ChangeRequestMongoRepo) List(ctx context.Context, opts model.ChangeRequestFindOpts) ([]model.ChangeRequest, error) {
+	filter := bson.M{}
+	if opts.ResourceType.IsSended {
+		filter["resource_type"] = opts.ResourceType.Value
+	} else if opts.ResourceTypes != nil {
+		filter["resource_type"] = bson.M{"$in": opts.ResourceTypes}
+	}
+	if opts.ResourceID.IsSended {
+		filter["resource_id"] = opts.ResourceID.Value
+	}
+	if opts.Status.IsSended {
+		filter["status"] = opts.Status.Value
+	}
+
+	mongoOptions := options.Find().
+		SetSort(bson.M{"requested_at": -1}).
+		SetLimit(int64(opts.Limit)).
+		SetSkip(int64(opts.Skip))
+
+	changes := make([]changeRequestMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(changeRequestColl).
+		Find(ctx, filter, mongoOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find change requests: %w", err)
+	}
+
+	if err := cur.All(ctx, &changes); err != nil {
+		return nil, fmt.Errorf("failed to decode change requests: %w", err)
+	}
+
+	res := make([]model.ChangeRequest, 0, len(changes))
+	for _, v := range changes {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+// AddApproval records the approval on a pending, unexpired change request that the approver has not approved yet,
+// and returns the change request with the approval. Otherwise interfaces.ErrChangeRequestClosed is returned.
+func (rc *ChangeRequestMongoRepo) AddApproval(ctx context.Context, changeID string, approval *model.ChangeApproval) (*model.ChangeRequest, error) {
+	oID, err := primitive.ObjectIDFromHex(changeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert change request id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":                   oID,
+		"status":                model.ChangeRequestStatusPending,
+		"expires_at":            bson.M{"$gt": time.Now()},
+		"approvals.approver_id": bson.M{"$ne": approval.ApproverID},
+	}
+	update := bson.M{
+		"$push": bson.M{
+			"approvals": changeApprovalMongo{
+				ApprovedAt: approval.ApprovedAt,
+				ApproverID: approval.ApproverID,
+				Comment:    approval.Comment,
+			},
+		},
+	}
+
+	change := new(changeRequestMongo)
+	err = rc.
+		db.
+		Collection(changeRequestColl).
+		FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(change)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, interfaces.ErrChangeRequestClosed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add approval: %w", err)
+	}
+
+	return rc.mongoToInternal(change), nil
+}
+
+// Decide moves a pending, unexpired change request to the given status. Only the first decision wins,
+// later ones get interfaces.ErrChangeRequestClosed.
+func (rc *ChangeRequestMongoRepo) Decide(ctx context.Context, changeID string, status string, deciderID string, comment string) error {
+	oID, err := primitive.ObjectIDFromHex(changeID)
+	if err != nil {
+		return fmt.Errorf("failed to convert change request id: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":        oID,
+		"status":     model.ChangeRequestStatusPending,
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"decided_by": deciderID,
+			"decided_at": now,
+			"comment":    comment,
+		},
+	}
+	query, err := rc.
+		db.
+		Collection(changeRequestColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to decide change request: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return interfaces.ErrChangeRequestClosed
+	}
+
+	return nil
+}
+
+// MarkFailed marks an approved change request whose change could not be applied
+func (rc *ChangeRequestMongoRepo) MarkFailed(ctx context.Context, changeID string, reason string) error {
+	oID, err := primitive.ObjectIDFromHex(changeID)
+	if err != nil {
+		return fmt.Errorf("failed to convert change request id: %w", err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status": model.ChangeRequestStatusFailed,
+			"error":  reason,
+		},
+	}
+	_, err = rc.
+		db.
+		Collection(changeRequestColl).
+		UpdateOne(ctx, bson.M{"_id": oID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to mark change request as failed: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireStale marks every pending change request past its expiry as expired
+func (rc *ChangeRequestMongoRepo) ExpireStale(ctx context.Context) error {
+	filter := bson.M{
+		"status":     model.ChangeRequestStatusPending,
+		"expires_at": bson.M{"$lte": time.Now()},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status": model.ChangeRequestStatusExpired,
+		},
+	}
+	_, err := rc.
+		db.
+		Collection(changeRequestColl).
+		UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to expire change requests: %w", err)
+	}
+
+	return nil
+}
+
+func (rc *ChangeRequestMongoRepo) mongoToInternal(change *changeRequestMongo) *model.ChangeRequest {
+	approvals := make([]model.ChangeApproval, 0, len(change.Approvals))
+	for _, v := range change.Approvals {
+		approvals = append(approvals, model.ChangeApproval{
+			ApprovedAt: v.ApprovedAt,
+			ApproverID: v.ApproverID,
+			Comment:    v.Comment,
+		})
+	}
+
+	return &model.ChangeRequest{
+		RequestedAt:       change.RequestedAt,
+		ExpiresAt:         change.ExpiresAt,
+		DecidedAt:         change.DecidedAt,
+		Payload:           json.RawMessage(change.Payload),
+		Approvals:         approvals,
+		ID:                change.ID.Hex(),
+		ResourceType:      change.ResourceType,
+		ResourceID:        change.ResourceID,
+		Operation:         change.Operation,
+		RequestedBy:       change.RequestedBy,
+		DecidedBy:         change.DecidedBy,
+		Comment:           change.Comment,
+		Error:             change.Error,
+		Status:            change.Status,
+		RequiredApprovals: change.RequiredApprovals,
+	}
+}
+
+func (rc *ChangeRequestMongoRepo) internalToMongo(change *model.ChangeRequest) *changeRequestMongo {
+	approvals := make([]changeApprovalMongo, 0, len(change.Approvals))
+	for _, v := range change.Approvals {
+		approvals = append(approvals, changeApprovalMongo{
+			ApprovedAt: v.ApprovedAt,
+			ApproverID: v.ApproverID,
+			Comment:    v.Comment,
+		})
+	}
+
+	return &changeRequestMongo{
+		RequestedAt:       change.RequestedAt,
+		ExpiresAt:         change.ExpiresAt,
+		DecidedAt:         change.DecidedAt,
+		Approvals:         approvals,
+		ID:                primitive.NewObjectID(),
+		Payload:           string(change.Payload),
+		ResourceType:      change.ResourceType,
+		ResourceID:        change.ResourceID,
+		Operation:         change.Operation,
+		RequestedBy:       change.RequestedBy,
+		DecidedBy:         change.DecidedBy,
+		Comment:           change.Comment,
+		Error:             change.Error,
+		Status:            change.Status,
+		RequiredApprovals: change.RequiredApprovals,
+	}
+}
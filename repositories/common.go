@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func hexToObjectIDs(ids []string) ([]primitive.ObjectID, error) {
+	oIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, v := range ids {
+		oID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q: %w", v, err)
+		}
+		oIDs = append(oIDs, oID)
+	}
+
+	return oIDs, nil
+}
+
+func objectIDsToHex(oIDs []primitive.ObjectID) []string {
+	ids := make([]string, 0, len(oIDs))
+	for _, v := range oIDs {
+		ids = append(ids, v.Hex())
+	}
+
+	return ids
+}
+
+// optionalObjectIDToHex returns an empty string for the zero object id, which stands for an unset optional id
+func optionalObjectIDToHex(oID primitive.ObjectID) string {
+	if oID.IsZero() {
+		return ""
+	}
+
+	return oID.Hex()
+}
+
+// optionalHexToObjectID returns the zero object id for an empty string, which stands for an unset optional id
+func optionalHexToObjectID(id string) (primitive.ObjectID, error) {
+	if id == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	return primitive.ObjectIDFromHex(id)
+}
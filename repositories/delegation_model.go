@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type delegationMongo struct {
+	CreatedAt    time.Time          `bson:"created_at"`
+	StartsAt     time.Time          `bson:"starts_at"`
+	EndsAt       time.Time          `bson:"ends_at"`
+	RevokedAt    time.Time          `bson:"revoked_at,omitempty"`
+	ID           primitive.ObjectID `bson:"_id"`
+	DelegatorID  primitive.ObjectID `bson:"delegator_id"`
+	DelegateID   primitive.ObjectID `bson:"delegate_id"`
+	MessageTypes []string           `bson:"message_types"`
+}
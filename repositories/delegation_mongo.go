@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DelegationMongoRepo struct {
+	db *mongo.Database
+}
+
+func NewDelegationMongoRepo(db *mongo.Database) *DelegationMongoRepo {
+	return &DelegationMongoRepo{
+		db: db,
+	}
+}
+
+var delegationColl = "delegations"
+
+func (rc *DelegationMongoRepo) Create(ctx context.Context, delegation *model.Delegation) (*model.Delegation, error) {
+	mongoDelegation, err := rc.internalToMongo(delegation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delegation: %w", err)
+	}
+
+	query, err := rc.
+		db.
+		Collection(delegationColl).
+		InsertOne(ctx, mongoDelegation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create delegation: %w", err)
+	}
+
+	oid, ok := query.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("can't get inserted ID")
+	}
+
+	delegation.ID = oid.Hex()
+
+	return delegation, nil
+}
+
+func (rc *DelegationMongoRepo) GetByID(ctx context.Context, delegationID string) (*model.Delegation, error) {
+	oID, err := primitive.ObjectIDFromHex(delegationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delegation id: %w", err)
+	}
+
+	delegation := new(delegationMongo)
+	err = rc.
+		db.
+		Collection(delegationColl).
+		FindOne(ctx, bson.M{"_id": oID}).
+		Decode(delegation)
+	if err != nil {
+		return nil, err
+	}
+
+	return rc.mongoToInternal(delegation), nil
+}
+
+// ListByUser lists the delegations the user gave or received, newest first
+func (rc *DelegationMongoRepo) ListByUser(ctx context.Context, userID string) ([]model.Delegation, error) {
+	oID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert user id: %w", err)
+	}
+
+	filter := bson.M{
+		"$or": []bson.M{
+			{"delegator_id": oID},
+			{"delegate_id": oID},
+		},
+	}
+
+	return rc.find(ctx, filter, options.Find().SetSort(bson.M{"_id": -1}))
+}
+
+// ListActive lists the delegations from the delegator to the delegate that are in effect at the given time
+func (rc *DelegationMongoRepo) ListActive(ctx context.Context, delegatorID, delegateID string, now time.Time) ([]model.Delegation, error) {
+	delegatorOID, err := primitive.ObjectIDFromHex(delegatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delegator id: %w", err)
+	}
+	delegateOID, err := primitive.ObjectIDFromHex(delegateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delegate id: %w", err)
+	}
+
+	filter := bson.M{
+		"delegator_id": delegatorOID,
+		"delegate_id":  delegateOID,
+		"starts_at":    bson.M{"$lte": now},
+		"ends_at":      bson.M{"$gt": now},
+		"revoked_at":   bson.M{"$in": bson.A{time.Time{}, nil}},
+	}
+
+	return rc.find(ctx, filter, options.Find())
+}
+
+// Revoke ends the delegation at the given time, it fails if the delegation is already revoked
+func (rc *DelegationMongoRepo) Revoke(ctx context.Context, delegationID string, revokedAt time.Time) error {
+	oID, err := primitive.ObjectIDFromHex(delegationID)
+	if err != nil {
+		return fmt.Errorf("failed to convert delegation id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":        oID,
+		"revoked_at": bson.M{"$in": bson.A{time.Time{}, nil}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"revoked_at": revokedAt,
+		},
+	}
+	query, err := rc.
+		db.
+		Collection(delegationColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to revoke delegation: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return fmt.Errorf("not found active delegation with id: %v", delegationID)
+	}
+
+	return nil
+}
+
+func (rc *DelegationMongoRepo) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]model.Delegation, error) {
+	delegations := make([]delegationMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(delegationColl).
+		Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find delegations: %w", err)
+	}
+
+	if err := cur.All(ctx, &delegations); err != nil {
+		return nil, fmt.Errorf("failed to decode delegations: %w", err)
+	}
+
+	res := make([]model.Delegation, 0, len(delegations))
+	for _, v := range delegations {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+func (rc *DelegationMongoRepo) mongoToInternal(delegation *delegationMongo) *model.Delegation {
+	return &model.Delegation{
+		CreatedAt:    delegation.CreatedAt,
+		StartsAt:     delegation.StartsAt,
+		EndsAt:       delegation.EndsAt,
+		RevokedAt:    delegation.RevokedAt,
+		ID:           delegation.ID.Hex(),
+		DelegatorID:  delegation.DelegatorID.Hex(),
+		DelegateID:   delegation.DelegateID.Hex(),
+		MessageTypes: delegation.MessageTypes,
+	}
+}
+
+func (rc *DelegationMongoRepo) internalToMongo(delegation *model.Delegation) (*delegationMongo, error) {
+	delegatorID, err := primitive.ObjectIDFromHex(delegation.DelegatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delegator id: %w", err)
+	}
+	delegateID, err := primitive.ObjectIDFromHex(delegation.DelegateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delegate id: %w", err)
+	}
+
+	return &delegationMongo{
+		CreatedAt:    delegation.CreatedAt,
+		StartsAt:     delegation.StartsAt,
+		EndsAt:       delegation.EndsAt,
+		RevokedAt:    delegation.RevokedAt,
+		ID:           primitive.NewObjectID(),
+		DelegatorID:  delegatorID,
+		DelegateID:   delegateID,
+		MessageTypes: delegation.MessageTypes,
+	}, nil
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+// ErrAuditSequenceTaken is returned when a concurrent append already used the record's sequence
+var ErrAuditSequenceTaken = errors.New("audit sequence already taken")
+
+type AuditInterfaces interface {
+	Create(ctx context.Context, record *model.AuditRecord) (*model.AuditRecord, error)
+	Last(ctx context.Context) (*model.AuditRecord, error)
+	ListAfter(ctx context.Context, sequence int64, limit uint) ([]model.AuditRecord, error)
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+// ErrChangeRequestClosed is returned when a change request is no longer pending, has expired, or was already approved by the user
+var ErrChangeRequestClosed = errors.New("change request is no longer open")
+
+type ChangeRequestInterfaces interface {
+	Create(ctx context.Context, change *model.ChangeRequest) (*model.ChangeRequest, error)
+	GetByID(ctx context.Context, changeID string) (*model.ChangeRequest, error)
+	List(ctx context.Context, opts model.ChangeRequestFindOpts) ([]model.ChangeRequest, error)
+	AddApproval(ctx context.Context, changeID string, approval *model.ChangeApproval) (*model.ChangeRequest, error)
+	Decide(ctx context.Context, changeID string, status string, deciderID string, comment string) error
+	MarkFailed(ctx context.Context, changeID string, reason string) error
+	ExpireStale(ctx context.Context) error
+}
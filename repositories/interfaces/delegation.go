@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+type DelegationInterfaces interface {
+	Create(ctx context.Context, delegation *model.Delegation) (*model.Delegation, error)
+	GetByID(ctx context.Context, delegationID string) (*model.Delegation, error)
+	ListByUser(ctx context.Context, userID string) ([]model.Delegation, error)
+	ListActive(ctx context.Context, delegatorID, delegateID string, now time.Time) ([]model.Delegation, error)
+	Revoke(ctx context.Context, delegationID string, revokedAt time.Time) error
+}
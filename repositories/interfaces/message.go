@@ -2,13 +2,39 @@ package interfaces
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/fleimkeipa/maker-checker/model"
 )
 
+// ErrMessageClaimed is returned when a message is not pending, or another checker holds an active review lease on it
+var ErrMessageClaimed = errors.New("message is not pending or is claimed by another checker")
+
+// The conditional writes Update, UpdateDraft, Submit, AddVote, Finalize, Withdraw and Recall return a *model.VersionConflictError
+// when the stored message is no longer at the expected version
+
 type MessageInterfaces interface {
 	Create(ctx context.Context, message *model.Message) (*model.Message, error)
 	Update(ctx context.Context, messageID string, message *model.Message) (*model.Message, error)
 	List(ctx context.Context, opts model.MessageFindOpts) ([]model.Message, error)
 	GetByID(ctx context.Context, messageID string) (*model.Message, error)
+	ListByIDs(ctx context.Context, messageIDs []string) ([]model.Message, error)
+	AddVote(ctx context.Context, vote *model.VoteWrite) (*model.Message, error)
+	Finalize(ctx context.Context, messageID string, version int, step int, status int, nextStep int) error
+	AddVotes(ctx context.Context, votes []model.VoteWrite) error
+	Withdraw(ctx context.Context, messageID string, version int, deletedAt time.Time) error
+	UpdateDraft(ctx context.Context, message *model.Message) error
+	Submit(ctx context.Context, message *model.Message) error
+	Recall(ctx context.Context, messageID string, version int, recalledAt time.Time) (*model.Message, error)
+	ListQueue(ctx context.Context, opts model.ReviewQueueOpts) ([]model.Message, error)
+	Claim(ctx context.Context, messageID string, claim *model.ReviewClaim) (*model.Message, error)
+	Release(ctx context.Context, messageID string, checkerID string) error
+	Assign(ctx context.Context, messageID string, checkerID string) error
+	CountAssigned(ctx context.Context, checkerIDs []string) (map[string]int, error)
+	ListDue(ctx context.Context, now time.Time, excludeIDs []string, limit int) ([]model.Message, error)
+	RecordEscalation(ctx context.Context, messageID string, escalation *model.Escalation, nextCheckAt time.Time) (bool, error)
+	ListRecent(ctx context.Context, limit int) ([]model.Message, error)
+	ListUndelivered(ctx context.Context, now time.Time, limit int) ([]model.Message, error)
+	Deliver(ctx context.Context, messageID string, deliveredAt time.Time) (bool, error)
 }
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+type RevisionInterfaces interface {
+	Create(ctx context.Context, revision *model.MessageRevision) (*model.MessageRevision, error)
+	ListByMessageID(ctx context.Context, messageID string) ([]model.MessageRevision, error)
+}
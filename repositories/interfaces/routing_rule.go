@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+// RoutingRuleInterfaces stores the routing rules and every version of them.
+// Update returns a *model.VersionConflictError when the stored rule is no longer at the expected version.
+type RoutingRuleInterfaces interface {
+	Create(ctx context.Context, rule *model.RoutingRule) (*model.RoutingRule, error)
+	GetByID(ctx context.Context, ruleID string) (*model.RoutingRule, error)
+	List(ctx context.Context) ([]model.RoutingRule, error)
+	ListEnabled(ctx context.Context) ([]model.RoutingRule, error)
+	Update(ctx context.Context, rule *model.RoutingRule) error
+	Versions(ctx context.Context, ruleID string) ([]model.RoutingRule, error)
+}
@@ -13,4 +13,7 @@ type UserInterfaces interface {
 	GetByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*model.User, error)
 	Exists(ctx context.Context, usernameOrEmail string) (bool, error)
 	Delete(ctx context.Context, userID string) error
+	ListByRole(ctx context.Context, role string) ([]model.User, error)
+	// ListByIDs returns the users with the given ids in a single query, ids that match no user are left out
+	ListByIDs(ctx context.Context, userIDs []string) ([]model.User, error)
 }
@@ -7,11 +7,87 @@ import (
 )
 
 type messageMongo struct {
+	CreatedAt   time.Time            `bson:"created_at"`
+	DeletedAt   time.Time            `bson:"deleted_at"`
+	RecalledAt  time.Time            `bson:"recalled_at,omitempty"`
+	DeliverAt   time.Time            `bson:"deliver_at,omitempty"`
+	DeliveredAt time.Time            `bson:"delivered_at,omitempty"`
+	ID          primitive.ObjectID   `bson:"_id"`
+	SenderID    primitive.ObjectID   `bson:"sender_id"`
+	ReceiverID  primitive.ObjectID   `bson:"receiver_id,omitempty"`
+	Text        string               `bson:"text"`
+	Type        string               `bson:"type"`
+	Status      int                  `bson:"status"`
+	Revision    int                  `bson:"revision"`
+	Version     int                  `bson:"version"`
+	Steps       []approvalStepMongo  `bson:"steps"`
+	CurrentStep int                  `bson:"current_step"`
+	AssigneeID  primitive.ObjectID   `bson:"assignee_id,omitempty"`
+	Claim       *reviewClaimMongo    `bson:"claim,omitempty"`
+	SLA         *messageSLAMongo     `bson:"sla,omitempty"`
+	Escalations []escalationMongo    `bson:"escalations"`
+	Policy      *policyDecisionMongo `bson:"policy,omitempty"`
+	// Workflow is missing on messages stored before workflows, they follow the default workflow
+	Workflow *workflowRefMongo `bson:"workflow,omitempty"`
+	// Delivered is missing on messages stored before scheduled delivery, they count as delivered
+	Delivered *bool `bson:"delivered,omitempty"`
+}
+
+type messageSLAMongo struct {
+	DueAt           time.Time   `bson:"due_at"`
+	ExpiresAt       time.Time   `bson:"expires_at,omitempty"`
+	RemindAt        []time.Time `bson:"remind_at"`
+	NextCheckAt     time.Time   `bson:"next_check_at,omitempty"`
+	EscalationGroup string      `bson:"escalation_group,omitempty"`
+	Breached        bool        `bson:"breached"`
+}
+
+type escalationMongo struct {
 	CreatedAt  time.Time          `bson:"created_at"`
-	DeletedAt  time.Time          `bson:"deleted_at"`
-	ID         primitive.ObjectID `bson:"_id"`
-	SenderID   primitive.ObjectID `bson:"sender_id"`
-	ReceiverID primitive.ObjectID `bson:"receiver_id"`
-	Text       string             `bson:"text"`
+	DueAt      time.Time          `bson:"due_at"`
+	Level      string             `bson:"level"`
+	Group      string             `bson:"group,omitempty"`
+	AssigneeID primitive.ObjectID `bson:"assignee_id,omitempty"`
+	Step       int                `bson:"step"`
+}
+
+type reviewClaimMongo struct {
+	ClaimedAt time.Time          `bson:"claimed_at"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CheckerID primitive.ObjectID `bson:"checker_id"`
+}
+
+type approvalStepMongo struct {
+	Votes    []approvalDecisionMongo `bson:"votes"`
+	Checkers []primitive.ObjectID    `bson:"checkers,omitempty"`
+	Name     string                  `bson:"name"`
+	Role     string                  `bson:"role,omitempty"`
+	Group    string                  `bson:"group,omitempty"`
+	Quorum   int                     `bson:"quorum"`
+	// Rejections is left out on steps stored before it existed, they are rejected by one rejecting vote
+	Rejections int `bson:"rejections,omitempty"`
+	// EscalatedTo is set once the SLA of the step was breached
+	EscalatedTo string `bson:"escalated_to,omitempty"`
+}
+
+type approvalDecisionMongo struct {
+	DecidedAt  time.Time          `bson:"decided_at"`
+	CheckerID  primitive.ObjectID `bson:"checker_id"`
+	DelegateID primitive.ObjectID `bson:"delegate_id,omitempty"`
+	ReasonCode string             `bson:"reason_code,omitempty"`
+	Comment    string             `bson:"comment,omitempty"`
+	RuleID     string             `bson:"rule_id,omitempty"`
 	Status     int                `bson:"status"`
 }
+
+type policyDecisionMongo struct {
+	RuleID      string `bson:"rule_id"`
+	RuleVersion int    `bson:"rule_version,omitempty"`
+	Action      string `bson:"action"`
+	Group       string `bson:"group,omitempty"`
+}
+
+type workflowRefMongo struct {
+	Name    string `bson:"name"`
+	Version int    `bson:"version"`
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
 	"github.com/fleimkeipa/maker-checker/util"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -56,10 +59,607 @@ func (rc *MsgMongoRepo) Update(ctx context.Context, msgID string, message *model
 		return nil, fmt.Errorf("failed to convert message id: %w", err)
 	}
 
-	filter := bson.M{"_id": oID}
+	steps, err := rc.stepsToMongo(message.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert approval steps: %w", err)
+	}
+
+	// the stored message must still be at the version it was read in, in the status its workflow was checked against
+	filter := bson.M{
+		"_id":     oID,
+		"version": versionFilter(message.Version),
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"text":         message.Text,
+			"status":       message.Status,
+			"revision":     message.Revision,
+			"steps":        steps,
+			"current_step": message.CurrentStep,
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return nil, messageConflict(msgID, message.Version)
+	}
+
+	message.Version++
+
+	return message, nil
+}
+
+// AddVote records the vote and the outcome of the step in a single update and returns the message as it is stored
+// afterwards. It only applies while the message is at the given version, pending on the step of the vote and neither
+// the checker nor their delegate has voted on it yet, otherwise a *model.VersionConflictError is returned.
+func (rc *MsgMongoRepo) AddVote(ctx context.Context, vote *model.VoteWrite) (*model.Message, error) {
+	filter, update, err := rc.voteWrite(vote)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(messageMongo)
+	err = rc.
+		db.
+		Collection(msgColl).
+		FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, messageConflict(vote.MessageID, vote.Version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add vote: %w", err)
+	}
+
+	return rc.mongoToInternal(msg), nil
+}
+
+// AddVotes records the votes in a single bulk write, each under the same conditions as AddVote. A vote whose
+// conditions no longer hold is skipped without an error, read the messages again to see which votes were recorded.
+func (rc *MsgMongoRepo) AddVotes(ctx context.Context, votes []model.VoteWrite) error {
+	writes := make([]mongo.WriteModel, 0, len(votes))
+	for i := range votes {
+		filter, update, err := rc.voteWrite(&votes[i])
+		if err != nil {
+			return err
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update))
+	}
+
+	if _, err := rc.
+		db.
+		Collection(msgColl).
+		BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to add votes: %w", err)
+	}
+
+	return nil
+}
+
+// voteWrite is the filter and update of a vote, the checker is done with the message and their review lease ends with it.
+// The steps are written as a whole, which also stores the steps of a message from before approval chains existed.
+func (rc *MsgMongoRepo) voteWrite(vote *model.VoteWrite) (bson.M, bson.M, error) {
+	oID, err := primitive.ObjectIDFromHex(vote.MessageID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	mongoVote, err := voteToMongo(&vote.Vote)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	steps, err := rc.stepsToMongo(vote.Steps)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert approval steps: %w", err)
+	}
+
+	votesField := fmt.Sprintf("steps.%d.votes", vote.Step)
+	voters := bson.A{mongoVote.CheckerID}
+	if !mongoVote.DelegateID.IsZero() {
+		voters = append(voters, mongoVote.DelegateID)
+	}
+	filter := bson.M{
+		"_id":          oID,
+		"version":      versionFilter(vote.Version),
+		"status":       model.MessageStatusPending,
+		"current_step": vote.Step,
+		votesField + ".checker_id": bson.M{
+			"$nin": voters,
+		},
+		votesField + ".delegate_id": bson.M{
+			"$nin": voters,
+		},
+	}
+
+	set := bson.M{
+		"steps":        steps,
+		"status":       vote.Status,
+		"current_step": vote.NextStep,
+	}
+	unset := bson.M{
+		"claim": "",
+	}
+	if vote.Reassign {
+		assigneeID, err := optionalHexToObjectID(vote.AssigneeID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to convert assignee id: %w", err)
+		}
+		if assigneeID.IsZero() {
+			unset["assignee_id"] = ""
+		} else {
+			set["assignee_id"] = assigneeID
+		}
+	}
+
+	update := bson.M{
+		"$set":   set,
+		"$unset": unset,
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	return filter, update, nil
+}
+
+// Finalize moves a message that is still at the given version and pending on the given step to the new status and step.
+// Only the first caller wins, later callers get a *model.VersionConflictError.
+func (rc *MsgMongoRepo) Finalize(ctx context.Context, msgID string, version int, step int, status int, nextStep int) error {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":          oID,
+		"version":      versionFilter(version),
+		"status":       model.MessageStatusPending,
+		"current_step": step,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       status,
+			"current_step": nextStep,
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to finalize message: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return messageConflict(msgID, version)
+	}
+
+	return nil
+}
+
+// Withdraw moves a message that is still at the given version to withdrawn, and stamps DeletedAt. The message leaves
+// the review queues and the SLA checks, otherwise a *model.VersionConflictError is returned.
+func (rc *MsgMongoRepo) Withdraw(ctx context.Context, msgID string, version int, deletedAt time.Time) error {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":     oID,
+		"version": versionFilter(version),
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     model.MessageStatusWithdrawn,
+			"deleted_at": deletedAt,
+		},
+		"$unset": bson.M{
+			"claim":             "",
+			"assignee_id":       "",
+			"sla.next_check_at": "",
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to withdraw message: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return messageConflict(msgID, version)
+	}
+
+	return nil
+}
+
+// UpdateDraft replaces the receiver, text, type, delivery time and workflow of the draft at the message's version
+func (rc *MsgMongoRepo) UpdateDraft(ctx context.Context, message *model.Message) error {
+	oID, err := primitive.ObjectIDFromHex(message.ID)
+	if err != nil {
+		return fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	receiverID, err := optionalHexToObjectID(message.ReceiverID)
+	if err != nil {
+		return fmt.Errorf("failed to convert receiver id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":     oID,
+		"version": versionFilter(message.Version),
+		"status":  model.MessageStatusDraft,
+	}
+	set := bson.M{
+		"text":      message.Text,
+		"type":      message.Type,
+		"delivered": message.Delivered,
+	}
+	unset := bson.M{}
+	if receiverID.IsZero() {
+		unset["receiver_id"] = ""
+	} else {
+		set["receiver_id"] = receiverID
+	}
+	if message.DeliverAt.IsZero() {
+		unset["deliver_at"] = ""
+	} else {
+		set["deliver_at"] = message.DeliverAt
+	}
+	if workflow := workflowToMongo(message.Workflow); workflow != nil {
+		set["workflow"] = workflow
+	} else {
+		unset["workflow"] = ""
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update draft: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return messageConflict(message.ID, message.Version)
+	}
+
+	return nil
+}
+
+// Submit moves the draft at the message's version to the status of the message, pending or auto-accepted, with its workflow,
+// approval chain, revision, SLA, assignee and policy decision
+func (rc *MsgMongoRepo) Submit(ctx context.Context, message *model.Message) error {
+	oID, err := primitive.ObjectIDFromHex(message.ID)
+	if err != nil {
+		return fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	steps, err := rc.stepsToMongo(message.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to convert approval steps: %w", err)
+	}
+
+	assigneeID, err := optionalHexToObjectID(message.AssigneeID)
+	if err != nil {
+		return fmt.Errorf("failed to convert assignee id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":     oID,
+		"version": versionFilter(message.Version),
+		"status":  model.MessageStatusDraft,
+	}
+	set := bson.M{
+		"status":       message.Status,
+		"revision":     message.Revision,
+		"steps":        steps,
+		"current_step": message.CurrentStep,
+	}
+	if sla := slaToMongo(message.SLA); sla != nil {
+		set["sla"] = sla
+	}
+	if policy := policyToMongo(message.Policy); policy != nil {
+		set["policy"] = policy
+	}
+	if workflow := workflowToMongo(message.Workflow); workflow != nil {
+		set["workflow"] = workflow
+	}
+	if !assigneeID.IsZero() {
+		set["assignee_id"] = assigneeID
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to submit draft: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return messageConflict(message.ID, message.Version)
+	}
+
+	return nil
+}
+
+// Recall moves a message that is still at the given version to recalled, otherwise a *model.VersionConflictError is
+// returned. The text stays stored for the audit, the receiver only gets a tombstone.
+func (rc *MsgMongoRepo) Recall(ctx context.Context, msgID string, version int, recalledAt time.Time) (*model.Message, error) {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":     oID,
+		"version": versionFilter(version),
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      model.MessageStatusRecalled,
+			"recalled_at": recalledAt,
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	msg := new(messageMongo)
+	err = rc.
+		db.
+		Collection(msgColl).
+		FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, messageConflict(msgID, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to recall message: %w", err)
+	}
+
+	return rc.mongoToInternal(msg), nil
+}
+
+// ListRecent lists the most recently created messages that were submitted for review, the newest first
+func (rc *MsgMongoRepo) ListRecent(ctx context.Context, limit int) ([]model.Message, error) {
+	filter := bson.M{
+		"status": bson.M{"$ne": model.MessageStatusDraft},
+	}
+
+	mongoOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	msgs := make([]messageMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(msgColl).
+		Find(ctx, filter, mongoOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find messages: %w", err)
+	}
+
+	if err := cur.All(ctx, &msgs); err != nil {
+		return nil, fmt.Errorf("failed to decode messages: %w", err)
+	}
+
+	res := make([]model.Message, 0, len(msgs))
+	for _, v := range msgs {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+// ListUndelivered lists the accepted messages whose scheduled delivery time has come by the given time, the oldest first
+func (rc *MsgMongoRepo) ListUndelivered(ctx context.Context, now time.Time, limit int) ([]model.Message, error) {
+	filter := bson.M{
+		"status":     model.MessageStatusAccepted,
+		"delivered":  false,
+		"deliver_at": bson.M{"$lte": now},
+	}
+
+	mongoOptions := options.Find().
+		SetSort(bson.D{{Key: "deliver_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	msgs := make([]messageMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(msgColl).
+		Find(ctx, filter, mongoOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find messages: %w", err)
+	}
+
+	if err := cur.All(ctx, &msgs); err != nil {
+		return nil, fmt.Errorf("failed to decode messages: %w", err)
+	}
+
+	res := make([]model.Message, 0, len(msgs))
+	for _, v := range msgs {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+// Deliver marks the accepted message as delivered. It returns false if the message is not waiting for its delivery
+// anymore, so running workers never deliver it twice.
+func (rc *MsgMongoRepo) Deliver(ctx context.Context, msgID string, deliveredAt time.Time) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":       oID,
+		"status":    model.MessageStatusAccepted,
+		"delivered": false,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"delivered":    true,
+			"delivered_at": deliveredAt,
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to deliver message: %w", err)
+	}
+
+	return query.ModifiedCount > 0, nil
+}
+
+// ListQueue lists pending messages that the checker neither sent nor receives, oldest first.
+// Messages leased by another checker are left out until the lease expires.
+func (rc *MsgMongoRepo) ListQueue(ctx context.Context, opts model.ReviewQueueOpts) ([]model.Message, error) {
+	checkerID, err := primitive.ObjectIDFromHex(opts.CheckerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert checker id: %w", err)
+	}
+
+	filter := bson.M{
+		"status":      model.MessageStatusPending,
+		"sender_id":   bson.M{"$ne": checkerID},
+		"receiver_id": bson.M{"$ne": checkerID},
+		"$or":         claimableFilter(checkerID, time.Now()),
+	}
+
+	mongoOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(opts.Limit)).
+		SetSkip(int64(opts.Skip))
+
+	msgs := make([]messageMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(msgColl).
+		Find(ctx, filter, mongoOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find messages: %w", err)
+	}
+
+	if err := cur.All(ctx, &msgs); err != nil {
+		return nil, fmt.Errorf("failed to decode messages: %w", err)
+	}
+
+	res := make([]model.Message, 0, len(msgs))
+	for _, v := range msgs {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+// Claim takes or renews the checker's review lease on a pending message. It returns interfaces.ErrMessageClaimed
+// if the message is not pending or another checker holds an active lease.
+func (rc *MsgMongoRepo) Claim(ctx context.Context, msgID string, claim *model.ReviewClaim) (*model.Message, error) {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	checkerID, err := primitive.ObjectIDFromHex(claim.CheckerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert checker id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":    oID,
+		"status": model.MessageStatusPending,
+		"$or":    claimableFilter(checkerID, claim.ClaimedAt),
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"claim": reviewClaimMongo{
+				ClaimedAt: claim.ClaimedAt,
+				ExpiresAt: claim.ExpiresAt,
+				CheckerID: checkerID,
+			},
+		},
+	}
+
+	msg := new(messageMongo)
+	err = rc.
+		db.
+		Collection(msgColl).
+		FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(msg)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, interfaces.ErrMessageClaimed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim message: %w", err)
+	}
+
+	return rc.mongoToInternal(msg), nil
+}
+
+// Release ends the checker's review lease. It returns interfaces.ErrMessageClaimed if the checker holds no lease on the message.
+func (rc *MsgMongoRepo) Release(ctx context.Context, msgID string, checkerID string) error {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	checkerOID, err := primitive.ObjectIDFromHex(checkerID)
+	if err != nil {
+		return fmt.Errorf("failed to convert checker id: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":              oID,
+		"claim.checker_id": checkerOID,
+	}
 	update := bson.M{
-		"$set": bson.M{
-			"status": message.Status,
+		"$unset": bson.M{
+			"claim": "",
 		},
 	}
 	query, err := rc.
@@ -67,14 +667,213 @@ func (rc *MsgMongoRepo) Update(ctx context.Context, msgID string, message *model
 		Collection(msgColl).
 		UpdateOne(ctx, filter, update)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create message: %w", err)
+		return fmt.Errorf("failed to release message: %w", err)
 	}
 
 	if query.MatchedCount == 0 {
-		return nil, fmt.Errorf("not found message with id: %v", msgID)
+		return interfaces.ErrMessageClaimed
 	}
 
-	return message, nil
+	return nil
+}
+
+// Assign assigns a pending message to the checker, an empty checker id removes the assignment
+func (rc *MsgMongoRepo) Assign(ctx context.Context, msgID string, checkerID string) error {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	update := bson.M{
+		"$unset": bson.M{
+			"assignee_id": "",
+		},
+	}
+	if checkerID != "" {
+		checkerOID, err := primitive.ObjectIDFromHex(checkerID)
+		if err != nil {
+			return fmt.Errorf("failed to convert checker id: %w", err)
+		}
+		update = bson.M{
+			"$set": bson.M{
+				"assignee_id": checkerOID,
+			},
+		}
+	}
+
+	filter := bson.M{
+		"_id":    oID,
+		"status": model.MessageStatusPending,
+	}
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to assign message: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return fmt.Errorf("not found pending message with id: %v", msgID)
+	}
+
+	return nil
+}
+
+// CountAssigned counts the pending messages assigned to each of the checkers
+func (rc *MsgMongoRepo) CountAssigned(ctx context.Context, checkerIDs []string) (map[string]int, error) {
+	oIDs, err := hexToObjectIDs(checkerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert checker ids: %w", err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"status":      model.MessageStatusPending,
+			"assignee_id": bson.M{"$in": oIDs},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$assignee_id",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cur, err := rc.
+		db.
+		Collection(msgColl).
+		Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count assigned messages: %w", err)
+	}
+
+	var counts []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	if err := cur.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode assigned message counts: %w", err)
+	}
+
+	res := make(map[string]int, len(counts))
+	for _, v := range counts {
+		res[v.ID.Hex()] = v.Count
+	}
+
+	return res, nil
+}
+
+// ListDue lists the messages whose SLA needs to be looked at by the given time, the most overdue first.
+// Only pending messages have a next check, an expiry stops the checks. The excluded messages are left out.
+func (rc *MsgMongoRepo) ListDue(ctx context.Context, now time.Time, excludeIDs []string, limit int) ([]model.Message, error) {
+	excluded, err := hexToObjectIDs(excludeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert excluded message ids: %w", err)
+	}
+
+	filter := bson.M{
+		"status":            model.MessageStatusPending,
+		"sla.next_check_at": bson.M{"$lte": now},
+	}
+	if len(excluded) > 0 {
+		filter["_id"] = bson.M{"$nin": excluded}
+	}
+
+	mongoOptions := options.Find().
+		SetSort(bson.D{{Key: "sla.next_check_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	msgs := make([]messageMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(msgColl).
+		Find(ctx, filter, mongoOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find messages: %w", err)
+	}
+
+	if err := cur.All(ctx, &msgs); err != nil {
+		return nil, fmt.Errorf("failed to decode messages: %w", err)
+	}
+
+	res := make([]model.Message, 0, len(msgs))
+	for _, v := range msgs {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+// RecordEscalation appends the escalation to the message and moves its next SLA check, a zero time stops the checks.
+// A breach also hands the step over to the escalation group and its assignee. It returns false if the message
+// moved on from the step or the escalation was recorded already, so running schedulers never record it twice.
+func (rc *MsgMongoRepo) RecordEscalation(ctx context.Context, msgID string, esc *model.Escalation, nextCheckAt time.Time) (bool, error) {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	mongoEsc, err := escalationToMongo(esc)
+	if err != nil {
+		return false, err
+	}
+
+	// an expiry is recorded once the message expired, anything else while it is still pending
+	status := model.MessageStatusPending
+	if esc.Level == model.EscalationExpiry {
+		status = model.MessageStatusExpired
+	}
+
+	filter := bson.M{
+		"_id":          oID,
+		"status":       status,
+		"current_step": esc.Step,
+		"escalations": bson.M{
+			"$not": bson.M{
+				"$elemMatch": bson.M{
+					"level":  esc.Level,
+					"due_at": esc.DueAt,
+				},
+			},
+		},
+	}
+
+	set := bson.M{}
+	if !nextCheckAt.IsZero() {
+		set["sla.next_check_at"] = nextCheckAt
+	}
+	if esc.Level == model.EscalationBreach {
+		set["sla.breached"] = true
+		if esc.Group != "" {
+			set[fmt.Sprintf("steps.%d.escalated_to", esc.Step)] = esc.Group
+		}
+		if !mongoEsc.AssigneeID.IsZero() {
+			set["assignee_id"] = mongoEsc.AssigneeID
+		}
+	}
+
+	update := bson.M{
+		"$push": bson.M{
+			"escalations": mongoEsc,
+		},
+	}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if nextCheckAt.IsZero() {
+		update["$unset"] = bson.M{
+			"sla.next_check_at": "",
+		}
+	}
+
+	query, err := rc.
+		db.
+		Collection(msgColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to record escalation: %w", err)
+	}
+
+	return query.MatchedCount > 0, nil
 }
 
 func (rc *MsgMongoRepo) List(ctx context.Context, opts model.MessageFindOpts) ([]model.Message, error) {
@@ -124,15 +923,63 @@ func (rc *MsgMongoRepo) GetByID(ctx context.Context, msgID string) (*model.Messa
 	return rc.mongoToInternal(msg), nil
 }
 
+// ListByIDs returns the messages with the given ids, ids without a message are left out
+func (rc *MsgMongoRepo) ListByIDs(ctx context.Context, msgIDs []string) ([]model.Message, error) {
+	oIDs := make([]primitive.ObjectID, 0, len(msgIDs))
+	for _, v := range msgIDs {
+		oID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert message id: %w", err)
+		}
+		oIDs = append(oIDs, oID)
+	}
+
+	msgs := make([]messageMongo, 0, len(oIDs))
+	cur, err := rc.
+		db.
+		Collection(msgColl).
+		Find(ctx, bson.M{"_id": bson.M{"$in": oIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find messages: %w", err)
+	}
+
+	if err := cur.All(ctx, &msgs); err != nil {
+		return nil, fmt.Errorf("failed to decode messages: %w", err)
+	}
+
+	res := make([]model.Message, 0, len(msgs))
+	for _, v := range msgs {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
 func (rc *MsgMongoRepo) mongoToInternal(msg *messageMongo) *model.Message {
 	return &model.Message{
-		CreatedAt:  msg.CreatedAt,
-		DeletedAt:  msg.DeletedAt,
-		ID:         msg.ID.Hex(),
-		SenderID:   msg.SenderID.Hex(),
-		ReceiverID: msg.ReceiverID.Hex(),
-		Text:       msg.Text,
-		Status:     msg.Status,
+		CreatedAt:   msg.CreatedAt,
+		DeletedAt:   msg.DeletedAt,
+		RecalledAt:  msg.RecalledAt,
+		DeliverAt:   msg.DeliverAt,
+		DeliveredAt: msg.DeliveredAt,
+		ID:          msg.ID.Hex(),
+		SenderID:    msg.SenderID.Hex(),
+		ReceiverID:  optionalObjectIDToHex(msg.ReceiverID),
+		Text:        msg.Text,
+		Type:        msg.Type,
+		Status:      msg.Status,
+		Revision:    msg.Revision,
+		Version:     msg.Version,
+
+		Steps:       rc.stepsToInternal(msg.Steps),
+		CurrentStep: msg.CurrentStep,
+		AssigneeID:  optionalObjectIDToHex(msg.AssigneeID),
+		Claim:       claimToInternal(msg.Claim),
+		SLA:         slaToInternal(msg.SLA),
+		Escalations: escalationsToInternal(msg.Escalations),
+		Policy:      policyToInternal(msg.Policy),
+		Workflow:    workflowToInternal(msg.Workflow),
+		Delivered:   msg.Delivered == nil || *msg.Delivered,
 	}
 }
 
@@ -153,22 +1000,316 @@ func (rc *MsgMongoRepo) internalToMongo(msg *model.Message) (*messageMongo, erro
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert sender id: %w", err)
 	}
-	receiverID, err := primitive.ObjectIDFromHex(msg.ReceiverID)
+	receiverID, err := optionalHexToObjectID(msg.ReceiverID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert receiver id: %w", err)
 	}
+	steps, err := rc.stepsToMongo(msg.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert approval steps: %w", err)
+	}
+	var assigneeID primitive.ObjectID
+	if msg.AssigneeID != "" {
+		assigneeID, err = primitive.ObjectIDFromHex(msg.AssigneeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert assignee id: %w", err)
+		}
+	}
+	escalations := make([]escalationMongo, 0, len(msg.Escalations))
+	for _, v := range msg.Escalations {
+		esc, err := escalationToMongo(&v)
+		if err != nil {
+			return nil, err
+		}
+		escalations = append(escalations, *esc)
+	}
 
 	return &messageMongo{
-		CreatedAt:  msg.CreatedAt,
-		DeletedAt:  msg.DeletedAt,
-		ID:         mID,
-		SenderID:   senderID,
-		ReceiverID: receiverID,
-		Text:       msg.Text,
-		Status:     msg.Status,
+		CreatedAt:   msg.CreatedAt,
+		DeletedAt:   msg.DeletedAt,
+		RecalledAt:  msg.RecalledAt,
+		DeliverAt:   msg.DeliverAt,
+		DeliveredAt: msg.DeliveredAt,
+		ID:          mID,
+		SenderID:    senderID,
+		ReceiverID:  receiverID,
+		Text:        msg.Text,
+		Type:        msg.Type,
+		Status:      msg.Status,
+		Revision:    msg.Revision,
+		Version:     msg.Version,
+
+		Steps:       steps,
+		CurrentStep: msg.CurrentStep,
+		AssigneeID:  assigneeID,
+		SLA:         slaToMongo(msg.SLA),
+		Escalations: escalations,
+		Policy:      policyToMongo(msg.Policy),
+		Workflow:    workflowToMongo(msg.Workflow),
+		Delivered:   &msg.Delivered,
+	}, nil
+}
+
+func (rc *MsgMongoRepo) stepsToInternal(steps []approvalStepMongo) []model.ApprovalStep {
+	res := make([]model.ApprovalStep, 0, len(steps))
+	for _, v := range steps {
+		votes := make([]model.ApprovalDecision, 0, len(v.Votes))
+		for _, vote := range v.Votes {
+			votes = append(votes, *voteToInternal(&vote))
+		}
+
+		res = append(res, model.ApprovalStep{
+			Votes:    votes,
+			Checkers: objectIDsToHex(v.Checkers),
+			Name:     v.Name,
+			Role:     v.Role,
+			Group:    v.Group,
+			Quorum:   v.Quorum,
+
+			Rejections:  v.Rejections,
+			EscalatedTo: v.EscalatedTo,
+		})
+	}
+
+	return res
+}
+
+func (rc *MsgMongoRepo) stepsToMongo(steps []model.ApprovalStep) ([]approvalStepMongo, error) {
+	res := make([]approvalStepMongo, 0, len(steps))
+	for _, v := range steps {
+		votes := make([]approvalDecisionMongo, 0, len(v.Votes))
+		for _, vote := range v.Votes {
+			mongoVote, err := voteToMongo(&vote)
+			if err != nil {
+				return nil, err
+			}
+			votes = append(votes, *mongoVote)
+		}
+
+		checkers, err := hexToObjectIDs(v.Checkers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert checker ids: %w", err)
+		}
+
+		res = append(res, approvalStepMongo{
+			Votes:    votes,
+			Checkers: checkers,
+			Name:     v.Name,
+			Role:     v.Role,
+			Group:    v.Group,
+			Quorum:   v.Quorum,
+
+			Rejections:  v.Rejections,
+			EscalatedTo: v.EscalatedTo,
+		})
+	}
+
+	return res, nil
+}
+
+// claimableFilter matches messages without a review lease, with an expired lease, or leased by the checker
+func claimableFilter(checkerID primitive.ObjectID, now time.Time) []bson.M {
+	return []bson.M{
+		{"claim": nil},
+		{"claim.expires_at": bson.M{"$lte": now}},
+		{"claim.checker_id": checkerID},
+	}
+}
+
+func slaToInternal(sla *messageSLAMongo) *model.MessageSLA {
+	if sla == nil {
+		return nil
+	}
+
+	return &model.MessageSLA{
+		DueAt:           sla.DueAt,
+		ExpiresAt:       sla.ExpiresAt,
+		RemindAt:        sla.RemindAt,
+		NextCheckAt:     sla.NextCheckAt,
+		EscalationGroup: sla.EscalationGroup,
+		Breached:        sla.Breached,
+	}
+}
+
+func slaToMongo(sla *model.MessageSLA) *messageSLAMongo {
+	if sla == nil {
+		return nil
+	}
+
+	return &messageSLAMongo{
+		DueAt:           sla.DueAt,
+		ExpiresAt:       sla.ExpiresAt,
+		RemindAt:        sla.RemindAt,
+		NextCheckAt:     sla.NextCheckAt,
+		EscalationGroup: sla.EscalationGroup,
+		Breached:        sla.Breached,
+	}
+}
+
+func escalationsToInternal(escalations []escalationMongo) []model.Escalation {
+	res := make([]model.Escalation, 0, len(escalations))
+	for _, v := range escalations {
+		res = append(res, model.Escalation{
+			CreatedAt:  v.CreatedAt,
+			DueAt:      v.DueAt,
+			Level:      v.Level,
+			Group:      v.Group,
+			AssigneeID: optionalObjectIDToHex(v.AssigneeID),
+			Step:       v.Step,
+		})
+	}
+
+	return res
+}
+
+func escalationToMongo(esc *model.Escalation) (*escalationMongo, error) {
+	var assigneeID primitive.ObjectID
+	if esc.AssigneeID != "" {
+		var err error
+		assigneeID, err = primitive.ObjectIDFromHex(esc.AssigneeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert assignee id: %w", err)
+		}
+	}
+
+	return &escalationMongo{
+		CreatedAt:  esc.CreatedAt,
+		DueAt:      esc.DueAt,
+		Level:      esc.Level,
+		Group:      esc.Group,
+		AssigneeID: assigneeID,
+		Step:       esc.Step,
+	}, nil
+}
+
+func claimToInternal(claim *reviewClaimMongo) *model.ReviewClaim {
+	if claim == nil {
+		return nil
+	}
+
+	return &model.ReviewClaim{
+		ClaimedAt: claim.ClaimedAt,
+		ExpiresAt: claim.ExpiresAt,
+		CheckerID: claim.CheckerID.Hex(),
+	}
+}
+
+// versionFilter matches the expected message version. Messages stored before versioning have no
+// version field and count as version 0.
+func versionFilter(version int) any {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+
+	return version
+}
+
+func messageConflict(msgID string, version int) *model.VersionConflictError {
+	return &model.VersionConflictError{
+		Resource: model.AuditResourceMessage,
+		ID:       msgID,
+		Version:  version,
+	}
+}
+
+func voteToInternal(vote *approvalDecisionMongo) *model.ApprovalDecision {
+	// system decisions are stored without a checker
+	checkerID := model.SystemActorID
+	if !vote.CheckerID.IsZero() {
+		checkerID = vote.CheckerID.Hex()
+	}
+
+	return &model.ApprovalDecision{
+		DecidedAt:  vote.DecidedAt,
+		CheckerID:  checkerID,
+		DelegateID: optionalObjectIDToHex(vote.DelegateID),
+		ReasonCode: vote.ReasonCode,
+		Comment:    vote.Comment,
+		RuleID:     vote.RuleID,
+		Status:     vote.Status,
+	}
+}
+
+func voteToMongo(vote *model.ApprovalDecision) (*approvalDecisionMongo, error) {
+	var checkerID primitive.ObjectID
+	if vote.CheckerID != model.SystemActorID {
+		var err error
+		checkerID, err = primitive.ObjectIDFromHex(vote.CheckerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert checker id: %w", err)
+		}
+	}
+	delegateID, err := optionalHexToObjectID(vote.DelegateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delegate id: %w", err)
+	}
+
+	return &approvalDecisionMongo{
+		DecidedAt:  vote.DecidedAt,
+		CheckerID:  checkerID,
+		DelegateID: delegateID,
+		ReasonCode: vote.ReasonCode,
+		Comment:    vote.Comment,
+		RuleID:     vote.RuleID,
+		Status:     vote.Status,
 	}, nil
 }
 
+func policyToInternal(policy *policyDecisionMongo) *model.PolicyDecision {
+	if policy == nil {
+		return nil
+	}
+
+	return &model.PolicyDecision{
+		RuleID:      policy.RuleID,
+		RuleVersion: policy.RuleVersion,
+		Action:      policy.Action,
+		Group:       policy.Group,
+	}
+}
+
+func policyToMongo(policy *model.PolicyDecision) *policyDecisionMongo {
+	if policy == nil {
+		return nil
+	}
+
+	return &policyDecisionMongo{
+		RuleID:      policy.RuleID,
+		RuleVersion: policy.RuleVersion,
+		Action:      policy.Action,
+		Group:       policy.Group,
+	}
+}
+
+func workflowToInternal(workflow *workflowRefMongo) *model.WorkflowRef {
+	if workflow == nil {
+		return nil
+	}
+
+	return &model.WorkflowRef{
+		Name:    workflow.Name,
+		Version: workflow.Version,
+	}
+}
+
+func workflowToMongo(workflow *model.WorkflowRef) *workflowRefMongo {
+	if workflow == nil {
+		return nil
+	}
+
+	return &workflowRefMongo{
+		Name:    workflow.Name,
+		Version: workflow.Version,
+	}
+}
+
+// receiverStatuses are the statuses of the messages a receiver sees, recalled messages are shown as tombstones
+var receiverStatuses = bson.A{model.MessageStatusAccepted, model.MessageStatusRecalled}
+
+// receiverDelivered leaves out the messages waiting for their scheduled delivery, messages stored before
+// scheduled delivery have no delivered field and count as delivered
+var receiverDelivered = bson.M{"$ne": false}
+
 func (rc *MsgMongoRepo) listFilters(ctx context.Context, opts model.MessageFindOpts) bson.M {
 	filter := bson.M{}
 	if opts.ReceiverID.IsSended {
@@ -177,13 +1318,29 @@ func (rc *MsgMongoRepo) listFilters(ctx context.Context, opts model.MessageFindO
 			return nil
 		}
 		filter["receiver_id"] = oID
-		filter["status"] = model.MessageStatusAccepted
+		filter["status"] = bson.M{"$in": receiverStatuses}
+		filter["delivered"] = receiverDelivered
 	} else if opts.SenderID.IsSended {
 		oID, err := primitive.ObjectIDFromHex(opts.SenderID.Value)
 		if err != nil {
 			return nil
 		}
 		filter["sender_id"] = oID
+		// drafts are private to their sender until they are submitted, and the receiver only sees what was delivered
+		if callerID := util.GetOwnerIDFromCtx(ctx); opts.SenderID.Value != callerID {
+			callerOID, err := primitive.ObjectIDFromHex(callerID)
+			if err != nil {
+				return nil
+			}
+			filter["status"] = bson.M{"$ne": model.MessageStatusDraft}
+			filter["$or"] = []bson.M{
+				{"receiver_id": bson.M{"$ne": callerOID}},
+				{
+					"status":    bson.M{"$in": receiverStatuses},
+					"delivered": receiverDelivered,
+				},
+			}
+		}
 	} else {
 		oID, err := primitive.ObjectIDFromHex(util.GetOwnerIDFromCtx(ctx))
 		if err != nil {
@@ -194,7 +1351,8 @@ func (rc *MsgMongoRepo) listFilters(ctx context.Context, opts model.MessageFindO
 			{
 				"$and": []bson.M{
 					{"receiver_id": oID},
-					{"status": model.MessageStatusAccepted},
+					{"status": bson.M{"$in": receiverStatuses}},
+					{"delivered": receiverDelivered},
 				},
 			},
 		}
@@ -207,7 +1365,11 @@ func (rc *MsgMongoRepo) listFilters(ctx context.Context, opts model.MessageFindO
 		}
 		filter["sender_id"] = oID
 
-		filter["status"] = opts.Status.Value
+		status, err := strconv.Atoi(opts.Status.Value)
+		if err != nil {
+			return nil
+		}
+		filter["status"] = status
 	}
 
 	return filter
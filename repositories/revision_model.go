@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type revisionMongo struct {
+	CreatedAt        time.Time              `bson:"created_at"`
+	ChangesRequested *approvalDecisionMongo `bson:"changes_requested,omitempty"`
+	ID               primitive.ObjectID     `bson:"_id"`
+	MessageID        primitive.ObjectID     `bson:"message_id"`
+	AuthorID         primitive.ObjectID     `bson:"author_id"`
+	Text             string                 `bson:"text"`
+	Diff             []diffLineMongo        `bson:"diff"`
+	Number           int                    `bson:"number"`
+}
+
+type diffLineMongo struct {
+	Op   string `bson:"op"`
+	Text string `bson:"text"`
+}
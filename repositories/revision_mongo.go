@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fleimkeipa/maker-checker/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RevisionMongoRepo struct {
+	db *mongo.Database
+}
+
+func NewRevisionMongoRepo(db *mongo.Database) *RevisionMongoRepo {
+	return &RevisionMongoRepo{
+		db: db,
+	}
+}
+
+var revisionColl = "message_revisions"
+
+func (rc *RevisionMongoRepo) Create(ctx context.Context, revision *model.MessageRevision) (*model.MessageRevision, error) {
+	mongoRevision, err := rc.internalToMongo(revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert revision: %w", err)
+	}
+
+	query, err := rc.
+		db.
+		Collection(revisionColl).
+		InsertOne(ctx, mongoRevision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revision: %w", err)
+	}
+
+	oid, ok := query.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("can't get inserted ID")
+	}
+
+	revision.ID = oid.Hex()
+
+	return revision, nil
+}
+
+// ListByMessageID returns every revision of the message, oldest first
+func (rc *RevisionMongoRepo) ListByMessageID(ctx context.Context, msgID string) ([]model.MessageRevision, error) {
+	oID, err := primitive.ObjectIDFromHex(msgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert message id: %w", err)
+	}
+
+	mongoOptions := options.Find().
+		SetSort(bson.M{"number": 1})
+
+	revisions := make([]revisionMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(revisionColl).
+		Find(ctx, bson.M{"message_id": oID}, mongoOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find revisions: %w", err)
+	}
+
+	if err := cur.All(ctx, &revisions); err != nil {
+		return nil, fmt.Errorf("failed to decode revisions: %w", err)
+	}
+
+	res := make([]model.MessageRevision, 0, len(revisions))
+	for _, v := range revisions {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+func (rc *RevisionMongoRepo) mongoToInternal(revision *revisionMongo) *model.MessageRevision {
+	diff := make([]model.DiffLine, 0, len(revision.Diff))
+	for _, v := range revision.Diff {
+		diff = append(diff, model.DiffLine{Op: v.Op, Text: v.Text})
+	}
+
+	var changesRequested *model.ApprovalDecision
+	if revision.ChangesRequested != nil {
+		changesRequested = voteToInternal(revision.ChangesRequested)
+	}
+
+	return &model.MessageRevision{
+		CreatedAt:        revision.CreatedAt,
+		ChangesRequested: changesRequested,
+		ID:               revision.ID.Hex(),
+		MessageID:        revision.MessageID.Hex(),
+		AuthorID:         revision.AuthorID.Hex(),
+		Text:             revision.Text,
+		Diff:             diff,
+		Number:           revision.Number,
+	}
+}
+
+func (rc *RevisionMongoRepo) internalToMongo(revision *model.MessageRevision) (*revisionMongo, error) {
+	messageID, err := primitive.ObjectIDFromHex(revision.MessageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert message id: %w", err)
+	}
+	authorID, err := primitive.ObjectIDFromHex(revision.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert author id: %w", err)
+	}
+
+	var changesRequested *approvalDecisionMongo
+	if revision.ChangesRequested != nil {
+		changesRequested, err = voteToMongo(revision.ChangesRequested)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diff := make([]diffLineMongo, 0, len(revision.Diff))
+	for _, v := range revision.Diff {
+		diff = append(diff, diffLineMongo{Op: v.Op, Text: v.Text})
+	}
+
+	return &revisionMongo{
+		CreatedAt:        revision.CreatedAt,
+		ChangesRequested: changesRequested,
+		ID:               primitive.NewObjectID(),
+		MessageID:        messageID,
+		AuthorID:         authorID,
+		Text:             revision.Text,
+		Diff:             diff,
+		Number:           revision.Number,
+	}, nil
+}
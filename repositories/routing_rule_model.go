@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type routingRuleMongo struct {
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+	ID         primitive.ObjectID `bson:"_id"`
+	Name       string             `bson:"name"`
+	Expression string             `bson:"expression"`
+	Action     string             `bson:"action"`
+	Group      string             `bson:"group,omitempty"`
+	Priority   int                `bson:"priority"`
+	Version    int                `bson:"version"`
+	Disabled   bool               `bson:"disabled"`
+	CreatedBy  primitive.ObjectID `bson:"created_by"`
+	UpdatedBy  primitive.ObjectID `bson:"updated_by"`
+}
+
+// routingRuleVersionMongo is the snapshot of a routing rule at one of its versions
+type routingRuleVersionMongo struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Rule routingRuleMongo   `bson:"rule"`
+}
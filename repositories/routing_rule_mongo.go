@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fleimkeipa/maker-checker/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RoutingRuleMongoRepo struct {
+	db *mongo.Database
+}
+
+func NewRoutingRuleMongoRepo(db *mongo.Database) *RoutingRuleMongoRepo {
+	return &RoutingRuleMongoRepo{
+		db: db,
+	}
+}
+
+var (
+	routingRuleColl        = "routing_rules"
+	routingRuleVersionColl = "routing_rule_versions"
+)
+
+// routingRuleOrder evaluates the rules by priority, and rules of the same priority in the order they were created
+var routingRuleOrder = bson.D{{Key: "priority", Value: 1}, {Key: "_id", Value: 1}}
+
+// Create stores the rule and its first version
+func (rc *RoutingRuleMongoRepo) Create(ctx context.Context, rule *model.RoutingRule) (*model.RoutingRule, error) {
+	mongoRule, err := rc.internalToMongo(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert routing rule: %w", err)
+	}
+	mongoRule.ID = primitive.NewObjectID()
+
+	_, err = rc.
+		db.
+		Collection(routingRuleColl).
+		InsertOne(ctx, mongoRule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create routing rule: %w", err)
+	}
+
+	if err := rc.storeVersion(ctx, mongoRule); err != nil {
+		return nil, err
+	}
+
+	return rc.mongoToInternal(mongoRule), nil
+}
+
+func (rc *RoutingRuleMongoRepo) GetByID(ctx context.Context, ruleID string) (*model.RoutingRule, error) {
+	oID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert routing rule id: %w", err)
+	}
+
+	rule := new(routingRuleMongo)
+	err = rc.
+		db.
+		Collection(routingRuleColl).
+		FindOne(ctx, bson.M{"_id": oID}).
+		Decode(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return rc.mongoToInternal(rule), nil
+}
+
+// List lists every rule in the order they are evaluated in
+func (rc *RoutingRuleMongoRepo) List(ctx context.Context) ([]model.RoutingRule, error) {
+	return rc.find(ctx, bson.M{})
+}
+
+// ListEnabled lists the rules that are not disabled, in the order they are evaluated in
+func (rc *RoutingRuleMongoRepo) ListEnabled(ctx context.Context) ([]model.RoutingRule, error) {
+	return rc.find(ctx, bson.M{"disabled": false})
+}
+
+// Update replaces the rule at its version and stores the new version
+func (rc *RoutingRuleMongoRepo) Update(ctx context.Context, rule *model.RoutingRule) error {
+	mongoRule, err := rc.internalToMongo(rule)
+	if err != nil {
+		return fmt.Errorf("failed to convert routing rule: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":     mongoRule.ID,
+		"version": rule.Version,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"updated_at": mongoRule.UpdatedAt,
+			"name":       mongoRule.Name,
+			"expression": mongoRule.Expression,
+			"action":     mongoRule.Action,
+			"group":      mongoRule.Group,
+			"priority":   mongoRule.Priority,
+			"disabled":   mongoRule.Disabled,
+			"updated_by": mongoRule.UpdatedBy,
+		},
+		"$inc": bson.M{
+			"version": 1,
+		},
+	}
+
+	query, err := rc.
+		db.
+		Collection(routingRuleColl).
+		UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update routing rule: %w", err)
+	}
+
+	if query.MatchedCount == 0 {
+		return &model.VersionConflictError{
+			Resource: model.AuditResourceRoutingRule,
+			ID:       rule.ID,
+			Version:  rule.Version,
+		}
+	}
+
+	mongoRule.Version++
+
+	return rc.storeVersion(ctx, mongoRule)
+}
+
+// Versions lists every version of the rule, oldest first
+func (rc *RoutingRuleMongoRepo) Versions(ctx context.Context, ruleID string) ([]model.RoutingRule, error) {
+	oID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert routing rule id: %w", err)
+	}
+
+	versions := make([]routingRuleVersionMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(routingRuleVersionColl).
+		Find(ctx, bson.M{"rule._id": oID}, options.Find().SetSort(bson.M{"rule.version": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find routing rule versions: %w", err)
+	}
+
+	if err := cur.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("failed to decode routing rule versions: %w", err)
+	}
+
+	res := make([]model.RoutingRule, 0, len(versions))
+	for _, v := range versions {
+		res = append(res, *rc.mongoToInternal(&v.Rule))
+	}
+
+	return res, nil
+}
+
+func (rc *RoutingRuleMongoRepo) storeVersion(ctx context.Context, rule *routingRuleMongo) error {
+	_, err := rc.
+		db.
+		Collection(routingRuleVersionColl).
+		InsertOne(ctx, routingRuleVersionMongo{
+			ID:   primitive.NewObjectID(),
+			Rule: *rule,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to store routing rule version: %w", err)
+	}
+
+	return nil
+}
+
+func (rc *RoutingRuleMongoRepo) find(ctx context.Context, filter bson.M) ([]model.RoutingRule, error) {
+	rules := make([]routingRuleMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(routingRuleColl).
+		Find(ctx, filter, options.Find().SetSort(routingRuleOrder))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find routing rules: %w", err)
+	}
+
+	if err := cur.All(ctx, &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode routing rules: %w", err)
+	}
+
+	res := make([]model.RoutingRule, 0, len(rules))
+	for _, v := range rules {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+func (rc *RoutingRuleMongoRepo) mongoToInternal(rule *routingRuleMongo) *model.RoutingRule {
+	return &model.RoutingRule{
+		CreatedAt:  rule.CreatedAt,
+		UpdatedAt:  rule.UpdatedAt,
+		ID:         rule.ID.Hex(),
+		Name:       rule.Name,
+		Expression: rule.Expression,
+		Action:     rule.Action,
+		Group:      rule.Group,
+		Priority:   rule.Priority,
+		Version:    rule.Version,
+		Disabled:   rule.Disabled,
+		CreatedBy:  optionalObjectIDToHex(rule.CreatedBy),
+		UpdatedBy:  optionalObjectIDToHex(rule.UpdatedBy),
+	}
+}
+
+func (rc *RoutingRuleMongoRepo) internalToMongo(rule *model.RoutingRule) (*routingRuleMongo, error) {
+	ruleID, err := optionalHexToObjectID(rule.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert routing rule id: %w", err)
+	}
+	createdBy, err := optionalHexToObjectID(rule.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert creator id: %w", err)
+	}
+	updatedBy, err := optionalHexToObjectID(rule.UpdatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert updater id: %w", err)
+	}
+
+	return &routingRuleMongo{
+		CreatedAt:  rule.CreatedAt,
+		UpdatedAt:  rule.UpdatedAt,
+		ID:         ruleID,
+		Name:       rule.Name,
+		Expression: rule.Expression,
+		Action:     rule.Action,
+		Group:      rule.Group,
+		Priority:   rule.Priority,
+		Version:    rule.Version,
+		Disabled:   rule.Disabled,
+		CreatedBy:  createdBy,
+		UpdatedBy:  updatedBy,
+	}, nil
+}
@@ -12,5 +12,10 @@ type userMongo struct {
 	Username  string             `bson:"username"`
 	Email     string             `bson:"email"`
 	Password  string             `bson:"password"`
+	Role      string             `bson:"role"`
 	ID        primitive.ObjectID `bson:"_id"`
+	// RelatedUserIDs are the users with a conflict of interest
+	RelatedUserIDs []primitive.ObjectID `bson:"related_user_ids"`
+	Groups         []string             `bson:"groups"`
+	Team           string               `bson:"team,omitempty"`
 }
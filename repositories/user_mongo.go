@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserMongoRepo struct {
@@ -55,6 +56,8 @@ func (rc *UserMongoRepo) Update(ctx context.Context, userID string, user *model.
 		return nil, fmt.Errorf("failed to convert id: %w", err)
 	}
 
+	// the document id can't change
+	user.ID = userID
 	updateUser, err := rc.internalToMongo(user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert user: %w", err)
@@ -68,7 +71,7 @@ func (rc *UserMongoRepo) Update(ctx context.Context, userID string, user *model.
 	query, err := rc.
 		db.
 		Collection(userColl).
-		UpdateOne(ctx, updateDocs, filter)
+		UpdateOne(ctx, filter, updateDocs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -177,7 +180,67 @@ func (rc *UserMongoRepo) Exists(ctx context.Context, usernameOrEmail string) (bo
 	return false, nil
 }
 
+// ListByRole lists the users with the given role that are not deleted, ordered by id
+func (rc *UserMongoRepo) ListByRole(ctx context.Context, role string) ([]model.User, error) {
+	filter := bson.M{
+		"role":       role,
+		"deleted_at": bson.M{"$in": bson.A{time.Time{}, nil}},
+	}
+
+	users := make([]userMongo, 0)
+	cur, err := rc.
+		db.
+		Collection(userColl).
+		Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+
+	if err := cur.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode users: %w", err)
+	}
+
+	res := make([]model.User, 0, len(users))
+	for _, v := range users {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
+func (rc *UserMongoRepo) ListByIDs(ctx context.Context, userIDs []string) ([]model.User, error) {
+	oIDs, err := hexToObjectIDs(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert user ids: %w", err)
+	}
+
+	users := make([]userMongo, 0, len(oIDs))
+	cur, err := rc.
+		db.
+		Collection(userColl).
+		Find(ctx, bson.M{"_id": bson.M{"$in": oIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+
+	if err := cur.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode users: %w", err)
+	}
+
+	res := make([]model.User, 0, len(users))
+	for _, v := range users {
+		res = append(res, *rc.mongoToInternal(&v))
+	}
+
+	return res, nil
+}
+
 func (rc *UserMongoRepo) mongoToInternal(u *userMongo) *model.User {
+	// users stored before roles were introduced are treated as makers
+	if u.Role == "" {
+		u.Role = model.RoleMaker
+	}
+
 	return &model.User{
 		CreatedAt: u.CreatedAt,
 		DeletedAt: u.DeletedAt,
@@ -185,6 +248,11 @@ func (rc *UserMongoRepo) mongoToInternal(u *userMongo) *model.User {
 		Username:  u.Username,
 		Email:     u.Email,
 		Password:  u.Password,
+		Role:      u.Role,
+
+		RelatedUserIDs: objectIDsToHex(u.RelatedUserIDs),
+		Groups:         u.Groups,
+		Team:           u.Team,
 	}
 }
 
@@ -201,6 +269,11 @@ func (rc *UserMongoRepo) internalToMongo(u *model.User) (*userMongo, error) {
 		oID = primitive.NewObjectID()
 	}
 
+	relatedUserIDs, err := hexToObjectIDs(u.RelatedUserIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert related user ids: %w", err)
+	}
+
 	return &userMongo{
 		CreatedAt: u.CreatedAt,
 		DeletedAt: u.DeletedAt,
@@ -208,5 +281,10 @@ func (rc *UserMongoRepo) internalToMongo(u *model.User) (*userMongo, error) {
 		Username:  u.Username,
 		Email:     u.Email,
 		Password:  u.Password,
+		Role:      u.Role,
+
+		RelatedUserIDs: relatedUserIDs,
+		Groups:         u.Groups,
+		Team:           u.Team,
 	}, nil
 }
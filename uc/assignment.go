@@ -0,0 +1,205 @@
+package uc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+)
+
+// AssignmentStrategy picks the checker a message is assigned to among the eligible candidates.
+// Candidates are never empty and ordered by id.
+type AssignmentStrategy interface {
+	Pick(ctx context.Context, message *model.Message, candidates []model.User) (*model.User, error)
+}
+
+// NewAssignmentStrategy returns the named strategy, or nil for model.AssignmentNone
+func NewAssignmentStrategy(name string, msgRepo interfaces.MessageInterfaces, userRepo interfaces.UserInterfaces) (AssignmentStrategy, error) {
+	switch name {
+	case model.AssignmentNone, "":
+		return nil, nil
+	case model.AssignmentRoundRobin:
+		return &RoundRobinStrategy{}, nil
+	case model.AssignmentLeastLoaded:
+		return NewLeastLoadedStrategy(msgRepo), nil
+	case model.AssignmentSenderTeam:
+		return NewSenderTeamStrategy(userRepo, NewLeastLoadedStrategy(msgRepo)), nil
+	}
+
+	return nil, fmt.Errorf("unknown assignment strategy %q", name)
+}
+
+// Assigner assigns the current approval step of a message to one of the checkers allowed to decide on it
+type Assigner struct {
+	userRepo  interfaces.UserInterfaces
+	conflicts *ConflictRuleEngine
+	strategy  AssignmentStrategy
+}
+
+// NewAssigner returns an assigner that picks checkers with the strategy, a nil strategy leaves messages unassigned
+func NewAssigner(userRepo interfaces.UserInterfaces, conflicts *ConflictRuleEngine, strategy AssignmentStrategy) *Assigner {
+	return &Assigner{
+		userRepo:  userRepo,
+		conflicts: conflicts,
+		strategy:  strategy,
+	}
+}
+
+// Assign returns the checker id the current step of the message is assigned to,
+// or an empty string if there is no strategy or no eligible checker
+func (rc *Assigner) Assign(ctx context.Context, message *model.Message) (string, error) {
+	if rc == nil || rc.strategy == nil || message.CurrentStep >= len(message.Steps) {
+		return "", nil
+	}
+
+	candidates, err := rc.Candidates(ctx, message)
+	if err != nil {
+		return "", err
+	}
+
+	return rc.AssignAmong(ctx, message, candidates)
+}
+
+// AssignAmong returns the checker id the strategy picks among the given candidates,
+// or an empty string if there is no strategy or no candidate
+func (rc *Assigner) AssignAmong(ctx context.Context, message *model.Message, candidates []model.User) (string, error) {
+	if rc == nil || rc.strategy == nil || len(candidates) == 0 {
+		return "", nil
+	}
+
+	checker, err := rc.strategy.Pick(ctx, message, candidates)
+	if err != nil {
+		return "", err
+	}
+
+	return checker.ID, nil
+}
+
+// Candidates lists the checkers that may decide on the current step of the message
+func (rc *Assigner) Candidates(ctx context.Context, message *model.Message) ([]model.User, error) {
+	checkers, err := rc.userRepo.ListByRole(ctx, model.RoleChecker)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to list checkers", http.StatusInternalServerError)
+	}
+
+	candidates := make([]model.User, 0, len(checkers))
+	for _, checker := range checkers {
+		ok, err := rc.isEligible(ctx, &checker, message)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			candidates = append(candidates, checker)
+		}
+	}
+
+	return candidates, nil
+}
+
+// isEligible reports whether the checker may vote on the current step, without conflicts of interest.
+// Only unexpected failures are returned as errors.
+func (rc *Assigner) isEligible(ctx context.Context, checker *model.User, message *model.Message) (bool, error) {
+	if message.CurrentStep >= len(message.Steps) || !message.Steps[message.CurrentStep].IsEligible(checker) {
+		return false, nil
+	}
+
+	// a checker can sign off only one step of the chain
+	for _, v := range message.Steps[:message.CurrentStep+1] {
+		if v.HasVoted(checker.ID) {
+			return false, nil
+		}
+	}
+
+	if err := rc.conflicts.Check(ctx, checker.ID, message); err != nil {
+		return false, unlessInternal(err)
+	}
+
+	return true, nil
+}
+
+// RoundRobinStrategy takes turns among the candidates. The turn is kept in memory, so it restarts with the server.
+type RoundRobinStrategy struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (rc *RoundRobinStrategy) Pick(_ context.Context, _ *model.Message, candidates []model.User) (*model.User, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	checker := &candidates[rc.next%len(candidates)]
+	rc.next++
+
+	return checker, nil
+}
+
+// LeastLoadedStrategy picks the candidate with the fewest pending messages assigned, the first one on a tie
+type LeastLoadedStrategy struct {
+	msgRepo interfaces.MessageInterfaces
+}
+
+func NewLeastLoadedStrategy(msgRepo interfaces.MessageInterfaces) *LeastLoadedStrategy {
+	return &LeastLoadedStrategy{
+		msgRepo: msgRepo,
+	}
+}
+
+func (rc *LeastLoadedStrategy) Pick(ctx context.Context, _ *model.Message, candidates []model.User) (*model.User, error) {
+	ids := make([]string, 0, len(candidates))
+	for _, v := range candidates {
+		ids = append(ids, v.ID)
+	}
+
+	loads, err := rc.msgRepo.CountAssigned(ctx, ids)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to count assigned messages", http.StatusInternalServerError)
+	}
+
+	least := &candidates[0]
+	for i := range candidates[1:] {
+		if loads[candidates[i+1].ID] < loads[least.ID] {
+			least = &candidates[i+1]
+		}
+	}
+
+	return least, nil
+}
+
+// SenderTeamStrategy narrows the candidates to the checkers sharing a group with the sender, and lets the next
+// strategy pick among them. All candidates are passed on if nobody is on the sender's team.
+type SenderTeamStrategy struct {
+	userRepo interfaces.UserInterfaces
+	next     AssignmentStrategy
+}
+
+func NewSenderTeamStrategy(userRepo interfaces.UserInterfaces, next AssignmentStrategy) *SenderTeamStrategy {
+	return &SenderTeamStrategy{
+		userRepo: userRepo,
+		next:     next,
+	}
+}
+
+func (rc *SenderTeamStrategy) Pick(ctx context.Context, message *model.Message, candidates []model.User) (*model.User, error) {
+	sender, err := rc.userRepo.GetByID(ctx, message.SenderID)
+	if err != nil {
+		return nil, pkg.NewError(err, "message sender not found", http.StatusNotFound)
+	}
+
+	team := make([]model.User, 0, len(candidates))
+	for _, v := range candidates {
+		if slices.ContainsFunc(sender.Groups, v.InGroup) {
+			team = append(team, v)
+		}
+	}
+
+	if len(team) == 0 {
+		team = candidates
+	}
+
+	return rc.next.Pick(ctx, message, team)
+}
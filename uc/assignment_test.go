@@ -0,0 +1,308 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestRoundRobinStrategyTakesTurns(t *testing.T) {
+	candidates := []model.User{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	strategy := &RoundRobinStrategy{}
+
+	for i, want := range []string{"a", "b", "c", "a", "b"} {
+		got, err := strategy.Pick(context.Background(), &model.Message{}, candidates)
+		if err != nil {
+			t.Fatalf("pick %d: %v", i, err)
+		}
+		if got.ID != want {
+			t.Errorf("pick %d = %s, want %s", i, got.ID, want)
+		}
+	}
+}
+
+func TestLeastLoadedStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		assigned map[string][]int
+		want     string
+	}{
+		{
+			name: "no load picks the first candidate",
+			want: "a",
+		},
+		{
+			name:     "picks the checker with the fewest pending assignments",
+			assigned: map[string][]int{"a": {model.MessageStatusPending, model.MessageStatusPending}, "b": {model.MessageStatusPending}},
+			want:     "c",
+		},
+		{
+			name:     "decided messages don't count",
+			assigned: map[string][]int{"a": {model.MessageStatusAccepted, model.MessageStatusRejected}, "b": {model.MessageStatusPending}, "c": {model.MessageStatusPending}},
+			want:     "a",
+		},
+		{
+			name:     "a tie picks the first of the least loaded",
+			assigned: map[string][]int{"a": {model.MessageStatusPending}},
+			want:     "b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := newMemoryMessageRepo()
+			for checkerID, statuses := range tt.assigned {
+				for _, status := range statuses {
+					messages.Create(context.Background(), &model.Message{AssigneeID: checkerID, Status: status})
+				}
+			}
+
+			got, err := NewLeastLoadedStrategy(messages).Pick(context.Background(), &model.Message{}, []model.User{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+			if err != nil {
+				t.Fatalf("pick: %v", err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("pick = %s, want %s", got.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestSenderTeamStrategy(t *testing.T) {
+	tests := []struct {
+		name         string
+		senderGroups []string
+		want         string
+	}{
+		{name: "picks a checker of the sender's team", senderGroups: []string{"finance"}, want: "b"},
+		{name: "any of the sender's teams", senderGroups: []string{"legal", "hr"}, want: "c"},
+		{name: "falls back to every candidate", senderGroups: []string{"sales"}, want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMemoryUserRepo()
+			sender, _ := users.Create(context.Background(), &model.User{Role: model.RoleMaker, Groups: tt.senderGroups})
+			candidates := []model.User{
+				{ID: "a", Groups: []string{"it"}},
+				{ID: "b", Groups: []string{"finance"}},
+				{ID: "c", Groups: []string{"hr"}},
+			}
+
+			strategy := NewSenderTeamStrategy(users, NewLeastLoadedStrategy(newMemoryMessageRepo()))
+			got, err := strategy.Pick(context.Background(), &model.Message{SenderID: sender.ID}, candidates)
+			if err != nil {
+				t.Fatalf("pick: %v", err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("pick = %s, want %s", got.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAssignmentStrategy(t *testing.T) {
+	for _, name := range []string{model.AssignmentRoundRobin, model.AssignmentLeastLoaded, model.AssignmentSenderTeam} {
+		strategy, err := NewAssignmentStrategy(name, newMemoryMessageRepo(), newMemoryUserRepo())
+		if err != nil || strategy == nil {
+			t.Errorf("NewAssignmentStrategy(%q) = %v, %v", name, strategy, err)
+		}
+	}
+
+	if strategy, err := NewAssignmentStrategy(model.AssignmentNone, nil, nil); err != nil || strategy != nil {
+		t.Errorf("NewAssignmentStrategy(none) = %v, %v, want no strategy", strategy, err)
+	}
+
+	if _, err := NewAssignmentStrategy("random", nil, nil); err == nil {
+		t.Error("NewAssignmentStrategy(random) succeeded, want an error")
+	}
+}
+
+func TestCreateAssignsEligibleCheckers(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentRoundRobin, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	first := f.user(t, model.RoleChecker)
+	receiver := f.user(t, model.RoleChecker)
+	related := f.user(t, model.RoleChecker)
+	second := f.user(t, model.RoleChecker)
+	f.user(t, model.RoleAdmin)
+
+	sender.RelatedUserIDs = []string{related.ID}
+	f.users.Update(context.Background(), sender.ID, sender)
+
+	// the receiver and the related checker are never assigned, the others take turns
+	want := []string{first.ID, second.ID, first.ID}
+	for i := range want {
+		message := f.send(t, sender, receiver, "")
+		if message.AssigneeID != want[i] {
+			t.Errorf("message %d assigned to %s, want %s", i, message.AssigneeID, want[i])
+		}
+
+		stored, err := f.msgUC.GetByID(context.Background(), message.ID)
+		if err != nil {
+			t.Fatalf("get message: %v", err)
+		}
+		if stored.AssigneeID != message.AssigneeID {
+			t.Errorf("stored message %d assigned to %s, want %s", i, stored.AssigneeID, message.AssigneeID)
+		}
+	}
+}
+
+func TestCreateWithoutEligibleCheckerLeavesMessageUnassigned(t *testing.T) {
+	chains := model.ApprovalChains{
+		model.DefaultMessageType: {{Name: "review", Role: model.RoleChecker, Group: "compliance"}},
+	}
+	f := newMsgFixture(t, model.AssignmentLeastLoaded, chains)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	f.user(t, model.RoleChecker, "finance")
+
+	message := f.send(t, sender, receiver, "")
+	if message.AssigneeID != "" {
+		t.Errorf("message assigned to %s, want unassigned", message.AssigneeID)
+	}
+}
+
+func TestCreateWithoutStrategyLeavesMessageUnassigned(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	f.user(t, model.RoleChecker)
+
+	message := f.send(t, sender, receiver, "")
+	if message.AssigneeID != "" {
+		t.Errorf("message assigned to %s, want unassigned", message.AssigneeID)
+	}
+}
+
+func TestNextStepIsReassigned(t *testing.T) {
+	chains := model.ApprovalChains{
+		model.DefaultMessageType: {
+			{Name: "team lead", Role: model.RoleChecker, Group: "team-leads"},
+			{Name: "compliance", Role: model.RoleChecker, Group: "compliance"},
+		},
+	}
+	f := newMsgFixture(t, model.AssignmentLeastLoaded, chains)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	lead := f.user(t, model.RoleChecker, "team-leads")
+	officer := f.user(t, model.RoleChecker, "compliance")
+
+	message := f.send(t, sender, receiver, "")
+	if message.AssigneeID != lead.ID {
+		t.Fatalf("first step assigned to %s, want %s", message.AssigneeID, lead.ID)
+	}
+
+	updated, err := f.msgUC.Update(ownerCtx(lead), message.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted})
+	if err != nil {
+		t.Fatalf("approve first step: %v", err)
+	}
+
+	if updated.CurrentStep != 1 || updated.AssigneeID != officer.ID {
+		t.Errorf("after the first step: step %d assigned to %s, want step 1 assigned to %s", updated.CurrentStep, updated.AssigneeID, officer.ID)
+	}
+
+	stored, _ := f.messages.GetByID(context.Background(), message.ID)
+	if stored.AssigneeID != officer.ID {
+		t.Errorf("stored assignee %s, want %s", stored.AssigneeID, officer.ID)
+	}
+}
+
+func TestAdminReassign(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentLeastLoaded, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleChecker)
+	first := f.user(t, model.RoleChecker)
+	second := f.user(t, model.RoleChecker)
+	maker := f.user(t, model.RoleMaker)
+	admin := f.user(t, model.RoleAdmin)
+
+	message := f.send(t, sender, receiver, "")
+	if message.AssigneeID != first.ID {
+		t.Fatalf("message assigned to %s, want %s", message.AssigneeID, first.ID)
+	}
+
+	decided := f.send(t, sender, receiver, "")
+	if _, err := f.msgUC.Update(ownerCtx(&model.User{ID: decided.AssigneeID, Role: model.RoleChecker}), decided.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+		t.Fatalf("accept message: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		messageID  string
+		checkerID  string
+		wantStatus int
+	}{
+		{name: "another eligible checker", messageID: message.ID, checkerID: second.ID},
+		{name: "the receiver", messageID: message.ID, checkerID: receiver.ID, wantStatus: http.StatusForbidden},
+		{name: "a maker", messageID: message.ID, checkerID: maker.ID, wantStatus: http.StatusUnprocessableEntity},
+		{name: "an unknown user", messageID: message.ID, checkerID: "unknown", wantStatus: http.StatusNotFound},
+		{name: "a decided message", messageID: decided.ID, checkerID: second.ID, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.msgUC.Assign(ownerCtx(admin), tt.messageID, &model.MessageAssignRequest{CheckerID: tt.checkerID})
+			if tt.wantStatus != 0 {
+				if statusCode(err) != tt.wantStatus {
+					t.Fatalf("assign error = %v (status %d), want status %d", err, statusCode(err), tt.wantStatus)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("assign: %v", err)
+			}
+			if got.AssigneeID != tt.checkerID {
+				t.Errorf("assignee = %s, want %s", got.AssigneeID, tt.checkerID)
+			}
+
+			stored, _ := f.messages.GetByID(context.Background(), tt.messageID)
+			if stored.AssigneeID != tt.checkerID {
+				t.Errorf("stored assignee = %s, want %s", stored.AssigneeID, tt.checkerID)
+			}
+		})
+	}
+}
+
+func TestQueueHidesMessagesAssignedToOthers(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentRoundRobin, nil)
+	reviews := NewReviewUC(f.msgUC, time.Minute)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	first := f.user(t, model.RoleChecker)
+	second := f.user(t, model.RoleChecker)
+
+	mine := f.send(t, sender, receiver, "")
+	theirs := f.send(t, sender, receiver, "")
+	if mine.AssigneeID != first.ID || theirs.AssigneeID != second.ID {
+		t.Fatalf("messages assigned to %s and %s, want %s and %s", mine.AssigneeID, theirs.AssigneeID, first.ID, second.ID)
+	}
+
+	queue, err := reviews.Queue(ownerCtx(first), model.PaginationOpts{Limit: 10})
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+
+	if len(queue) != 1 || queue[0].ID != mine.ID {
+		t.Errorf("queue = %v, want only message %s", messageIDs(queue), mine.ID)
+	}
+}
+
+func messageIDs(messages []model.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, v := range messages {
+		ids = append(ids, v.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,145 @@
+package uc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+	"github.com/fleimkeipa/maker-checker/util"
+)
+
+// auditAppendAttempts bounds the retries when concurrent appends race for the same sequence
+const auditAppendAttempts = 10
+
+// auditVerifyBatch is the number of records read at once while verifying the chain
+const auditVerifyBatch = 500
+
+type AuditUC struct {
+	auditRepo interfaces.AuditInterfaces
+}
+
+func NewAuditUC(repo interfaces.AuditInterfaces) *AuditUC {
+	return &AuditUC{
+		auditRepo: repo,
+	}
+}
+
+// Record appends the event to the hash-chained audit trail
+func (rc *AuditUC) Record(ctx context.Context, event model.AuditEvent) error {
+	before, err := json.Marshal(event.Before)
+	if err != nil {
+		return pkg.NewError(err, "failed to encode audit snapshot", http.StatusInternalServerError)
+	}
+
+	after, err := json.Marshal(event.After)
+	if err != nil {
+		return pkg.NewError(err, "failed to encode audit snapshot", http.StatusInternalServerError)
+	}
+
+	actorID := event.ActorID
+	if actorID == "" {
+		actorID = util.GetOwnerIDFromCtx(ctx)
+	}
+	if actorID == "" {
+		actorID = model.SystemActorID
+	}
+
+	record := model.AuditRecord{
+		// mongo stores milliseconds, the hashed time must survive the round trip
+		CreatedAt:    time.Now().UTC().Truncate(time.Millisecond),
+		Before:       before,
+		After:        after,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		ActorID:      actorID,
+		OnBehalfOfID: event.OnBehalfOfID,
+		RequestID:    util.GetRequestIDFromCtx(ctx),
+		ClientIP:     util.GetClientIPFromCtx(ctx),
+	}
+
+	for range auditAppendAttempts {
+		last, err := rc.auditRepo.Last(ctx)
+		if err != nil {
+			return pkg.NewError(err, "failed to read audit trail", http.StatusInternalServerError)
+		}
+
+		record.Sequence, record.PrevHash = 1, ""
+		if last != nil {
+			record.Sequence, record.PrevHash = last.Sequence+1, last.Hash
+		}
+		record.Hash = record.ComputeHash()
+
+		_, err = rc.auditRepo.Create(ctx, &record)
+		if errors.Is(err, interfaces.ErrAuditSequenceTaken) {
+			continue
+		}
+		if err != nil {
+			return pkg.NewError(err, "failed to write audit record", http.StatusInternalServerError)
+		}
+
+		return nil
+	}
+
+	return pkg.NewError(nil, "failed to write audit record, too many concurrent changes", http.StatusServiceUnavailable)
+}
+
+// Verify walks the whole audit trail and reports the first record that breaks the hash chain
+func (rc *AuditUC) Verify(ctx context.Context) (*model.AuditVerification, error) {
+	result := model.AuditVerification{Valid: true}
+
+	var prev *model.AuditRecord
+	for {
+		var after int64
+		if prev != nil {
+			after = prev.Sequence
+		}
+
+		records, err := rc.auditRepo.ListAfter(ctx, after, auditVerifyBatch)
+		if err != nil {
+			return nil, pkg.NewError(err, "failed to read audit trail", http.StatusInternalServerError)
+		}
+
+		for i := range records {
+			record := &records[i]
+			if reason := brokenLink(prev, record); reason != "" {
+				result.Valid = false
+				result.BrokenAt = record
+				result.Reason = reason
+				return &result, nil
+			}
+
+			result.Checked++
+			prev = record
+		}
+
+		if len(records) < auditVerifyBatch {
+			return &result, nil
+		}
+	}
+}
+
+// brokenLink explains why the record does not follow the previous one, or returns an empty string
+func brokenLink(prev, record *model.AuditRecord) string {
+	expectedSequence, expectedPrevHash := int64(1), ""
+	if prev != nil {
+		expectedSequence, expectedPrevHash = prev.Sequence+1, prev.Hash
+	}
+
+	switch {
+	case record.Sequence != expectedSequence:
+		return fmt.Sprintf("expected sequence %d, found %d: a record was removed", expectedSequence, record.Sequence)
+	case record.PrevHash != expectedPrevHash:
+		return "previous hash does not match the previous record"
+	case record.Hash != record.ComputeHash():
+		return "hash does not match the record content: the record was modified"
+	}
+
+	return ""
+}
@@ -0,0 +1,132 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+)
+
+var (
+	ErrCheckerIsSender   = errors.New("checker is the sender of the message")
+	ErrCheckerIsReceiver = errors.New("checker is the receiver of the message")
+	ErrCheckerIsRelated  = errors.New("checker is related to the sender of the message")
+)
+
+// ConflictRule checks a single conflict-of-interest condition between a checker and a message.
+type ConflictRule interface {
+	Check(ctx context.Context, checkerID string, message *model.Message) error
+}
+
+// UserConflictRule is implemented by rules that decide on the stored checker and sender, so that callers that
+// already loaded them don't load them again
+type UserConflictRule interface {
+	CheckUsers(checker, sender *model.User) error
+}
+
+// ConflictRuleEngine enforces segregation of duties by running every rule before a checker may decide on a message.
+type ConflictRuleEngine struct {
+	rules []ConflictRule
+}
+
+func NewConflictRuleEngine(rules ...ConflictRule) *ConflictRuleEngine {
+	return &ConflictRuleEngine{
+		rules: rules,
+	}
+}
+
+// DefaultConflictRules returns the rules that block the sender, the receiver and users related to the sender.
+func DefaultConflictRules(userRepo interfaces.UserInterfaces) []ConflictRule {
+	return []ConflictRule{
+		SenderConflictRule{},
+		ReceiverConflictRule{},
+		NewRelatedUsersConflictRule(userRepo),
+	}
+}
+
+// Check returns the first violated rule's error, or nil if the checker has no conflict of interest.
+func (rc *ConflictRuleEngine) Check(ctx context.Context, checkerID string, message *model.Message) error {
+	for _, rule := range rc.rules {
+		if err := rule.Check(ctx, checkerID, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckUsers is Check for a checker and a sender that are already loaded
+func (rc *ConflictRuleEngine) CheckUsers(ctx context.Context, checker, sender *model.User, message *model.Message) error {
+	for _, rule := range rc.rules {
+		var err error
+		if userRule, ok := rule.(UserConflictRule); ok {
+			err = userRule.CheckUsers(checker, sender)
+		} else {
+			err = rule.Check(ctx, checker.ID, message)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SenderConflictRule blocks the maker from checking their own message.
+type SenderConflictRule struct{}
+
+func (SenderConflictRule) Check(_ context.Context, checkerID string, message *model.Message) error {
+	if checkerID == message.SenderID {
+		return pkg.NewErrorWithReason(ErrCheckerIsSender, "you cannot check a message you sent", model.ConflictReasonSender, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// ReceiverConflictRule blocks the receiver from checking a message addressed to them.
+type ReceiverConflictRule struct{}
+
+func (ReceiverConflictRule) Check(_ context.Context, checkerID string, message *model.Message) error {
+	if checkerID == message.ReceiverID {
+		return pkg.NewErrorWithReason(ErrCheckerIsReceiver, "you cannot check a message addressed to you", model.ConflictReasonReceiver, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// RelatedUsersConflictRule blocks checkers that appear on the sender's related users list, or the other way around.
+type RelatedUsersConflictRule struct {
+	userRepo interfaces.UserInterfaces
+}
+
+func NewRelatedUsersConflictRule(userRepo interfaces.UserInterfaces) *RelatedUsersConflictRule {
+	return &RelatedUsersConflictRule{
+		userRepo: userRepo,
+	}
+}
+
+func (rc *RelatedUsersConflictRule) Check(ctx context.Context, checkerID string, message *model.Message) error {
+	checker, err := rc.userRepo.GetByID(ctx, checkerID)
+	if err != nil {
+		return pkg.NewError(err, "checker not found", http.StatusNotFound)
+	}
+
+	sender, err := rc.userRepo.GetByID(ctx, message.SenderID)
+	if err != nil {
+		return pkg.NewError(err, "message sender not found", http.StatusNotFound)
+	}
+
+	return rc.CheckUsers(checker, sender)
+}
+
+func (rc *RelatedUsersConflictRule) CheckUsers(checker, sender *model.User) error {
+	if slices.Contains(checker.RelatedUserIDs, sender.ID) || slices.Contains(sender.RelatedUserIDs, checker.ID) {
+		return pkg.NewErrorWithReason(ErrCheckerIsRelated, "you cannot check a message sent by a related user", model.ConflictReasonRelated, http.StatusForbidden)
+	}
+
+	return nil
+}
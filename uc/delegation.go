@@ -0,0 +1,139 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+	"github.com/fleimkeipa/maker-checker/util"
+)
+
+// DelegationUC manages the delegations checkers give to a substitute while they are out of office
+type DelegationUC struct {
+	repo      interfaces.DelegationInterfaces
+	userRepo  interfaces.UserInterfaces
+	audit     *AuditUC
+	workflows *Workflows
+}
+
+func NewDelegationUC(repo interfaces.DelegationInterfaces, userRepo interfaces.UserInterfaces, audit *AuditUC, workflows *Workflows) *DelegationUC {
+	return &DelegationUC{
+		repo:      repo,
+		userRepo:  userRepo,
+		audit:     audit,
+		workflows: workflows,
+	}
+}
+
+// Create lets the caller's delegate decide on messages on the caller's behalf during the window.
+// A window without a start starts now.
+func (rc *DelegationUC) Create(ctx context.Context, req *model.DelegationCreateRequest) (*model.Delegation, error) {
+	now := time.Now()
+	delegatorID := util.GetOwnerIDFromCtx(ctx)
+
+	if req.DelegateID == "" {
+		return nil, pkg.NewError(nil, "delegate id is required", http.StatusBadRequest)
+	}
+
+	if req.DelegateID == delegatorID {
+		return nil, pkg.NewError(nil, "you can't delegate to yourself", http.StatusUnprocessableEntity)
+	}
+
+	startsAt := req.StartsAt
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+
+	if !req.EndsAt.After(startsAt) || !req.EndsAt.After(now) {
+		return nil, pkg.NewError(nil, "ends_at must be in the future and after starts_at", http.StatusBadRequest)
+	}
+
+	for _, v := range req.MessageTypes {
+		if !rc.workflows.HasMessageType(v) {
+			return nil, pkg.NewError(errors.New("unknown message type: "+v), "message type has no workflow or approval chain", http.StatusBadRequest)
+		}
+	}
+
+	delegate, err := rc.userRepo.GetByID(ctx, req.DelegateID)
+	if err != nil || !delegate.DeletedAt.IsZero() {
+		return nil, pkg.NewError(err, "delegate not found", http.StatusNotFound)
+	}
+
+	if delegate.Role != model.RoleChecker {
+		return nil, pkg.NewError(nil, "only checkers can be delegates", http.StatusUnprocessableEntity)
+	}
+
+	delegation := model.Delegation{
+		CreatedAt:    now,
+		StartsAt:     startsAt,
+		EndsAt:       req.EndsAt,
+		DelegatorID:  delegatorID,
+		DelegateID:   req.DelegateID,
+		MessageTypes: req.MessageTypes,
+	}
+
+	newDelegation, err := rc.repo.Create(ctx, &delegation)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to create delegation", http.StatusInternalServerError)
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionDelegationCreate,
+		ResourceType: model.AuditResourceDelegation,
+		ResourceID:   newDelegation.ID,
+		After:        newDelegation,
+	}); err != nil {
+		return nil, err
+	}
+
+	return newDelegation, nil
+}
+
+// List lists the delegations the caller gave or received, newest first
+func (rc *DelegationUC) List(ctx context.Context) ([]model.Delegation, error) {
+	delegations, err := rc.repo.ListByUser(ctx, util.GetOwnerIDFromCtx(ctx))
+	if err != nil {
+		return nil, pkg.NewError(err, "delegations not found", http.StatusNotFound)
+	}
+
+	return delegations, nil
+}
+
+// Revoke ends one of the caller's delegations right away
+func (rc *DelegationUC) Revoke(ctx context.Context, delegationID string) (*model.Delegation, error) {
+	delegation, err := rc.repo.GetByID(ctx, delegationID)
+	if err != nil {
+		return nil, pkg.NewError(err, "delegation not found", http.StatusNotFound)
+	}
+
+	if delegation.DelegatorID != util.GetOwnerIDFromCtx(ctx) {
+		return nil, pkg.NewError(nil, "only the delegator can revoke a delegation", http.StatusForbidden)
+	}
+
+	if !delegation.RevokedAt.IsZero() {
+		return nil, pkg.NewError(nil, "delegation is already revoked", http.StatusConflict)
+	}
+
+	before := *delegation
+	delegation.RevokedAt = time.Now()
+
+	if err := rc.repo.Revoke(ctx, delegationID, delegation.RevokedAt); err != nil {
+		return nil, pkg.NewError(err, "failed to revoke delegation", http.StatusInternalServerError)
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionDelegationRevoke,
+		ResourceType: model.AuditResourceDelegation,
+		ResourceID:   delegationID,
+		Before:       &before,
+		After:        delegation,
+	}); err != nil {
+		return nil, err
+	}
+
+	return delegation, nil
+}
@@ -0,0 +1,71 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestDelegateInheritsTheSegregationOfDutiesOfTheDelegator(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	receiver := f.user(t, model.RoleMaker)
+	delegator := f.user(t, model.RoleChecker)
+	delegate := f.user(t, model.RoleChecker)
+
+	relatedToDelegate := f.user(t, model.RoleMaker)
+	relatedToDelegator := f.user(t, model.RoleMaker)
+	unrelated := f.user(t, model.RoleMaker)
+	delegate.RelatedUserIDs = []string{relatedToDelegate.ID}
+	delegator.RelatedUserIDs = []string{relatedToDelegator.ID}
+	for _, v := range []*model.User{delegate, delegator} {
+		if _, err := f.users.Update(context.Background(), v.ID, v); err != nil {
+			t.Fatalf("update user: %v", err)
+		}
+	}
+
+	if _, err := f.delegations.Create(context.Background(), &model.Delegation{
+		DelegatorID: delegator.ID,
+		DelegateID:  delegate.ID,
+		StartsAt:    time.Now().Add(-time.Hour),
+		EndsAt:      time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("create delegation: %v", err)
+	}
+
+	accept := &model.MessageUpdateRequest{Status: model.MessageStatusAccepted, OnBehalfOf: delegator.ID}
+
+	for _, tc := range []struct {
+		name   string
+		sender *model.User
+	}{
+		{"own message of the delegate", delegate},
+		{"message of a user related to the delegate", relatedToDelegate},
+		{"message of a user related to the delegator", relatedToDelegator},
+	} {
+		message := f.send(t, tc.sender, receiver, "")
+		if _, err := f.msgUC.Update(ownerCtx(delegate), message.ID, accept); statusCode(err) != http.StatusForbidden {
+			t.Errorf("%s: status %d, want %d", tc.name, statusCode(err), http.StatusForbidden)
+		}
+
+		stored, err := f.messages.GetByID(context.Background(), message.ID)
+		if err != nil {
+			t.Fatalf("get message: %v", err)
+		}
+		if stored.Status != model.MessageStatusPending || len(stored.Steps[0].Votes) != 0 {
+			t.Errorf("%s: status %s with votes %+v, want pending without votes", tc.name, model.StatusName(stored.Status), stored.Steps[0].Votes)
+		}
+	}
+
+	message := f.send(t, unrelated, receiver, "")
+	accepted, err := f.msgUC.Update(ownerCtx(delegate), message.ID, accept)
+	if err != nil {
+		t.Fatalf("unrelated message: %v", err)
+	}
+	if votes := accepted.Steps[0].Votes; len(votes) != 1 || votes[0].CheckerID != delegator.ID || votes[0].DelegateID != delegate.ID {
+		t.Errorf("unrelated message: votes %+v, want the delegator's vote cast by the delegate", votes)
+	}
+}
@@ -0,0 +1,106 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+)
+
+// deliveryBatch is the number of messages read at once by the delivery worker
+const deliveryBatch = 100
+
+// DeliveryWorker delivers the accepted messages whose scheduled delivery time has come: it marks them as delivered,
+// which shows them to their receiver, and notifies the receiver. A message is delivered at most once, so several
+// workers can run side by side.
+type DeliveryWorker struct {
+	messages *MsgUC
+	notifier Notifier
+	interval time.Duration
+}
+
+func NewDeliveryWorker(messages *MsgUC, notifier Notifier, interval time.Duration) *DeliveryWorker {
+	return &DeliveryWorker{
+		messages: messages,
+		notifier: notifier,
+		interval: interval,
+	}
+}
+
+// Run delivers the due messages on every interval until the context is done, failed deliveries are passed to onError
+func (rc *DeliveryWorker) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(rc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := rc.Tick(ctx, now); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Tick delivers the messages that are due at the given time
+func (rc *DeliveryWorker) Tick(ctx context.Context, now time.Time) error {
+	for {
+		messages, err := rc.messages.msgRepo.ListUndelivered(ctx, now, deliveryBatch)
+		if err != nil {
+			return pkg.NewError(err, "failed to list undelivered messages", http.StatusInternalServerError)
+		}
+
+		var errs []error
+		for i := range messages {
+			if err := rc.deliver(ctx, &messages[i], now); err != nil {
+				errs = append(errs, fmt.Errorf("message %s: %w", messages[i].ID, err))
+			}
+		}
+
+		// failed messages stay undelivered, they are retried on the next tick
+		if len(errs) > 0 || len(messages) < deliveryBatch {
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// deliver marks the message as delivered, records the delivery in the audit trail and notifies the receiver
+func (rc *DeliveryWorker) deliver(ctx context.Context, message *model.Message, now time.Time) error {
+	delivered, err := rc.messages.msgRepo.Deliver(ctx, message.ID, now)
+	if err != nil {
+		return pkg.NewError(err, "failed to deliver message", http.StatusInternalServerError)
+	}
+	if !delivered {
+		return nil
+	}
+
+	after := *message
+	after.Delivered = true
+	after.DeliveredAt = now
+	after.Version++
+
+	if err := rc.messages.audit.Record(ctx, model.AuditEvent{
+		ActorID:      model.SystemActorID,
+		Action:       model.AuditActionMessageDeliver,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   message.ID,
+		Before:       message,
+		After:        &after,
+	}); err != nil {
+		return err
+	}
+
+	return rc.notifier.Notify(ctx, model.Notification{
+		CreatedAt:    now,
+		MessageID:    message.ID,
+		Kind:         model.NotificationDelivery,
+		RecipientIDs: []string{message.ReceiverID},
+		Text:         "message scheduled for " + message.DeliverAt.Format(time.RFC3339) + " was delivered",
+	})
+}
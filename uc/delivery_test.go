@@ -0,0 +1,146 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestDeliveryTickOnlyDeliversAcceptedMessagesThatAreDue(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	notifier := &recordingNotifier{}
+	worker := NewDeliveryWorker(f.msgUC, notifier, time.Minute)
+
+	now := time.Now()
+	schedule := func(deliverAt time.Time, accept bool) *model.Message {
+		t.Helper()
+
+		message, err := f.msgUC.Create(ownerCtx(sender), &model.MessageCreateRequest{
+			ReceiverID: receiver.ID,
+			Text:       "hello",
+			DeliverAt:  &deliverAt,
+		})
+		if err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+
+		if accept {
+			if _, err := f.msgUC.Update(ownerCtx(checker), message.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+				t.Fatalf("accept: %v", err)
+			}
+		}
+
+		return message
+	}
+
+	due := schedule(now.Add(time.Hour), true)
+	later := schedule(now.Add(2*time.Hour), true)
+	inReview := schedule(now.Add(time.Hour), false)
+	immediate := f.send(t, sender, receiver, "")
+	if _, err := f.msgUC.Update(ownerCtx(checker), immediate.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+
+	// an accepted message waiting for its delivery time is hidden from the receiver
+	if _, err := f.msgUC.GetByID(ownerCtx(receiver), due.ID); statusCode(err) != http.StatusNotFound {
+		t.Errorf("before delivery: status %d, want %d", statusCode(err), http.StatusNotFound)
+	}
+
+	tickAt := now.Add(90 * time.Minute)
+	if err := worker.Tick(context.Background(), tickAt); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+
+	if len(notifier.notifications) != 1 || notifier.notifications[0].MessageID != due.ID ||
+		notifier.notifications[0].Kind != model.NotificationDelivery {
+		t.Fatalf("notifications %+v, want the delivery of the due message only", notifier.notifications)
+	}
+
+	for _, v := range []struct {
+		message   *model.Message
+		delivered bool
+	}{
+		{due, true},
+		{later, false},
+		{inReview, false},
+		{immediate, true},
+	} {
+		stored, err := f.messages.GetByID(context.Background(), v.message.ID)
+		if err != nil {
+			t.Fatalf("get message: %v", err)
+		}
+		if stored.Delivered != v.delivered {
+			t.Errorf("message %s: delivered %t, want %t", v.message.ID, stored.Delivered, v.delivered)
+		}
+	}
+
+	if got, err := f.msgUC.GetByID(ownerCtx(receiver), due.ID); err != nil || !got.Delivered {
+		t.Errorf("after delivery: %+v, %v, want the delivered message", got, err)
+	}
+
+	// a delivered message is not delivered again, a message accepted after its time is delivered on the next tick
+	if _, err := f.msgUC.Update(ownerCtx(checker), inReview.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if err := worker.Tick(context.Background(), tickAt.Add(time.Minute)); err != nil {
+		t.Fatalf("second tick: %v", err)
+	}
+	if len(notifier.notifications) != 2 || notifier.notifications[1].MessageID != inReview.ID {
+		t.Errorf("notifications %+v, want one more for the late accepted message", notifier.notifications)
+	}
+}
+
+func TestReceiverListingBySenderOnlyShowsDeliveredMessages(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	accept := func(message *model.Message) {
+		t.Helper()
+
+		if _, err := f.msgUC.Update(ownerCtx(checker), message.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+			t.Fatalf("accept: %v", err)
+		}
+	}
+
+	delivered := f.send(t, sender, receiver, "")
+	accept(delivered)
+
+	deliverAt := time.Now().Add(time.Hour)
+	scheduled, err := f.msgUC.Create(ownerCtx(sender), &model.MessageCreateRequest{ReceiverID: receiver.ID, Text: "later", DeliverAt: &deliverAt})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	accept(scheduled)
+
+	pending := f.send(t, sender, receiver, "")
+
+	opts := model.MessageFindOpts{SenderID: model.Filter{IsSended: true, Value: sender.ID}}
+
+	listed, err := f.msgUC.List(ownerCtx(receiver), opts)
+	if err != nil {
+		t.Fatalf("list as receiver: %v", err)
+	}
+	if ids := messageIDs(listed); !slices.Equal(ids, []string{delivered.ID}) {
+		t.Errorf("receiver lists %v, want only the delivered message %s", ids, delivered.ID)
+	}
+
+	listed, err = f.msgUC.List(ownerCtx(sender), opts)
+	if err != nil {
+		t.Fatalf("list as sender: %v", err)
+	}
+	if ids := messageIDs(listed); len(ids) != 3 || !slices.Contains(ids, scheduled.ID) || !slices.Contains(ids, pending.ID) {
+		t.Errorf("sender lists %v, want all of their 3 messages", ids)
+	}
+}
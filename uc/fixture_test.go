@@ -0,0 +1,103 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+)
+
+// msgFixture runs a MsgUC on the memory repositories, with the given assignment strategy and approval chains
+type msgFixture struct {
+	users       *memoryUserRepo
+	messages    *memoryMessageRepo
+	delegations *memoryDelegationRepo
+	msgUC       *MsgUC
+}
+
+func newMsgFixture(t *testing.T, strategyName string, chains model.ApprovalChains) *msgFixture {
+	t.Helper()
+
+	users := newMemoryUserRepo()
+	messages := newMemoryMessageRepo()
+	delegations := &memoryDelegationRepo{}
+	conflicts := NewConflictRuleEngine(DefaultConflictRules(users)...)
+
+	strategy, err := NewAssignmentStrategy(strategyName, messages, users)
+	if err != nil {
+		t.Fatalf("NewAssignmentStrategy(%q): %v", strategyName, err)
+	}
+
+	if chains == nil {
+		chains = model.DefaultApprovalChains()
+	}
+
+	msgUC := NewMessageUC(
+		messages,
+		users,
+		&memoryRevisionRepo{},
+		delegations,
+		NewAuditUC(&memoryAuditRepo{}),
+		conflicts,
+		NewAssigner(users, conflicts, strategy),
+		NewWorkflows(nil, chains, nil, nil, nil),
+		model.DefaultRejectionReasons(),
+		nil,
+		nil,
+	)
+
+	return &msgFixture{
+		users:       users,
+		messages:    messages,
+		delegations: delegations,
+		msgUC:       msgUC,
+	}
+}
+
+func (rc *msgFixture) user(t *testing.T, role string, groups ...string) *model.User {
+	t.Helper()
+
+	user, err := rc.users.Create(context.Background(), &model.User{Role: role, Groups: groups})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	return user
+}
+
+func (rc *msgFixture) send(t *testing.T, sender, receiver *model.User, messageType string) *model.Message {
+	t.Helper()
+
+	message, err := rc.msgUC.Create(ownerCtx(sender), &model.MessageCreateRequest{
+		ReceiverID: receiver.ID,
+		Text:       "hello",
+		Type:       messageType,
+	})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	return message
+}
+
+func ownerCtx(user *model.User) context.Context {
+	return context.WithValue(context.Background(), "user", model.TokenOwner{ID: user.ID, Role: user.Role})
+}
+
+func statusCode(err error) int {
+	var pe *pkg.Error
+	if errors.As(err, &pe) {
+		return pe.StatusCode()
+	}
+
+	return 0
+}
+
+// isConflict reports whether the decision lost against another one: it read an older version, or the message was decided already
+func isConflict(err error) bool {
+	var ce *model.VersionConflictError
+	return errors.As(err, &ce) || statusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,831 @@
+package uc
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+)
+
+// The in-memory repositories below implement the repository interfaces for the use case tests.
+// They keep copies of the stored values, so that tests can't change them by accident.
+
+var errNotFound = errors.New("not found")
+
+type memoryIDs struct {
+	mu   sync.Mutex
+	last int
+}
+
+func (rc *memoryIDs) next() string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.last++
+
+	return fmt.Sprintf("%024x", rc.last)
+}
+
+type memoryUserRepo struct {
+	ids   memoryIDs
+	mu    sync.Mutex
+	users map[string]model.User
+}
+
+func newMemoryUserRepo() *memoryUserRepo {
+	return &memoryUserRepo{
+		users: map[string]model.User{},
+	}
+}
+
+func (rc *memoryUserRepo) Create(_ context.Context, user *model.User) (*model.User, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if user.ID == "" {
+		user.ID = rc.ids.next()
+	}
+	rc.users[user.ID] = *user
+
+	return user, nil
+}
+
+func (rc *memoryUserRepo) Update(_ context.Context, userID string, user *model.User) (*model.User, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, ok := rc.users[userID]; !ok {
+		return nil, errNotFound
+	}
+	user.ID = userID
+	rc.users[userID] = *user
+
+	return user, nil
+}
+
+func (rc *memoryUserRepo) GetByID(_ context.Context, userID string) (*model.User, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	user, ok := rc.users[userID]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	return &user, nil
+}
+
+func (rc *memoryUserRepo) GetByUsernameOrEmail(_ context.Context, usernameOrEmail string) (*model.User, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, v := range rc.users {
+		if v.Username == usernameOrEmail || v.Email == usernameOrEmail {
+			return &v, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+func (rc *memoryUserRepo) Exists(ctx context.Context, usernameOrEmail string) (bool, error) {
+	_, err := rc.GetByUsernameOrEmail(ctx, usernameOrEmail)
+	return err == nil, nil
+}
+
+func (rc *memoryUserRepo) Delete(_ context.Context, userID string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	user, ok := rc.users[userID]
+	if !ok {
+		return errNotFound
+	}
+	user.DeletedAt = time.Now()
+	rc.users[userID] = user
+
+	return nil
+}
+
+func (rc *memoryUserRepo) ListByRole(_ context.Context, role string) ([]model.User, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	users := make([]model.User, 0)
+	for _, v := range rc.users {
+		if v.Role == role && v.DeletedAt.IsZero() {
+			users = append(users, v)
+		}
+	}
+	slices.SortFunc(users, func(a, b model.User) int { return cmp.Compare(a.ID, b.ID) })
+
+	return users, nil
+}
+
+func (rc *memoryUserRepo) ListByIDs(_ context.Context, userIDs []string) ([]model.User, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	users := make([]model.User, 0, len(userIDs))
+	for _, v := range userIDs {
+		if user, ok := rc.users[v]; ok {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+type memoryMessageRepo struct {
+	ids      memoryIDs
+	mu       sync.Mutex
+	messages map[string]model.Message
+}
+
+func newMemoryMessageRepo() *memoryMessageRepo {
+	return &memoryMessageRepo{
+		messages: map[string]model.Message{},
+	}
+}
+
+func (rc *memoryMessageRepo) Create(_ context.Context, message *model.Message) (*model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	message.ID = rc.ids.next()
+	rc.messages[message.ID] = cloneMessage(message)
+
+	return message, nil
+}
+
+func (rc *memoryMessageRepo) Update(_ context.Context, messageID string, message *model.Message) (*model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Version != message.Version {
+		return nil, &model.VersionConflictError{Resource: model.AuditResourceMessage, ID: messageID, Version: message.Version}
+	}
+
+	stored.Text = message.Text
+	stored.Status = message.Status
+	stored.Revision = message.Revision
+	stored.Steps = cloneMessage(message).Steps
+	stored.CurrentStep = message.CurrentStep
+	stored.Version++
+	rc.messages[messageID] = stored
+	message.Version++
+
+	return message, nil
+}
+
+func (rc *memoryMessageRepo) List(_ context.Context, opts model.MessageFindOpts) ([]model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	messages := make([]model.Message, 0)
+	for _, v := range rc.messages {
+		if opts.SenderID.IsSended && v.SenderID != opts.SenderID.Value {
+			continue
+		}
+		if opts.ReceiverID.IsSended && v.ReceiverID != opts.ReceiverID.Value {
+			continue
+		}
+		messages = append(messages, cloneMessage(&v))
+	}
+
+	return messages, nil
+}
+
+func (rc *memoryMessageRepo) GetByID(_ context.Context, messageID string) (*model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	message, ok := rc.messages[messageID]
+	if !ok {
+		return nil, errNotFound
+	}
+	message = cloneMessage(&message)
+
+	return &message, nil
+}
+
+func (rc *memoryMessageRepo) ListByIDs(ctx context.Context, messageIDs []string) ([]model.Message, error) {
+	messages := make([]model.Message, 0, len(messageIDs))
+	for _, v := range messageIDs {
+		message, err := rc.GetByID(ctx, v)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+
+	return messages, nil
+}
+
+func (rc *memoryMessageRepo) AddVote(_ context.Context, vote *model.VoteWrite) (*model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[vote.MessageID]
+	if !ok || stored.Version != vote.Version || stored.Status != model.MessageStatusPending || stored.CurrentStep != vote.Step ||
+		vote.Step < len(stored.Steps) && (stored.Steps[vote.Step].HasVoted(vote.Vote.CheckerID) ||
+			vote.Vote.DelegateID != "" && stored.Steps[vote.Step].HasVoted(vote.Vote.DelegateID)) {
+		return nil, &model.VersionConflictError{Resource: model.AuditResourceMessage, ID: vote.MessageID, Version: vote.Version}
+	}
+
+	stored = cloneMessage(&stored)
+	stored.Steps = cloneMessage(&model.Message{Steps: vote.Steps}).Steps
+	stored.Status = vote.Status
+	stored.CurrentStep = vote.NextStep
+	if vote.Reassign {
+		stored.AssigneeID = vote.AssigneeID
+	}
+	stored.Version++
+	stored.Claim = nil
+	rc.messages[vote.MessageID] = stored
+	stored = cloneMessage(&stored)
+
+	return &stored, nil
+}
+
+func (rc *memoryMessageRepo) Finalize(_ context.Context, messageID string, version int, step int, status int, nextStep int) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Version != version || stored.Status != model.MessageStatusPending || stored.CurrentStep != step {
+		return &model.VersionConflictError{Resource: model.AuditResourceMessage, ID: messageID, Version: version}
+	}
+
+	stored.Status = status
+	stored.CurrentStep = nextStep
+	stored.Version++
+	rc.messages[messageID] = stored
+
+	return nil
+}
+
+func (rc *memoryMessageRepo) AddVotes(ctx context.Context, votes []model.VoteWrite) error {
+	for i := range votes {
+		_, err := rc.AddVote(ctx, &votes[i])
+		var ce *model.VersionConflictError
+		if err != nil && !errors.As(err, &ce) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rc *memoryMessageRepo) Withdraw(_ context.Context, messageID string, version int, deletedAt time.Time) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Version != version {
+		return &model.VersionConflictError{Resource: model.AuditResourceMessage, ID: messageID, Version: version}
+	}
+
+	stored.Status = model.MessageStatusWithdrawn
+	stored.DeletedAt = deletedAt
+	stored.Claim = nil
+	stored.AssigneeID = ""
+	if stored.SLA != nil {
+		sla := *stored.SLA
+		sla.NextCheckAt = time.Time{}
+		stored.SLA = &sla
+	}
+	stored.Version++
+	rc.messages[messageID] = stored
+
+	return nil
+}
+
+func (rc *memoryMessageRepo) UpdateDraft(_ context.Context, message *model.Message) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[message.ID]
+	if !ok || stored.Version != message.Version || stored.Status != model.MessageStatusDraft {
+		return &model.VersionConflictError{Resource: model.AuditResourceMessage, ID: message.ID, Version: message.Version}
+	}
+
+	stored.ReceiverID = message.ReceiverID
+	stored.Text = message.Text
+	stored.Type = message.Type
+	stored.DeliverAt = message.DeliverAt
+	stored.Delivered = message.Delivered
+	stored.Workflow = message.Workflow
+	stored.Version++
+	rc.messages[message.ID] = stored
+
+	return nil
+}
+
+func (rc *memoryMessageRepo) Submit(_ context.Context, message *model.Message) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[message.ID]
+	if !ok || stored.Version != message.Version || stored.Status != model.MessageStatusDraft {
+		return &model.VersionConflictError{Resource: model.AuditResourceMessage, ID: message.ID, Version: message.Version}
+	}
+
+	submitted := cloneMessage(message)
+	submitted.Version++
+	rc.messages[message.ID] = submitted
+
+	return nil
+}
+
+func (rc *memoryMessageRepo) Recall(_ context.Context, messageID string, version int, recalledAt time.Time) (*model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Version != version {
+		return nil, &model.VersionConflictError{Resource: model.AuditResourceMessage, ID: messageID, Version: version}
+	}
+
+	stored.Status = model.MessageStatusRecalled
+	stored.RecalledAt = recalledAt
+	stored.Version++
+	rc.messages[messageID] = stored
+	stored = cloneMessage(&stored)
+
+	return &stored, nil
+}
+
+func (rc *memoryMessageRepo) ListQueue(_ context.Context, opts model.ReviewQueueOpts) ([]model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	messages := make([]model.Message, 0)
+	for _, v := range rc.messages {
+		if v.Status != model.MessageStatusPending || v.SenderID == opts.CheckerID || v.ReceiverID == opts.CheckerID ||
+			v.Claim.IsHeldByOther(opts.CheckerID, now) {
+			continue
+		}
+		messages = append(messages, cloneMessage(&v))
+	}
+	slices.SortFunc(messages, func(a, b model.Message) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	start := min(int(opts.Skip), len(messages))
+	end := min(start+int(opts.Limit), len(messages))
+
+	return messages[start:end], nil
+}
+
+func (rc *memoryMessageRepo) Claim(_ context.Context, messageID string, claim *model.ReviewClaim) (*model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Status != model.MessageStatusPending || stored.Claim.IsHeldByOther(claim.CheckerID, claim.ClaimedAt) {
+		return nil, interfaces.ErrMessageClaimed
+	}
+
+	leased := *claim
+	stored.Claim = &leased
+	rc.messages[messageID] = stored
+	stored = cloneMessage(&stored)
+
+	return &stored, nil
+}
+
+func (rc *memoryMessageRepo) Release(_ context.Context, messageID string, checkerID string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Claim == nil || stored.Claim.CheckerID != checkerID {
+		return interfaces.ErrMessageClaimed
+	}
+
+	stored.Claim = nil
+	rc.messages[messageID] = stored
+
+	return nil
+}
+
+func (rc *memoryMessageRepo) Assign(_ context.Context, messageID string, checkerID string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Status != model.MessageStatusPending {
+		return errNotFound
+	}
+
+	stored.AssigneeID = checkerID
+	rc.messages[messageID] = stored
+
+	return nil
+}
+
+func (rc *memoryMessageRepo) CountAssigned(_ context.Context, checkerIDs []string) (map[string]int, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	counts := map[string]int{}
+	for _, v := range rc.messages {
+		if v.Status == model.MessageStatusPending && slices.Contains(checkerIDs, v.AssigneeID) {
+			counts[v.AssigneeID]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (rc *memoryMessageRepo) ListDue(_ context.Context, now time.Time, excludeIDs []string, limit int) ([]model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	messages := make([]model.Message, 0)
+	for _, v := range rc.messages {
+		if v.Status != model.MessageStatusPending || v.SLA == nil || v.SLA.NextCheckAt.IsZero() || v.SLA.NextCheckAt.After(now) ||
+			slices.Contains(excludeIDs, v.ID) {
+			continue
+		}
+		messages = append(messages, cloneMessage(&v))
+	}
+	slices.SortFunc(messages, func(a, b model.Message) int {
+		return cmp.Or(a.SLA.NextCheckAt.Compare(b.SLA.NextCheckAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return messages[:min(limit, len(messages))], nil
+}
+
+func (rc *memoryMessageRepo) RecordEscalation(_ context.Context, messageID string, esc *model.Escalation, nextCheckAt time.Time) (bool, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	status := model.MessageStatusPending
+	if esc.Level == model.EscalationExpiry {
+		status = model.MessageStatusExpired
+	}
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Status != status || stored.CurrentStep != esc.Step ||
+		slices.ContainsFunc(stored.Escalations, func(v model.Escalation) bool { return v.Level == esc.Level && v.DueAt.Equal(esc.DueAt) }) {
+		return false, nil
+	}
+
+	stored = cloneMessage(&stored)
+	stored.Escalations = append(stored.Escalations, *esc)
+	stored.SLA.NextCheckAt = nextCheckAt
+	if esc.Level == model.EscalationBreach {
+		stored.SLA.Breached = true
+		if esc.Group != "" {
+			stored.Steps[esc.Step].EscalatedTo = esc.Group
+		}
+		if esc.AssigneeID != "" {
+			stored.AssigneeID = esc.AssigneeID
+		}
+	}
+	rc.messages[messageID] = stored
+
+	return true, nil
+}
+
+func (rc *memoryMessageRepo) ListRecent(_ context.Context, limit int) ([]model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	messages := make([]model.Message, 0)
+	for _, v := range rc.messages {
+		if v.Status == model.MessageStatusDraft {
+			continue
+		}
+		messages = append(messages, cloneMessage(&v))
+	}
+	slices.SortFunc(messages, func(a, b model.Message) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	return messages[:min(limit, len(messages))], nil
+}
+
+func (rc *memoryMessageRepo) ListUndelivered(_ context.Context, now time.Time, limit int) ([]model.Message, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	messages := make([]model.Message, 0)
+	for _, v := range rc.messages {
+		if v.Status != model.MessageStatusAccepted || v.Delivered || v.DeliverAt.After(now) {
+			continue
+		}
+		messages = append(messages, cloneMessage(&v))
+	}
+	slices.SortFunc(messages, func(a, b model.Message) int {
+		return cmp.Or(a.DeliverAt.Compare(b.DeliverAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return messages[:min(limit, len(messages))], nil
+}
+
+func (rc *memoryMessageRepo) Deliver(_ context.Context, messageID string, deliveredAt time.Time) (bool, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	stored, ok := rc.messages[messageID]
+	if !ok || stored.Status != model.MessageStatusAccepted || stored.Delivered {
+		return false, nil
+	}
+
+	stored.Delivered = true
+	stored.DeliveredAt = deliveredAt
+	stored.Version++
+	rc.messages[messageID] = stored
+
+	return true, nil
+}
+
+// cloneMessage copies the message deep enough that the steps and votes are not shared
+func cloneMessage(message *model.Message) model.Message {
+	clone := *message
+	clone.Steps = slices.Clone(message.Steps)
+	for i := range clone.Steps {
+		clone.Steps[i].Votes = slices.Clone(clone.Steps[i].Votes)
+	}
+	if message.Claim != nil {
+		claim := *message.Claim
+		clone.Claim = &claim
+	}
+	if message.SLA != nil {
+		sla := *message.SLA
+		sla.RemindAt = slices.Clone(sla.RemindAt)
+		clone.SLA = &sla
+	}
+	clone.Escalations = slices.Clone(message.Escalations)
+
+	return clone
+}
+
+type memoryRevisionRepo struct {
+	ids       memoryIDs
+	mu        sync.Mutex
+	revisions []model.MessageRevision
+}
+
+func (rc *memoryRevisionRepo) Create(_ context.Context, revision *model.MessageRevision) (*model.MessageRevision, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	revision.ID = rc.ids.next()
+	rc.revisions = append(rc.revisions, *revision)
+
+	return revision, nil
+}
+
+func (rc *memoryRevisionRepo) ListByMessageID(_ context.Context, messageID string) ([]model.MessageRevision, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	revisions := make([]model.MessageRevision, 0)
+	for _, v := range rc.revisions {
+		if v.MessageID == messageID {
+			revisions = append(revisions, v)
+		}
+	}
+
+	return revisions, nil
+}
+
+type memoryDelegationRepo struct {
+	ids         memoryIDs
+	mu          sync.Mutex
+	delegations []model.Delegation
+}
+
+func (rc *memoryDelegationRepo) Create(_ context.Context, delegation *model.Delegation) (*model.Delegation, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	delegation.ID = rc.ids.next()
+	rc.delegations = append(rc.delegations, *delegation)
+
+	return delegation, nil
+}
+
+func (rc *memoryDelegationRepo) GetByID(_ context.Context, delegationID string) (*model.Delegation, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, v := range rc.delegations {
+		if v.ID == delegationID {
+			return &v, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+func (rc *memoryDelegationRepo) ListByUser(_ context.Context, userID string) ([]model.Delegation, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	delegations := make([]model.Delegation, 0)
+	for _, v := range rc.delegations {
+		if v.DelegatorID == userID || v.DelegateID == userID {
+			delegations = append(delegations, v)
+		}
+	}
+
+	return delegations, nil
+}
+
+func (rc *memoryDelegationRepo) ListActive(_ context.Context, delegatorID, delegateID string, now time.Time) ([]model.Delegation, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	delegations := make([]model.Delegation, 0)
+	for _, v := range rc.delegations {
+		if v.DelegatorID == delegatorID && v.DelegateID == delegateID && v.IsActive(now) {
+			delegations = append(delegations, v)
+		}
+	}
+
+	return delegations, nil
+}
+
+func (rc *memoryDelegationRepo) Revoke(_ context.Context, delegationID string, revokedAt time.Time) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for i, v := range rc.delegations {
+		if v.ID == delegationID {
+			rc.delegations[i].RevokedAt = revokedAt
+			return nil
+		}
+	}
+
+	return errNotFound
+}
+
+type memoryChangeRequestRepo struct {
+	ids     memoryIDs
+	mu      sync.Mutex
+	changes []model.ChangeRequest
+}
+
+func (rc *memoryChangeRequestRepo) Create(_ context.Context, change *model.ChangeRequest) (*model.ChangeRequest, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	change.ID = rc.ids.next()
+	rc.changes = append(rc.changes, *change)
+
+	return change, nil
+}
+
+func (rc *memoryChangeRequestRepo) GetByID(_ context.Context, changeID string) (*model.ChangeRequest, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, v := range rc.changes {
+		if v.ID == changeID {
+			v.Approvals = slices.Clone(v.Approvals)
+			return &v, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+func (rc *memoryChangeRequestRepo) List(_ context.Context, opts model.ChangeRequestFindOpts) ([]model.ChangeRequest, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	changes := make([]model.ChangeRequest, 0)
+	for _, v := range rc.changes {
+		if opts.ResourceType.IsSended && v.ResourceType != opts.ResourceType.Value ||
+			opts.ResourceID.IsSended && v.ResourceID != opts.ResourceID.Value ||
+			opts.Status.IsSended && v.Status != opts.Status.Value ||
+			len(opts.ResourceTypes) > 0 && !slices.Contains(opts.ResourceTypes, v.ResourceType) {
+			continue
+		}
+		changes = append(changes, v)
+	}
+
+	return changes, nil
+}
+
+func (rc *memoryChangeRequestRepo) AddApproval(_ context.Context, changeID string, approval *model.ChangeApproval) (*model.ChangeRequest, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	i := slices.IndexFunc(rc.changes, func(v model.ChangeRequest) bool { return v.ID == changeID })
+	if i < 0 || rc.changes[i].Status != model.ChangeRequestStatusPending || rc.changes[i].HasApproved(approval.ApproverID) {
+		return nil, interfaces.ErrChangeRequestClosed
+	}
+
+	rc.changes[i].Approvals = append(rc.changes[i].Approvals, *approval)
+	change := rc.changes[i]
+	change.Approvals = slices.Clone(change.Approvals)
+
+	return &change, nil
+}
+
+func (rc *memoryChangeRequestRepo) Decide(_ context.Context, changeID string, status string, deciderID string, comment string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	i := slices.IndexFunc(rc.changes, func(v model.ChangeRequest) bool { return v.ID == changeID })
+	if i < 0 || rc.changes[i].Status != model.ChangeRequestStatusPending {
+		return interfaces.ErrChangeRequestClosed
+	}
+
+	rc.changes[i].Status = status
+	rc.changes[i].DecidedBy = deciderID
+	rc.changes[i].DecidedAt = time.Now()
+	rc.changes[i].Comment = comment
+
+	return nil
+}
+
+func (rc *memoryChangeRequestRepo) MarkFailed(_ context.Context, changeID string, reason string) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	i := slices.IndexFunc(rc.changes, func(v model.ChangeRequest) bool { return v.ID == changeID })
+	if i < 0 {
+		return errNotFound
+	}
+
+	rc.changes[i].Status = model.ChangeRequestStatusFailed
+	rc.changes[i].Error = reason
+
+	return nil
+}
+
+func (rc *memoryChangeRequestRepo) ExpireStale(_ context.Context) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	for i, v := range rc.changes {
+		if v.Status == model.ChangeRequestStatusPending && !v.ExpiresAt.After(now) {
+			rc.changes[i].Status = model.ChangeRequestStatusExpired
+		}
+	}
+
+	return nil
+}
+
+type memoryAuditRepo struct {
+	mu      sync.Mutex
+	records []model.AuditRecord
+}
+
+func (rc *memoryAuditRepo) Create(_ context.Context, record *model.AuditRecord) (*model.AuditRecord, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if len(rc.records) > 0 && rc.records[len(rc.records)-1].Sequence >= record.Sequence {
+		return nil, interfaces.ErrAuditSequenceTaken
+	}
+	record.ID = fmt.Sprintf("%024x", record.Sequence)
+	rc.records = append(rc.records, *record)
+
+	return record, nil
+}
+
+func (rc *memoryAuditRepo) Last(_ context.Context) (*model.AuditRecord, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if len(rc.records) == 0 {
+		return nil, nil
+	}
+	last := rc.records[len(rc.records)-1]
+
+	return &last, nil
+}
+
+func (rc *memoryAuditRepo) ListAfter(_ context.Context, sequence int64, limit uint) ([]model.AuditRecord, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	records := make([]model.AuditRecord, 0)
+	for _, v := range rc.records {
+		if v.Sequence > sequence && uint(len(records)) < limit {
+			records = append(records, v)
+		}
+	}
+
+	return records, nil
+}
@@ -2,7 +2,10 @@ package uc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/fleimkeipa/maker-checker/model"
@@ -12,60 +15,454 @@ import (
 )
 
 type MsgUC struct {
-	msgRepo interfaces.MessageInterfaces
+	msgRepo      interfaces.MessageInterfaces
+	userRepo     interfaces.UserInterfaces
+	revisionRepo interfaces.RevisionInterfaces
+	delegations  interfaces.DelegationInterfaces
+	audit        *AuditUC
+	conflicts    *ConflictRuleEngine
+	assigner     *Assigner
+	workflows    *Workflows
+	reasons      model.RejectionReasons
+	calendar     model.BusinessCalendar
+	policy       ApprovalPolicy
 }
 
-func NewMessageUC(repo interfaces.MessageInterfaces) *MsgUC {
+func NewMessageUC(repo interfaces.MessageInterfaces, userRepo interfaces.UserInterfaces, revisionRepo interfaces.RevisionInterfaces, delegations interfaces.DelegationInterfaces, audit *AuditUC, conflicts *ConflictRuleEngine, assigner *Assigner, workflows *Workflows, reasons model.RejectionReasons, calendar model.BusinessCalendar, policy ApprovalPolicy) *MsgUC {
 	return &MsgUC{
-		msgRepo: repo,
+		msgRepo:      repo,
+		userRepo:     userRepo,
+		revisionRepo: revisionRepo,
+		delegations:  delegations,
+		audit:        audit,
+		conflicts:    conflicts,
+		assigner:     assigner,
+		workflows:    workflows,
+		reasons:      reasons,
+		calendar:     calendar,
+		policy:       policy,
 	}
 }
 
 func (rc *MsgUC) Create(ctx context.Context, req *model.MessageCreateRequest) (*model.Message, error) {
-	message := model.Message{
-		CreatedAt:  time.Now(),
-		SenderID:   util.GetOwnerIDFromCtx(ctx),
-		ReceiverID: req.ReceiverID,
-		Text:       req.Text,
-		Status:     model.MessageStatusPending,
+	message, err := newMessage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// a draft is only checked for what it has so far, anything else goes up for review right away
+	if req.Draft {
+		if err := rc.startDraft(message); err != nil {
+			return nil, err
+		}
+		if err := rc.validateDraft(ctx, message); err != nil {
+			return nil, err
+		}
+	} else if err := rc.prepareSubmission(ctx, message, message.CreatedAt); err != nil {
+		return nil, err
 	}
 
-	newMsg, err := rc.msgRepo.Create(ctx, &message)
+	newMsg, err := rc.msgRepo.Create(ctx, message)
 	if err != nil {
 		return nil, pkg.NewError(err, "failed to create message", http.StatusInternalServerError)
 	}
 
+	if newMsg.Status != model.MessageStatusDraft {
+		if err := rc.createRevision(ctx, newMsg, "", nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionMessageCreate,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   newMsg.ID,
+		After:        newMsg,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := rc.auditAutoApproval(ctx, newMsg); err != nil {
+		return nil, err
+	}
+
+	if newMsg.Status != model.MessageStatusDraft {
+		rc.workflows.Notify(ctx, newMsg)
+	}
+
+	newMsg.AllowedTransitions = rc.workflows.AllowedTransitions(newMsg)
+
 	return newMsg, nil
 }
 
 func (rc *MsgUC) Update(ctx context.Context, messageID string, req *model.MessageUpdateRequest) (*model.Message, error) {
+	message, vote, err := rc.prepareVote(ctx, messageID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	write, err := rc.decide(ctx, message, vote)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := rc.msgRepo.AddVote(ctx, write)
+	if err != nil {
+		return nil, writeError(err, "failed to record vote")
+	}
+
+	updated.AllowedTransitions = rc.workflows.AllowedTransitions(updated)
+
+	if err := rc.auditVote(ctx, message, updated, vote); err != nil {
+		return nil, err
+	}
+
+	if updated.Status != message.Status {
+		rc.workflows.Notify(ctx, updated)
+	}
+
+	return updated, nil
+}
+
+// prepareVote runs the checks of a decision on the message: its version, the state machine, the review lease,
+// the decision itself, the delegation, the segregation of duties and the approval step. It returns the message
+// as it was read and the vote to record on its current step.
+func (rc *MsgUC) prepareVote(ctx context.Context, messageID string, req *model.MessageUpdateRequest) (*model.Message, *model.ApprovalDecision, error) {
 	// message exist control
+	message, err := rc.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// stale read control, the checker decided on an older version of the message
+	if req.Version != 0 && req.Version != message.Version {
+		return nil, nil, &model.VersionConflictError{
+			Resource: model.AuditResourceMessage,
+			ID:       messageID,
+			Version:  req.Version,
+		}
+	}
+
+	// state machine control
+	if err := rc.workflows.ValidateTransition(message, req.Status, model.ActorChecker); err != nil {
+		return nil, nil, err
+	}
+
+	// votes are cast on the approval chain, which is only open while the message is pending
+	if message.Status != model.MessageStatusPending {
+		return nil, nil, pkg.NewErrorWithReason(nil, "only pending messages can be reviewed", model.TransitionReasonNotAllowed, http.StatusConflict)
+	}
+
+	actorID := util.GetOwnerIDFromCtx(ctx)
+
+	// review lease control
+	if message.Claim.IsHeldByOther(actorID, time.Now()) {
+		return nil, nil, pkg.NewError(nil, "message is claimed by another checker until "+message.Claim.ExpiresAt.Format(time.RFC3339), http.StatusConflict)
+	}
+
+	if err := rc.validateDecision(req); err != nil {
+		return nil, nil, err
+	}
+
+	// the vote counts for the checker, the delegate is set when the caller votes on their behalf
+	checkerID, delegateID, err := rc.decidingChecker(ctx, actorID, req.OnBehalfOf, message)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// segregation of duties control, a delegation must not let either of them check a message they may not check
+	if err := rc.conflicts.Check(ctx, checkerID, message); err != nil {
+		return nil, nil, err
+	}
+	if delegateID != "" {
+		if err := rc.conflicts.Check(ctx, delegateID, message); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// messages created before approval chains existed have a single default step, it is stored with the vote
+	if len(message.Steps) == 0 {
+		message.Steps, _ = rc.workflows.chains.For(model.DefaultMessageType)
+	}
+
+	step, err := rc.currentStep(ctx, checkerID, message)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if step.HasVoted(checkerID) {
+		return nil, nil, pkg.NewError(nil, "you already voted on this step", http.StatusConflict)
+	}
+
+	// a delegate has one vote per message as well, on their own or anybody else's behalf
+	if delegateID != "" {
+		for _, v := range message.Steps[:message.CurrentStep+1] {
+			if v.HasVoted(delegateID) {
+				return nil, nil, pkg.NewError(nil, "you already voted on this message", http.StatusConflict)
+			}
+		}
+	}
+
+	vote := model.ApprovalDecision{
+		DecidedAt:  time.Now(),
+		CheckerID:  checkerID,
+		DelegateID: delegateID,
+		ReasonCode: req.ReasonCode,
+		Comment:    req.Comment,
+		Status:     req.Status,
+	}
+
+	return message, &vote, nil
+}
+
+// auditVote records the vote in the audit trail, on behalf of the checker if a delegate cast it
+func (rc *MsgUC) auditVote(ctx context.Context, before, after *model.Message, vote *model.ApprovalDecision) error {
+	var onBehalfOfID string
+	if vote.DelegateID != "" {
+		onBehalfOfID = vote.CheckerID
+	}
+
+	return rc.audit.Record(ctx, model.AuditEvent{
+		OnBehalfOfID: onBehalfOfID,
+		Action:       model.AuditActionMessageVote,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   before.ID,
+		Before:       before,
+		After:        after,
+	})
+}
+
+// Resubmit lets the sender edit the text of a message that a checker sent back, and puts it up for review again
+func (rc *MsgUC) Resubmit(ctx context.Context, messageID string, req *model.MessageResubmitRequest) (*model.Message, error) {
+	message, err := rc.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.SenderID != util.GetOwnerIDFromCtx(ctx) {
+		return nil, pkg.NewError(nil, "only the sender can resubmit a message", http.StatusForbidden)
+	}
+
+	// stale read control, the sender edited an older version of the message
+	if req.Version != 0 && req.Version != message.Version {
+		return nil, &model.VersionConflictError{
+			Resource: model.AuditResourceMessage,
+			ID:       messageID,
+			Version:  req.Version,
+		}
+	}
+
+	if err := rc.workflows.ValidateTransition(message, model.MessageStatusPending, model.ActorSender); err != nil {
+		return nil, err
+	}
+
+	if req.Text == "" {
+		return nil, pkg.NewError(nil, "text is required", http.StatusBadRequest)
+	}
+
+	var changesRequested *model.ApprovalDecision
+	if message.CurrentStep < len(message.Steps) {
+		changesRequested = message.Steps[message.CurrentStep].ChangesRequest()
+	}
+
+	previousText := message.Text
+	before := *message
+	before.Steps = slices.Clone(message.Steps)
+
+	// the new revision is reviewed from the first step again
+	for i := range message.Steps {
+		message.Steps[i].Votes = nil
+	}
+	message.CurrentStep = 0
+	message.Status = model.MessageStatusPending
+	message.Text = req.Text
+	message.Revision = max(message.Revision, 1) + 1
+
+	if _, err := rc.msgRepo.Update(ctx, messageID, message); err != nil {
+		return nil, writeError(err, "failed to resubmit message")
+	}
+
+	if err := rc.assignCurrentStep(ctx, message); err != nil {
+		return nil, err
+	}
+
+	if err := rc.createRevision(ctx, message, previousText, changesRequested); err != nil {
+		return nil, err
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionMessageResubmit,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   messageID,
+		Before:       &before,
+		After:        message,
+	}); err != nil {
+		return nil, err
+	}
+
+	rc.workflows.Notify(ctx, message)
+
+	message.AllowedTransitions = rc.workflows.AllowedTransitions(message)
+
+	return message, nil
+}
+
+// Withdraw lets the sender retract a message, from the statuses its workflow allows. The message stays in the sender's
+// history as withdrawn.
+func (rc *MsgUC) Withdraw(ctx context.Context, messageID string, req *model.MessageWithdrawRequest) (*model.Message, error) {
+	message, err := rc.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.SenderID != util.GetOwnerIDFromCtx(ctx) {
+		return nil, pkg.NewError(nil, "only the sender can withdraw a message", http.StatusForbidden)
+	}
+
+	// stale read control, the sender withdraws an older version of the message
+	if req.Version != 0 && req.Version != message.Version {
+		return nil, &model.VersionConflictError{
+			Resource: model.AuditResourceMessage,
+			ID:       messageID,
+			Version:  req.Version,
+		}
+	}
+
+	if err := rc.workflows.ValidateTransition(message, model.MessageStatusWithdrawn, model.ActorSender); err != nil {
+		return nil, err
+	}
+
+	before := *message
+	deletedAt := time.Now()
+
+	if err := rc.msgRepo.Withdraw(ctx, messageID, message.Version, deletedAt); err != nil {
+		return nil, writeError(err, "failed to withdraw message")
+	}
+
+	message.Status = model.MessageStatusWithdrawn
+	message.DeletedAt = deletedAt
+	message.Claim = nil
+	message.AssigneeID = ""
+	if message.SLA != nil {
+		sla := *message.SLA
+		sla.NextCheckAt = time.Time{}
+		message.SLA = &sla
+	}
+	message.Version++
+	message.AllowedTransitions = rc.workflows.AllowedTransitions(message)
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionMessageWithdraw,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   messageID,
+		Before:       &before,
+		After:        message,
+	}); err != nil {
+		return nil, err
+	}
+
+	rc.workflows.Notify(ctx, message)
+
+	return message, nil
+}
+
+// Assign reassigns the current step of a pending message to another checker, who must be allowed to decide on it
+func (rc *MsgUC) Assign(ctx context.Context, messageID string, req *model.MessageAssignRequest) (*model.Message, error) {
 	message, err := rc.GetByID(ctx, messageID)
 	if err != nil {
 		return nil, err
 	}
 
 	if message.Status != model.MessageStatusPending {
-		return nil, pkg.NewError(nil, "message is not pending", http.StatusConflict)
+		return nil, pkg.NewError(nil, "only pending messages can be assigned", http.StatusConflict)
+	}
+
+	// messages created before approval chains existed have a single default step
+	if len(message.Steps) == 0 {
+		message.Steps, _ = rc.workflows.chains.For(model.DefaultMessageType)
+	}
+
+	checker, err := rc.userRepo.GetByID(ctx, req.CheckerID)
+	if err != nil {
+		return nil, pkg.NewError(err, "checker not found", http.StatusNotFound)
+	}
+
+	if checker.Role != model.RoleChecker {
+		return nil, pkg.NewError(nil, "messages can only be assigned to checkers", http.StatusUnprocessableEntity)
 	}
 
-	message.Status = req.Status
+	// segregation of duties control
+	if err := rc.conflicts.Check(ctx, checker.ID, message); err != nil {
+		return nil, err
+	}
 
-	_, err = rc.msgRepo.Update(ctx, messageID, message)
+	step, err := rc.currentStep(ctx, checker.ID, message)
 	if err != nil {
-		return nil, pkg.NewError(err, "failed to update message", http.StatusInternalServerError)
+		return nil, err
+	}
+
+	if step.HasVoted(checker.ID) {
+		return nil, pkg.NewError(nil, "checker already voted on this step", http.StatusConflict)
+	}
+
+	before := *message
+
+	if err := rc.msgRepo.Assign(ctx, messageID, checker.ID); err != nil {
+		return nil, pkg.NewError(err, "failed to assign message", http.StatusInternalServerError)
+	}
+
+	message.AssigneeID = checker.ID
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionMessageAssign,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   messageID,
+		Before:       &before,
+		After:        message,
+	}); err != nil {
+		return nil, err
 	}
 
 	return message, nil
 }
 
+// Revisions returns the revision history of a message, oldest first
+func (rc *MsgUC) Revisions(ctx context.Context, messageID string) ([]model.MessageRevision, error) {
+	// message exist control
+	if _, err := rc.GetByID(ctx, messageID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := rc.revisionRepo.ListByMessageID(ctx, messageID)
+	if err != nil {
+		return nil, pkg.NewError(err, "revisions not found", http.StatusNotFound)
+	}
+
+	return revisions, nil
+}
+
 func (rc *MsgUC) List(ctx context.Context, opts model.MessageFindOpts) ([]model.Message, error) {
-	message, err := rc.msgRepo.List(ctx, opts)
+	messages, err := rc.msgRepo.List(ctx, opts)
 	if err != nil {
 		return nil, pkg.NewError(err, "messages not found", http.StatusNotFound)
 	}
 
-	return message, nil
+	callerID := util.GetOwnerIDFromCtx(ctx)
+	visible := make([]model.Message, 0, len(messages))
+	for i := range messages {
+		// the repository filters them out already, a filter it misses must not leak them
+		if isHiddenFrom(callerID, &messages[i]) {
+			continue
+		}
+
+		messages[i].AllowedTransitions = rc.workflows.AllowedTransitions(&messages[i])
+		if isTombstoneFor(callerID, &messages[i]) {
+			messages[i] = *messages[i].ToTombstone()
+		}
+		visible = append(visible, messages[i])
+	}
+
+	return visible, nil
 }
 
 func (rc *MsgUC) GetByID(ctx context.Context, messageID string) (*model.Message, error) {
@@ -74,5 +471,236 @@ func (rc *MsgUC) GetByID(ctx context.Context, messageID string) (*model.Message,
 		return nil, pkg.NewError(err, "message not found", http.StatusNotFound)
 	}
 
+	callerID := util.GetOwnerIDFromCtx(ctx)
+	if isHiddenFrom(callerID, message) {
+		return nil, pkg.NewError(nil, "message not found", http.StatusNotFound)
+	}
+
+	message.AllowedTransitions = rc.workflows.AllowedTransitions(message)
+
+	if isTombstoneFor(callerID, message) {
+		return message.ToTombstone(), nil
+	}
+
 	return message, nil
 }
+
+// RejectionReasons returns the catalog of reason codes a checker can reject with
+func (rc *MsgUC) RejectionReasons() model.RejectionReasons {
+	return rc.reasons
+}
+
+// validateDecision requires a reason code from the catalog for rejections, and only for rejections.
+// Requesting changes requires a comment that tells the sender what to change.
+func (rc *MsgUC) validateDecision(req *model.MessageUpdateRequest) error {
+	if req.Status == model.MessageStatusChangesRequested && req.Comment == "" {
+		return pkg.NewError(nil, "comment is required when requesting changes", http.StatusBadRequest)
+	}
+
+	if req.Status != model.MessageStatusRejected {
+		if req.ReasonCode != "" {
+			return pkg.NewError(nil, "reason code is only allowed when rejecting", http.StatusBadRequest)
+		}
+		return nil
+	}
+
+	if req.ReasonCode == "" {
+		return pkg.NewError(nil, "reason code is required when rejecting", http.StatusBadRequest)
+	}
+
+	if !rc.reasons.Contains(req.ReasonCode) {
+		return pkg.NewError(errors.New("unknown reason code: "+req.ReasonCode), "reason code is not in the rejection reasons catalog", http.StatusUnprocessableEntity)
+	}
+
+	return nil
+}
+
+// createRevision stores the current text of the message as a new revision
+func (rc *MsgUC) createRevision(ctx context.Context, message *model.Message, previousText string, changesRequested *model.ApprovalDecision) error {
+	revision := model.MessageRevision{
+		CreatedAt:        time.Now(),
+		ChangesRequested: changesRequested,
+		MessageID:        message.ID,
+		AuthorID:         message.SenderID,
+		Text:             message.Text,
+		Diff:             pkg.DiffLines(previousText, message.Text),
+		Number:           message.Revision,
+	}
+
+	if _, err := rc.revisionRepo.Create(ctx, &revision); err != nil {
+		return pkg.NewError(err, "failed to create message revision", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// decide records the vote on a copy of the message read by prepareVote and settles its current step. It returns the
+// write that stores the vote together with the outcome, the next step is assigned when the message moves on to it.
+func (rc *MsgUC) decide(ctx context.Context, message *model.Message, vote *model.ApprovalDecision) (*model.VoteWrite, error) {
+	workflow, err := rc.workflows.Of(message)
+	if err != nil {
+		return nil, err
+	}
+
+	step := message.CurrentStep
+	decided := *message
+	decided.Steps = slices.Clone(message.Steps)
+	decided.Steps[step].Votes = append(slices.Clone(message.Steps[step].Votes), *vote)
+
+	if err := settle(workflow, &decided); err != nil {
+		return nil, err
+	}
+
+	write := model.VoteWrite{
+		Vote:      *vote,
+		MessageID: message.ID,
+		Steps:     decided.Steps,
+		Version:   message.Version,
+		Step:      step,
+		Status:    decided.Status,
+		NextStep:  decided.CurrentStep,
+	}
+	if decided.CurrentStep != step {
+		write.AssigneeID, err = rc.assigner.Assign(ctx, &decided)
+		if err != nil {
+			return nil, err
+		}
+		write.Reassign = true
+	}
+
+	return &write, nil
+}
+
+// settle applies the outcome of the current step to the message: an accepted step moves on to the next one, and
+// the last accepted step or any other outcome becomes the status of the message. An undecided step changes nothing.
+func settle(workflow *model.Workflow, message *model.Message) error {
+	step := message.CurrentStep
+	outcome := message.Steps[step].Outcome()
+
+	switch {
+	case outcome == model.MessageStatusPending:
+	case outcome == model.MessageStatusAccepted && step < len(message.Steps)-1:
+		message.CurrentStep++
+	default:
+		if err := validateTransition(workflow, message.Status, outcome, model.ActorChecker); err != nil {
+			return err
+		}
+		message.Status = outcome
+		message.AllowedTransitions = workflow.AllowedTransitions(message.Status)
+	}
+
+	return nil
+}
+
+// assignCurrentStep assigns the current step of a pending message to a checker picked by the assignment strategy
+func (rc *MsgUC) assignCurrentStep(ctx context.Context, message *model.Message) error {
+	assigneeID, err := rc.assigner.Assign(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	if assigneeID == message.AssigneeID {
+		return nil
+	}
+
+	if err := rc.msgRepo.Assign(ctx, message.ID, assigneeID); err != nil {
+		return pkg.NewError(err, "failed to assign message", http.StatusInternalServerError)
+	}
+
+	message.AssigneeID = assigneeID
+
+	return nil
+}
+
+// decidingChecker returns the checker the caller's vote counts for, and the caller as their delegate if the caller
+// votes on behalf of a checker. The checker must have an active delegation to the caller covering the message type.
+func (rc *MsgUC) decidingChecker(ctx context.Context, actorID, onBehalfOf string, message *model.Message) (string, string, error) {
+	if onBehalfOf == "" || onBehalfOf == actorID {
+		return actorID, "", nil
+	}
+
+	delegations, err := rc.delegations.ListActive(ctx, onBehalfOf, actorID, time.Now())
+	if err != nil {
+		return "", "", pkg.NewError(err, "failed to find delegations", http.StatusInternalServerError)
+	}
+
+	if !slices.ContainsFunc(delegations, func(v model.Delegation) bool { return v.Covers(message.Type) }) {
+		return "", "", pkg.NewError(nil, "you have no active delegation of the checker for this message type", http.StatusForbidden)
+	}
+
+	return onBehalfOf, actorID, nil
+}
+
+// currentStep returns the step waiting for a decision, if the checker is allowed to decide on it
+func (rc *MsgUC) currentStep(ctx context.Context, checkerID string, message *model.Message) (*model.ApprovalStep, error) {
+	checker, err := rc.userRepo.GetByID(ctx, checkerID)
+	if err != nil {
+		return nil, pkg.NewError(err, "checker not found", http.StatusNotFound)
+	}
+
+	return stepFor(checker, message)
+}
+
+// stepFor is currentStep for a checker that is already loaded
+func stepFor(checker *model.User, message *model.Message) (*model.ApprovalStep, error) {
+	if message.CurrentStep >= len(message.Steps) {
+		return nil, pkg.NewError(nil, "message has no open approval step", http.StatusConflict)
+	}
+
+	// a checker can sign off only one step of the chain
+	for _, v := range message.Steps[:message.CurrentStep] {
+		if v.HasVoted(checker.ID) {
+			return nil, pkg.NewError(nil, "you already signed off an earlier step of this message", http.StatusConflict)
+		}
+	}
+
+	step := &message.Steps[message.CurrentStep]
+	if !step.IsEligible(checker) {
+		return nil, pkg.NewError(nil, "you are not allowed to decide on step "+step.Name, http.StatusForbidden)
+	}
+
+	return step, nil
+}
+
+// isTombstoneFor reports whether the caller only gets to see that the message was recalled: the receiver does,
+// while the sender, checkers and auditors keep the content
+func isTombstoneFor(callerID string, message *model.Message) bool {
+	return message.Status == model.MessageStatusRecalled && message.ReceiverID == callerID && message.SenderID != callerID
+}
+
+// isHiddenFrom reports whether the caller may not see the message at all: drafts are only seen by their sender,
+// and the receiver only sees the message once it is accepted and delivered
+func isHiddenFrom(callerID string, message *model.Message) bool {
+	if message.SenderID == callerID {
+		return false
+	}
+
+	return message.Status == model.MessageStatusDraft || message.ReceiverID == callerID && !message.IsVisibleToReceiver()
+}
+
+// writeError passes version conflicts through, so that they are reported as 409, and wraps any other repository error
+func writeError(err error, message string) error {
+	var ce *model.VersionConflictError
+	if errors.As(err, &ce) {
+		return err
+	}
+
+	return pkg.NewError(err, message, http.StatusInternalServerError)
+}
+
+// validateTransition checks the state machine of the workflow and turns a rejected move into a 409 or 422 error
+func validateTransition(workflow *model.Workflow, from, to int, actor string) error {
+	var te *model.TransitionError
+	if err := workflow.ValidateTransition(from, to, actor); !errors.As(err, &te) {
+		return err
+	}
+
+	statusCode := http.StatusConflict
+	if te.Reason == model.TransitionReasonUnknownStatus {
+		statusCode = http.StatusUnprocessableEntity
+	}
+
+	message := fmt.Sprintf("message cannot move from %s to %s", model.StatusName(from), model.StatusName(to))
+
+	return pkg.NewErrorWithReason(te, message, te.Reason, statusCode)
+}
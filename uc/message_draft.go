@@ -0,0 +1,290 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/util"
+)
+
+// Edit replaces the receiver, text, type and delivery time of a draft
+func (rc *MsgUC) Edit(ctx context.Context, messageID string, req *model.MessageEditRequest) (*model.Message, error) {
+	message, err := rc.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.SenderID != util.GetOwnerIDFromCtx(ctx) {
+		return nil, pkg.NewError(nil, "only the sender can edit a draft", http.StatusForbidden)
+	}
+
+	// stale read control, the sender edited an older version of the draft
+	if req.Version != 0 && req.Version != message.Version {
+		return nil, &model.VersionConflictError{
+			Resource: model.AuditResourceMessage,
+			ID:       messageID,
+			Version:  req.Version,
+		}
+	}
+
+	if message.Status != model.MessageStatusDraft {
+		return nil, pkg.NewErrorWithReason(nil, "only drafts can be edited", model.TransitionReasonNotAllowed, http.StatusConflict)
+	}
+
+	before := *message
+
+	message.ReceiverID = req.ReceiverID
+	message.Text = req.Text
+	message.Type = messageTypeOrDefault(req.Type)
+	if err := setDelivery(message, req.DeliverAt); err != nil {
+		return nil, err
+	}
+
+	if err := rc.startDraft(message); err != nil {
+		return nil, err
+	}
+
+	if err := rc.validateDraft(ctx, message); err != nil {
+		return nil, err
+	}
+
+	if err := rc.msgRepo.UpdateDraft(ctx, message); err != nil {
+		return nil, writeError(err, "failed to edit draft")
+	}
+
+	message.Version++
+	message.AllowedTransitions = rc.workflows.AllowedTransitions(message)
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionMessageEdit,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   messageID,
+		Before:       &before,
+		After:        message,
+	}); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// Submit validates the draft and puts it up for review
+func (rc *MsgUC) Submit(ctx context.Context, messageID string, req *model.MessageSubmitRequest) (*model.Message, error) {
+	message, err := rc.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.SenderID != util.GetOwnerIDFromCtx(ctx) {
+		return nil, pkg.NewError(nil, "only the sender can submit a draft", http.StatusForbidden)
+	}
+
+	// stale read control, the sender submits an older version of the draft
+	if req.Version != 0 && req.Version != message.Version {
+		return nil, &model.VersionConflictError{
+			Resource: model.AuditResourceMessage,
+			ID:       messageID,
+			Version:  req.Version,
+		}
+	}
+
+	// messages sent back with changes requested are resubmitted instead
+	if message.Status != model.MessageStatusDraft {
+		return nil, pkg.NewErrorWithReason(nil, "only drafts can be submitted", model.TransitionReasonNotAllowed, http.StatusConflict)
+	}
+
+	// the draft is submitted under the latest version of the workflow of its type
+	workflow, err := rc.workflows.Start(message.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTransition(workflow, message.Status, model.MessageStatusPending, model.ActorSender); err != nil {
+		return nil, err
+	}
+
+	before := *message
+
+	if err := rc.prepareSubmission(ctx, message, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := rc.msgRepo.Submit(ctx, message); err != nil {
+		return nil, writeError(err, "failed to submit draft")
+	}
+
+	message.Version++
+	message.AllowedTransitions = rc.workflows.AllowedTransitions(message)
+
+	if err := rc.createRevision(ctx, message, "", nil); err != nil {
+		return nil, err
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionMessageSubmit,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   messageID,
+		Before:       &before,
+		After:        message,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := rc.auditAutoApproval(ctx, message); err != nil {
+		return nil, err
+	}
+
+	rc.workflows.Notify(ctx, message)
+
+	return message, nil
+}
+
+// startDraft sets the workflow of the draft by its message type, the workflow must allow drafts. A draft of an unknown
+// message type follows the default workflow until it is submitted, the message type is checked then.
+func (rc *MsgUC) startDraft(message *model.Message) error {
+	workflow, err := rc.workflows.Start(message.Type)
+	if err != nil {
+		message.Workflow = nil
+		return nil
+	}
+
+	if !workflow.HasState(model.MessageStatusDraft) {
+		return pkg.NewErrorWithReason(nil, "the workflow of this message type has no drafts", model.TransitionReasonNotAllowed, http.StatusConflict)
+	}
+
+	message.Workflow = workflow.Ref()
+
+	return nil
+}
+
+// validateDraft checks the parts of a draft that are already filled in
+func (rc *MsgUC) validateDraft(ctx context.Context, message *model.Message) error {
+	if message.ReceiverID == "" {
+		return nil
+	}
+
+	if _, err := rc.userRepo.GetByID(ctx, message.ReceiverID); err != nil {
+		return pkg.NewError(err, "receiver not found", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// prepareSubmission checks that the message is complete and moves it to pending at its first approval step,
+// with the SLA counting from the submission and the first step assigned to a checker. The approval policy may
+// accept the message right away instead, or route it to another checker group.
+func (rc *MsgUC) prepareSubmission(ctx context.Context, message *model.Message, submittedAt time.Time) error {
+	if _, err := rc.route(ctx, message, submittedAt); err != nil {
+		return err
+	}
+
+	// an auto-accepted message has nothing left to review
+	if message.Status != model.MessageStatusPending {
+		return nil
+	}
+
+	assigneeID, err := rc.assigner.Assign(ctx, message)
+	if err != nil {
+		return err
+	}
+	message.AssigneeID = assigneeID
+
+	return nil
+}
+
+// route checks that the message is complete, and sets the workflow, status, approval steps and SLA the message gets
+// when it is submitted at the given time. It returns the rules of the approval policy that matched the message.
+func (rc *MsgUC) route(ctx context.Context, message *model.Message, submittedAt time.Time) ([]model.PolicyMatch, error) {
+	if message.ReceiverID == "" {
+		return nil, pkg.NewError(nil, "receiver_id is required", http.StatusBadRequest)
+	}
+
+	if message.Text == "" {
+		return nil, pkg.NewError(nil, "text is required", http.StatusBadRequest)
+	}
+
+	if err := rc.validateDraft(ctx, message); err != nil {
+		return nil, err
+	}
+
+	// the delivery time of a draft may have passed while it was edited
+	if !message.Delivered && !message.DeliverAt.After(submittedAt) {
+		return nil, pkg.NewError(nil, "deliver_at must be in the future", http.StatusBadRequest)
+	}
+
+	workflow, err := rc.workflows.Start(message.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	message.Workflow = workflow.Ref()
+	message.Status = model.MessageStatusPending
+	message.Revision = 1
+	message.Steps = workflow.Steps
+	message.CurrentStep = 0
+
+	matches, err := rc.applyPolicy(ctx, message, submittedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Status != model.MessageStatusPending {
+		return matches, nil
+	}
+
+	if workflow.SLA != nil {
+		message.SLA = workflow.SLA.Schedule(submittedAt, rc.calendar)
+	}
+
+	return matches, nil
+}
+
+// newMessage returns the draft of a new message from the caller, with its delivery scheduled
+func newMessage(ctx context.Context, req *model.MessageCreateRequest) (*model.Message, error) {
+	message := model.Message{
+		CreatedAt:  time.Now(),
+		SenderID:   util.GetOwnerIDFromCtx(ctx),
+		ReceiverID: req.ReceiverID,
+		Text:       req.Text,
+		Type:       messageTypeOrDefault(req.Type),
+		Status:     model.MessageStatusDraft,
+		Version:    1,
+	}
+
+	if err := setDelivery(&message, req.DeliverAt); err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// setDelivery schedules the delivery of the message, a message without a delivery time is delivered once it is accepted
+func setDelivery(message *model.Message, deliverAt *time.Time) error {
+	message.DeliverAt = time.Time{}
+	message.Delivered = true
+
+	if deliverAt == nil {
+		return nil
+	}
+
+	if !deliverAt.After(time.Now()) {
+		return pkg.NewError(nil, "deliver_at must be in the future", http.StatusBadRequest)
+	}
+
+	// a scheduled message is delivered by the delivery worker once it is accepted and its time has come
+	message.DeliverAt = *deliverAt
+	message.Delivered = false
+
+	return nil
+}
+
+func messageTypeOrDefault(messageType string) string {
+	if messageType == "" {
+		return model.DefaultMessageType
+	}
+
+	return messageType
+}
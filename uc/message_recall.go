@@ -0,0 +1,123 @@
+package uc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/changerequest"
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+)
+
+// RecallUC lets senders take back an accepted message. A recall is a change request of the message resource,
+// so that it only takes effect once checkers approved it.
+type RecallUC struct {
+	changes *changerequest.Engine
+}
+
+func NewRecallUC(changes *changerequest.Engine) *RecallUC {
+	return &RecallUC{
+		changes: changes,
+	}
+}
+
+// Request submits a change request to recall the accepted message
+func (rc *RecallUC) Request(ctx context.Context, messageID string, req *model.MessageRecallRequest) (*model.ChangeRequest, error) {
+	return rc.changes.Submit(ctx, model.ChangeResourceMessage, messageID, model.ChangeOperationRecall, req)
+}
+
+// MessageRecallApplier applies approved message recalls. It is registered on the change request engine for the message resource type.
+type MessageRecallApplier struct {
+	messages *MsgUC
+	changes  *changerequest.Engine
+}
+
+func NewMessageRecallApplier(messages *MsgUC, changes *changerequest.Engine) *MessageRecallApplier {
+	return &MessageRecallApplier{
+		messages: messages,
+		changes:  changes,
+	}
+}
+
+// Validate checks that the sender recalls an accepted message with a reason, and that no other recall is pending
+func (rc *MessageRecallApplier) Validate(ctx context.Context, change *model.ChangeRequest) error {
+	if change.Operation != model.ChangeOperationRecall {
+		return pkg.NewError(nil, "unknown message change operation: "+change.Operation, http.StatusUnprocessableEntity)
+	}
+
+	var req model.MessageRecallRequest
+	if err := json.Unmarshal(change.Payload, &req); err != nil {
+		return pkg.NewError(err, "invalid message recall payload", http.StatusBadRequest)
+	}
+
+	if req.Reason == "" {
+		return pkg.NewError(nil, "reason is required", http.StatusBadRequest)
+	}
+
+	message, err := rc.messages.msgRepo.GetByID(ctx, change.ResourceID)
+	if err != nil {
+		return pkg.NewError(err, "message not found", http.StatusNotFound)
+	}
+
+	if message.SenderID != change.RequestedBy {
+		return pkg.NewError(nil, "only the sender can recall a message", http.StatusForbidden)
+	}
+
+	if err := rc.messages.workflows.ValidateTransition(message, model.MessageStatusRecalled, model.ActorChecker); err != nil {
+		return err
+	}
+
+	pending, err := rc.changes.HasPending(ctx, model.ChangeResourceMessage, change.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	if pending {
+		return pkg.NewError(nil, "a recall of this message is already waiting for approval", http.StatusConflict)
+	}
+
+	return nil
+}
+
+// CheckApprover applies the segregation of duties rules of the message to the checkers deciding on its recall
+func (rc *MessageRecallApplier) CheckApprover(ctx context.Context, change *model.ChangeRequest, approverID string) error {
+	message, err := rc.messages.msgRepo.GetByID(ctx, change.ResourceID)
+	if err != nil {
+		return pkg.NewError(err, "message not found", http.StatusNotFound)
+	}
+
+	return rc.messages.conflicts.Check(ctx, approverID, message)
+}
+
+// Apply moves the message to recalled, its content stays stored and in the audit trail
+func (rc *MessageRecallApplier) Apply(ctx context.Context, change *model.ChangeRequest) error {
+	message, err := rc.messages.msgRepo.GetByID(ctx, change.ResourceID)
+	if err != nil {
+		return pkg.NewError(err, "message not found", http.StatusNotFound)
+	}
+
+	if err := rc.messages.workflows.ValidateTransition(message, model.MessageStatusRecalled, model.ActorChecker); err != nil {
+		return err
+	}
+
+	recalled, err := rc.messages.msgRepo.Recall(ctx, change.ResourceID, message.Version, time.Now())
+	if err != nil {
+		return writeError(err, "failed to recall message")
+	}
+
+	if err := rc.messages.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionMessageRecall,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   change.ResourceID,
+		Before:       message,
+		After:        recalled,
+	}); err != nil {
+		return err
+	}
+
+	rc.messages.workflows.Notify(ctx, recalled)
+
+	return nil
+}
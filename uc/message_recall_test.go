@@ -0,0 +1,85 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/fleimkeipa/maker-checker/changerequest"
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestRecallTakesBackAnAcceptedMessageOnceItIsApproved(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+	approver := f.user(t, model.RoleChecker)
+
+	policies := model.ChangeRequestPolicies{
+		model.ChangeResourceMessage: {ApproverRoles: []string{model.RoleChecker}},
+	}
+	changes := changerequest.NewEngine(&memoryChangeRequestRepo{}, NewAuditUC(&memoryAuditRepo{}), policies)
+	changes.Register(model.ChangeResourceMessage, NewMessageRecallApplier(f.msgUC, changes))
+	recalls := NewRecallUC(changes)
+
+	req := &model.MessageRecallRequest{Reason: "sent to the wrong team"}
+
+	// a message in review is withdrawn, not recalled
+	pending := f.send(t, sender, receiver, "")
+	if _, err := recalls.Request(ownerCtx(sender), pending.ID, req); statusCode(err) != http.StatusConflict {
+		t.Errorf("recall of a pending message: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+
+	message := f.send(t, sender, receiver, "")
+	if _, err := f.msgUC.Update(ownerCtx(checker), message.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+
+	if _, err := recalls.Request(ownerCtx(receiver), message.ID, req); statusCode(err) != http.StatusForbidden {
+		t.Errorf("recall by the receiver: status %d, want %d", statusCode(err), http.StatusForbidden)
+	}
+
+	change, err := recalls.Request(ownerCtx(sender), message.ID, req)
+	if err != nil {
+		t.Fatalf("recall: %v", err)
+	}
+	if _, err := recalls.Request(ownerCtx(sender), message.ID, req); statusCode(err) != http.StatusConflict {
+		t.Errorf("second recall: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+
+	// nothing changes until a checker approves the recall
+	if got, err := f.msgUC.GetByID(ownerCtx(receiver), message.ID); err != nil || got.Status != model.MessageStatusAccepted {
+		t.Fatalf("before approval: %+v, %v, want the accepted message", got, err)
+	}
+
+	if _, err := changes.Approve(ownerCtx(approver), change.ID, model.ChangeRequestDecision{}); err != nil {
+		t.Fatalf("approve recall: %v", err)
+	}
+
+	tombstone, err := f.msgUC.GetByID(ownerCtx(receiver), message.ID)
+	if err != nil {
+		t.Fatalf("get as receiver: %v", err)
+	}
+	if tombstone.Status != model.MessageStatusRecalled || tombstone.Text != model.RecalledMessageText {
+		t.Errorf("receiver: status %s with text %q, want the recalled tombstone", model.StatusName(tombstone.Status), tombstone.Text)
+	}
+
+	stored, err := f.msgUC.GetByID(ownerCtx(sender), message.ID)
+	if err != nil {
+		t.Fatalf("get as sender: %v", err)
+	}
+	if stored.Status != model.MessageStatusRecalled || stored.Text != message.Text || stored.RecalledAt.IsZero() {
+		t.Errorf("sender: status %s with text %q, want the recalled message with its content", model.StatusName(stored.Status), stored.Text)
+	}
+
+	// a recalled message can't be recalled again
+	if _, err := recalls.Request(ownerCtx(sender), message.ID, req); statusCode(err) != http.StatusConflict {
+		t.Errorf("recall of a recalled message: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+
+	if stored, err := f.messages.GetByID(context.Background(), pending.ID); err != nil || stored.Status != model.MessageStatusPending {
+		t.Errorf("message in review: %+v, %v, want it still pending", stored, err)
+	}
+}
@@ -0,0 +1,56 @@
+package uc
+
+import (
+	"context"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+// Simulate routes the message as if the caller submitted it now, without storing or assigning it. It returns the
+// approval steps with the checkers who could decide on each of them, the SLA, and the rules that matched.
+// The draft flag of the request is ignored, the message is always simulated as submitted.
+func (rc *MsgUC) Simulate(ctx context.Context, req *model.MessageCreateRequest) (*model.MessageSimulation, error) {
+	message, err := newMessage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := rc.route(ctx, message, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := model.MessageSimulation{
+		Status:       message.Status,
+		Type:         message.Type,
+		Steps:        make([]model.SimulatedStep, 0, len(message.Steps)),
+		SLA:          message.SLA,
+		Policy:       message.Policy,
+		Workflow:     message.Workflow,
+		MatchedRules: matches,
+	}
+	if simulation.MatchedRules == nil {
+		simulation.MatchedRules = []model.PolicyMatch{}
+	}
+
+	for i, step := range message.Steps {
+		simulated := model.SimulatedStep{ApprovalStep: step, EligibleCheckerIDs: []string{}}
+
+		// an auto-accepted message has nobody left to decide on it
+		if message.Status == model.MessageStatusPending {
+			atStep := *message
+			atStep.CurrentStep = i
+
+			candidates, err := rc.assigner.Candidates(ctx, &atStep)
+			if err != nil {
+				return nil, err
+			}
+			simulated.EligibleCheckerIDs = userIDs(candidates)
+		}
+
+		simulation.Steps = append(simulation.Steps, simulated)
+	}
+
+	return &simulation, nil
+}
@@ -0,0 +1,246 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestConcurrentApproveAndRejectDecideTheStepOnce(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	approver := f.user(t, model.RoleChecker)
+	rejecter := f.user(t, model.RoleChecker)
+
+	for range 50 {
+		message := f.send(t, sender, receiver, "")
+
+		decisions := []struct {
+			checker *model.User
+			req     *model.MessageUpdateRequest
+		}{
+			{approver, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}},
+			{rejecter, &model.MessageUpdateRequest{Status: model.MessageStatusRejected, ReasonCode: "other", Comment: "no"}},
+		}
+
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		errs := make([]error, len(decisions))
+		for i, v := range decisions {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				_, errs[i] = f.msgUC.Update(ownerCtx(v.checker), message.ID, v.req)
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		winner := -1
+		for i, err := range errs {
+			switch {
+			case err == nil && winner < 0:
+				winner = i
+			case err == nil:
+				t.Fatal("both decisions were recorded")
+			case !isConflict(err):
+				t.Fatalf("decision %d: %v, want a conflict", i, err)
+			}
+		}
+		if winner < 0 {
+			t.Fatal("neither decision was recorded")
+		}
+
+		stored, err := f.messages.GetByID(context.Background(), message.ID)
+		if err != nil {
+			t.Fatalf("get message: %v", err)
+		}
+
+		want := decisions[winner].req.Status
+		if stored.Status != want {
+			t.Fatalf("status %s, want %s of the recorded decision", model.StatusName(stored.Status), model.StatusName(want))
+		}
+		if votes := stored.Steps[0].Votes; len(votes) != 1 || votes[0].CheckerID != decisions[winner].checker.ID {
+			t.Fatalf("votes %+v, want only the recorded decision", votes)
+		}
+	}
+}
+
+func TestVoteOnAMessageWithoutStepsStoresTheDefaultStep(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	// stored before approval chains existed
+	message, err := f.messages.Create(context.Background(), &model.Message{
+		SenderID:   sender.ID,
+		ReceiverID: receiver.ID,
+		Status:     model.MessageStatusPending,
+		Version:    1,
+	})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	accept := &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}
+
+	// a refused vote leaves the message as it was
+	if _, err := f.msgUC.Update(ownerCtx(sender), message.ID, accept); statusCode(err) != http.StatusForbidden {
+		t.Fatalf("vote of the sender: status %d, want %d", statusCode(err), http.StatusForbidden)
+	}
+	stored, err := f.messages.GetByID(context.Background(), message.ID)
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if stored.Version != message.Version || len(stored.Steps) != 0 {
+		t.Fatalf("refused vote wrote version %d with %d steps, want version %d without steps", stored.Version, len(stored.Steps), message.Version)
+	}
+
+	updated, err := f.msgUC.Update(ownerCtx(checker), message.ID, accept)
+	if err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if updated.Status != model.MessageStatusAccepted || len(updated.Steps) != 1 || len(updated.Steps[0].Votes) != 1 {
+		t.Errorf("status %s with steps %+v, want accepted with the vote on the default step", model.StatusName(updated.Status), updated.Steps)
+	}
+}
+
+func TestRejectionsOutsideTheCheckersListDontEndTheQuorum(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	first := f.user(t, model.RoleChecker)
+	second := f.user(t, model.RoleChecker)
+	third := f.user(t, model.RoleChecker)
+	escalation := []*model.User{f.user(t, model.RoleChecker, "ops"), f.user(t, model.RoleChecker, "ops")}
+
+	// the step was escalated to ops, whose checkers may vote besides the listed ones
+	message, err := f.messages.Create(context.Background(), &model.Message{
+		SenderID:   sender.ID,
+		ReceiverID: receiver.ID,
+		Status:     model.MessageStatusPending,
+		Version:    1,
+		Delivered:  true,
+		Steps: []model.ApprovalStep{{
+			Name:        "treasury",
+			Role:        model.RoleChecker,
+			Quorum:      2,
+			Checkers:    []string{first.ID, second.ID, third.ID},
+			EscalatedTo: "ops",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+
+	reject := &model.MessageUpdateRequest{Status: model.MessageStatusRejected, ReasonCode: "other", Comment: "no"}
+	for _, v := range escalation {
+		updated, err := f.msgUC.Update(ownerCtx(v), message.ID, reject)
+		if err != nil {
+			t.Fatalf("rejection of the escalation group: %v", err)
+		}
+		if updated.Status != model.MessageStatusPending {
+			t.Fatalf("status %s after a rejection outside the checkers list, want pending", model.StatusName(updated.Status))
+		}
+	}
+
+	accept := &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}
+	if _, err := f.msgUC.Update(ownerCtx(first), message.ID, accept); err != nil {
+		t.Fatalf("first approval: %v", err)
+	}
+	accepted, err := f.msgUC.Update(ownerCtx(second), message.ID, accept)
+	if err != nil {
+		t.Fatalf("second approval: %v", err)
+	}
+	if accepted.Status != model.MessageStatusAccepted {
+		t.Errorf("status %s, want accepted by the quorum of listed checkers", model.StatusName(accepted.Status))
+	}
+}
+
+func TestOpenPoolStepIsRejectedByItsRejections(t *testing.T) {
+	chains := model.ApprovalChains{
+		model.DefaultMessageType: {{Name: "review", Role: model.RoleChecker, Quorum: 2}},
+		"contract":               {{Name: "legal", Role: model.RoleChecker, Quorum: 2, Rejections: 2}},
+	}
+	f := newMsgFixture(t, model.AssignmentNone, chains)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	first := f.user(t, model.RoleChecker)
+	second := f.user(t, model.RoleChecker)
+
+	reject := &model.MessageUpdateRequest{Status: model.MessageStatusRejected, ReasonCode: "other", Comment: "no"}
+
+	// by default a single rejection rejects the step, even though it needs two approvals
+	message := f.send(t, sender, receiver, "")
+	rejected, err := f.msgUC.Update(ownerCtx(first), message.ID, reject)
+	if err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if rejected.Status != model.MessageStatusRejected {
+		t.Errorf("default step: status %s after one rejection, want rejected", model.StatusName(rejected.Status))
+	}
+
+	contract := f.send(t, sender, receiver, "contract")
+	pending, err := f.msgUC.Update(ownerCtx(first), contract.ID, reject)
+	if err != nil {
+		t.Fatalf("first rejection: %v", err)
+	}
+	if pending.Status != model.MessageStatusPending {
+		t.Errorf("step with 2 rejections: status %s after one rejection, want pending", model.StatusName(pending.Status))
+	}
+	rejected, err = f.msgUC.Update(ownerCtx(second), contract.ID, reject)
+	if err != nil {
+		t.Fatalf("second rejection: %v", err)
+	}
+	if rejected.Status != model.MessageStatusRejected {
+		t.Errorf("step with 2 rejections: status %s after two rejections, want rejected", model.StatusName(rejected.Status))
+	}
+}
+
+func TestResubmitIsRefusedOnAStaleVersion(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	message := f.send(t, sender, receiver, "")
+	changes := &model.MessageUpdateRequest{Status: model.MessageStatusChangesRequested, Comment: "add the invoice number"}
+	sentBack, err := f.msgUC.Update(ownerCtx(checker), message.ID, changes)
+	if err != nil {
+		t.Fatalf("request changes: %v", err)
+	}
+
+	// the sender edited the message before it was sent back
+	stale := &model.MessageResubmitRequest{Text: "invoice 42", Version: message.Version}
+	if _, err := f.msgUC.Resubmit(ownerCtx(sender), message.ID, stale); !isConflict(err) {
+		t.Fatalf("resubmit of version %d: %v, want a version conflict", message.Version, err)
+	}
+
+	resubmitted, err := f.msgUC.Resubmit(ownerCtx(sender), message.ID, &model.MessageResubmitRequest{Text: "invoice 42", Version: sentBack.Version})
+	if err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	if resubmitted.Status != model.MessageStatusPending {
+		t.Errorf("resubmitted: status %s, want pending", model.StatusName(resubmitted.Status))
+	}
+
+	// sent back again, the version the sender resubmitted before is stale
+	if _, err := f.msgUC.Update(ownerCtx(checker), message.ID, changes); err != nil {
+		t.Fatalf("request changes again: %v", err)
+	}
+	again := &model.MessageResubmitRequest{Text: "invoice 43", Version: sentBack.Version}
+	if _, err := f.msgUC.Resubmit(ownerCtx(sender), message.ID, again); !isConflict(err) {
+		t.Errorf("second resubmit of version %d: %v, want a version conflict", sentBack.Version, err)
+	}
+}
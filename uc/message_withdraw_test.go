@@ -0,0 +1,67 @@
+package uc
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestWithdrawOnlyLetsTheSenderRetractAnUndecidedMessage(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+	reviews := NewReviewUC(f.msgUC, time.Minute)
+
+	message := f.send(t, sender, receiver, "")
+
+	if _, err := f.msgUC.Withdraw(ownerCtx(checker), message.ID, &model.MessageWithdrawRequest{}); statusCode(err) != http.StatusForbidden {
+		t.Errorf("withdraw by a checker: status %d, want %d", statusCode(err), http.StatusForbidden)
+	}
+	if _, err := f.msgUC.Withdraw(ownerCtx(sender), message.ID, &model.MessageWithdrawRequest{Version: message.Version + 1}); !isConflict(err) {
+		t.Errorf("withdraw of another version: %v, want a conflict", err)
+	}
+
+	withdrawn, err := f.msgUC.Withdraw(ownerCtx(sender), message.ID, &model.MessageWithdrawRequest{Version: message.Version})
+	if err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if withdrawn.Status != model.MessageStatusWithdrawn || withdrawn.DeletedAt.IsZero() {
+		t.Errorf("withdrawn: status %s deleted at %v, want withdrawn with a deletion time", model.StatusName(withdrawn.Status), withdrawn.DeletedAt)
+	}
+
+	queue, err := reviews.Queue(ownerCtx(checker), model.PaginationOpts{Limit: 10})
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	if len(queue) != 0 {
+		t.Errorf("queue: %d messages, want the withdrawn one left out", len(queue))
+	}
+
+	// the sender keeps it in their history, and it can't be withdrawn twice or decided on
+	stored, err := f.msgUC.GetByID(ownerCtx(sender), message.ID)
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if stored.Status != model.MessageStatusWithdrawn {
+		t.Errorf("stored: status %s, want withdrawn", model.StatusName(stored.Status))
+	}
+	if _, err := f.msgUC.Withdraw(ownerCtx(sender), message.ID, &model.MessageWithdrawRequest{}); statusCode(err) != http.StatusConflict {
+		t.Errorf("second withdrawal: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+	if _, err := f.msgUC.Update(ownerCtx(checker), message.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); statusCode(err) != http.StatusConflict {
+		t.Errorf("vote on the withdrawn message: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+
+	// a decided message can't be withdrawn anymore
+	accepted := f.send(t, sender, receiver, "")
+	if _, err := f.msgUC.Update(ownerCtx(checker), accepted.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if _, err := f.msgUC.Withdraw(ownerCtx(sender), accepted.ID, &model.MessageWithdrawRequest{}); statusCode(err) != http.StatusConflict {
+		t.Errorf("withdraw of an accepted message: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+}
@@ -0,0 +1,149 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+)
+
+// ApprovalPolicy decides how a message that is submitted for review is reviewed: accepted right away, reviewed
+// through its approval chain, or reviewed by a checker group. Match lists the rules matching the message in the
+// order they are evaluated, the first one decides, and a message without matches is reviewed as usual.
+type ApprovalPolicy interface {
+	Match(ctx context.Context, subject *PolicySubject) ([]model.PolicyMatch, error)
+}
+
+// PolicySubject is a message together with its sender and receiver, as the approval policies see it
+type PolicySubject struct {
+	Message  *model.Message
+	Sender   *model.User
+	Receiver *model.User
+}
+
+// RulePolicy evaluates the configured auto-approval rules
+type RulePolicy struct {
+	policy model.AutoApprovalPolicy
+}
+
+func NewRulePolicy(policy model.AutoApprovalPolicy) *RulePolicy {
+	return &RulePolicy{
+		policy: policy,
+	}
+}
+
+func (rc *RulePolicy) Match(_ context.Context, subject *PolicySubject) ([]model.PolicyMatch, error) {
+	return rc.policy.Matches(subject.Sender, subject.Receiver, subject.Message), nil
+}
+
+// PolicyChain asks each policy in turn, the matches of the first policy come first
+type PolicyChain []ApprovalPolicy
+
+func (rc PolicyChain) Match(ctx context.Context, subject *PolicySubject) ([]model.PolicyMatch, error) {
+	var matches []model.PolicyMatch
+	for _, v := range rc {
+		policyMatches, err := v.Match(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, policyMatches...)
+	}
+
+	return matches, nil
+}
+
+// newPolicySubject looks up the sender and receiver of the message
+func newPolicySubject(ctx context.Context, userRepo interfaces.UserInterfaces, message *model.Message) (*PolicySubject, error) {
+	sender, err := userRepo.GetByID(ctx, message.SenderID)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to find sender", http.StatusInternalServerError)
+	}
+
+	receiver, err := userRepo.GetByID(ctx, message.ReceiverID)
+	if err != nil {
+		return nil, pkg.NewError(err, "receiver not found", http.StatusBadRequest)
+	}
+
+	return &PolicySubject{Message: message, Sender: sender, Receiver: receiver}, nil
+}
+
+// applyPolicy routes the message by the decision of the approval policy. An auto-accepted message gets a system vote
+// on every approval step and is accepted right away, unless its workflow doesn't allow it: then the decision is
+// recorded as skipped and the message is reviewed as usual. A decision with a group restricts the steps without a
+// checker group of their own to that group. It returns every rule that matched the message.
+func (rc *MsgUC) applyPolicy(ctx context.Context, message *model.Message, decidedAt time.Time) ([]model.PolicyMatch, error) {
+	message.Policy = nil
+	if rc.policy == nil {
+		return nil, nil
+	}
+
+	subject, err := newPolicySubject(ctx, rc.userRepo, message)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := rc.policy.Match(ctx, subject)
+	if err != nil || len(matches) == 0 {
+		return matches, err
+	}
+
+	decision := matches[0].PolicyDecision
+	message.Policy = &decision
+
+	switch decision.Action {
+	case model.PolicyActionAccept:
+		workflow, err := rc.workflows.Of(message)
+		if err != nil {
+			return nil, err
+		}
+
+		// the workflow doesn't let the system accept the message, it is reviewed as usual
+		if workflow.ValidateTransition(message.Status, model.MessageStatusAccepted, model.ActorSystem) != nil {
+			message.Policy.Skipped = true
+			break
+		}
+
+		for i := range message.Steps {
+			message.Steps[i].Votes = append(slices.Clone(message.Steps[i].Votes), model.ApprovalDecision{
+				DecidedAt: decidedAt,
+				CheckerID: model.SystemActorID,
+				Comment:   "accepted by auto approval rule " + decision.RuleID,
+				RuleID:    decision.RuleID,
+				Status:    model.MessageStatusAccepted,
+			})
+		}
+		message.CurrentStep = len(message.Steps) - 1
+		message.Status = model.MessageStatusAccepted
+	default:
+		if decision.Group == "" {
+			break
+		}
+		// steps that name a checker group of their own keep it
+		for i := range message.Steps {
+			if message.Steps[i].Group == "" {
+				message.Steps[i].Group = decision.Group
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// auditAutoApproval records the system decision of an auto-accepted message
+func (rc *MsgUC) auditAutoApproval(ctx context.Context, message *model.Message) error {
+	if message.Status != model.MessageStatusAccepted || message.Policy == nil {
+		return nil
+	}
+
+	return rc.audit.Record(ctx, model.AuditEvent{
+		ActorID:      model.SystemActorID,
+		Action:       model.AuditActionMessageAutoApprove,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   message.ID,
+		After:        message,
+	})
+}
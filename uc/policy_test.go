@@ -0,0 +1,79 @@
+package uc
+
+import (
+	"testing"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestAcceptRuleFallsBackToReviewWhenTheWorkflowForbidsIt(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+
+	// invoice version 1 has no transition from pending to accepted by the system
+	workflows := model.Workflows{invoiceWorkflow(1)}
+	f.msgUC.workflows = NewWorkflows(workflows, model.DefaultApprovalChains(), nil, nil, nil)
+
+	policy := model.AutoApprovalPolicy{Rules: []model.AutoApprovalRule{{ID: "everything", Action: model.PolicyActionAccept}}}
+	f.msgUC.policy = NewRulePolicy(policy)
+
+	accepted := f.send(t, sender, receiver, "")
+	if accepted.Status != model.MessageStatusAccepted || accepted.Policy == nil || accepted.Policy.Skipped {
+		t.Errorf("default type: status %s with decision %+v, want accepted by the rule", model.StatusName(accepted.Status), accepted.Policy)
+	}
+
+	reviewed := f.send(t, sender, receiver, "invoice")
+	if reviewed.Status != model.MessageStatusPending {
+		t.Errorf("invoice: status %s, want pending", model.StatusName(reviewed.Status))
+	}
+	if reviewed.Policy == nil || reviewed.Policy.RuleID != "everything" || !reviewed.Policy.Skipped {
+		t.Errorf("invoice: decision %+v, want the rule recorded as skipped", reviewed.Policy)
+	}
+	for _, v := range reviewed.Steps {
+		if len(v.Votes) != 0 {
+			t.Errorf("invoice: step %s has votes %+v, want none", v.Name, v.Votes)
+		}
+	}
+
+	// a rule that only covers invoices could never accept anything
+	policy.Rules[0].MessageTypes = []string{"invoice"}
+	if err := policy.ValidateWorkflows(workflows); err == nil {
+		t.Error("accept rule for invoices only: want an error")
+	}
+	policy.Rules[0].MessageTypes = []string{"invoice", model.DefaultMessageType}
+	if err := policy.ValidateWorkflows(workflows); err != nil {
+		t.Errorf("accept rule for invoices and the default type: %v", err)
+	}
+}
+
+func TestSeniorRuleKeepsTheGroupsOfTheChain(t *testing.T) {
+	chains := model.ApprovalChains{
+		"payment": {
+			{Name: "team-lead", Role: model.RoleChecker, Group: "team-leads"},
+			{Name: "review", Role: model.RoleChecker},
+		},
+	}
+	f := newMsgFixture(t, model.AssignmentNone, chains)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+
+	policy := model.AutoApprovalPolicy{
+		Rules:       []model.AutoApprovalRule{{ID: "payments", Action: model.PolicyActionSenior}},
+		SeniorGroup: "seniors",
+	}
+	f.msgUC.policy = NewRulePolicy(policy)
+
+	message := f.send(t, sender, receiver, "payment")
+	if message.Status != model.MessageStatusPending || len(message.Steps) != 2 {
+		t.Fatalf("status %s with %d steps, want pending with 2 steps", model.StatusName(message.Status), len(message.Steps))
+	}
+	if got := message.Steps[0].Group; got != "team-leads" {
+		t.Errorf("step with its own group: group %q, want %q", got, "team-leads")
+	}
+	if got := message.Steps[1].Group; got != "seniors" {
+		t.Errorf("step without a group: group %q, want %q", got, "seniors")
+	}
+}
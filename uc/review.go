@@ -0,0 +1,225 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+	"github.com/fleimkeipa/maker-checker/util"
+)
+
+// reviewQueueBatch is the number of pending messages read at once while building a checker's queue
+const reviewQueueBatch = 100
+
+// ReviewUC serves the checkers' work queue and the review leases on pending messages
+type ReviewUC struct {
+	messages *MsgUC
+	leaseTTL time.Duration
+}
+
+func NewReviewUC(messages *MsgUC, leaseTTL time.Duration) *ReviewUC {
+	return &ReviewUC{
+		messages: messages,
+		leaseTTL: leaseTTL,
+	}
+}
+
+// Queue lists the pending messages the caller may decide on, oldest first. Messages leased by another checker are left out,
+// and so are messages assigned to another checker, unless their current step needs more than one approval.
+func (rc *ReviewUC) Queue(ctx context.Context, opts model.PaginationOpts) ([]model.Message, error) {
+	checkerID := util.GetOwnerIDFromCtx(ctx)
+
+	checker, err := rc.messages.userRepo.GetByID(ctx, checkerID)
+	if err != nil {
+		return nil, pkg.NewError(err, "checker not found", http.StatusNotFound)
+	}
+
+	queue := make([]model.Message, 0, opts.Limit)
+	skipped := uint(0)
+	for offset := uint(0); ; offset += reviewQueueBatch {
+		candidates, err := rc.messages.msgRepo.ListQueue(ctx, model.ReviewQueueOpts{
+			PaginationOpts: model.PaginationOpts{Skip: offset, Limit: reviewQueueBatch},
+			CheckerID:      checkerID,
+		})
+		if err != nil {
+			return nil, pkg.NewError(err, "failed to list the review queue", http.StatusInternalServerError)
+		}
+
+		senders, err := rc.senders(ctx, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range candidates {
+			sender, ok := senders[candidates[i].SenderID]
+			if !ok {
+				continue
+			}
+
+			ok, err := rc.canDecideAs(ctx, checker, sender, &candidates[i])
+			if err != nil {
+				return nil, err
+			}
+			if !ok || isAssignedToOther(checkerID, &candidates[i]) {
+				continue
+			}
+
+			if skipped < opts.Skip {
+				skipped++
+				continue
+			}
+
+			candidates[i].AllowedTransitions = rc.messages.workflows.AllowedTransitions(&candidates[i])
+			queue = append(queue, candidates[i])
+			if uint(len(queue)) >= opts.Limit {
+				return queue, nil
+			}
+		}
+
+		if len(candidates) < reviewQueueBatch {
+			return queue, nil
+		}
+	}
+}
+
+// Claim takes a review lease on a pending message for the caller, or renews the caller's lease
+func (rc *ReviewUC) Claim(ctx context.Context, messageID string) (*model.Message, error) {
+	message, err := rc.messages.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.Status != model.MessageStatusPending {
+		return nil, pkg.NewErrorWithReason(nil, "only pending messages can be claimed", model.TransitionReasonNotAllowed, http.StatusConflict)
+	}
+
+	checkerID := util.GetOwnerIDFromCtx(ctx)
+
+	ok, err := rc.canDecide(ctx, checkerID, message)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, pkg.NewError(nil, "you are not allowed to decide on this message", http.StatusForbidden)
+	}
+
+	now := time.Now()
+	claimed, err := rc.messages.msgRepo.Claim(ctx, messageID, &model.ReviewClaim{
+		ClaimedAt: now,
+		ExpiresAt: now.Add(rc.leaseTTL),
+		CheckerID: checkerID,
+	})
+	if errors.Is(err, interfaces.ErrMessageClaimed) {
+		return nil, pkg.NewError(err, "message is no longer pending or is claimed by another checker", http.StatusConflict)
+	}
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to claim message", http.StatusInternalServerError)
+	}
+
+	claimed.AllowedTransitions = rc.messages.workflows.AllowedTransitions(claimed)
+
+	return claimed, nil
+}
+
+// Release gives up the caller's review lease on a message
+func (rc *ReviewUC) Release(ctx context.Context, messageID string) error {
+	// message exist control
+	if _, err := rc.messages.GetByID(ctx, messageID); err != nil {
+		return err
+	}
+
+	err := rc.messages.msgRepo.Release(ctx, messageID, util.GetOwnerIDFromCtx(ctx))
+	if errors.Is(err, interfaces.ErrMessageClaimed) {
+		return pkg.NewError(err, "you hold no claim on this message", http.StatusConflict)
+	}
+	if err != nil {
+		return pkg.NewError(err, "failed to release message", http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// canDecide reports whether the checker may vote on the current step of the pending message.
+// Only unexpected failures are returned as errors.
+func (rc *ReviewUC) canDecide(ctx context.Context, checkerID string, message *model.Message) (bool, error) {
+	// a checker or sender that no longer exists only means the checker can't decide
+	checker, err := rc.messages.userRepo.GetByID(ctx, checkerID)
+	if err != nil {
+		return false, nil
+	}
+
+	sender, err := rc.messages.userRepo.GetByID(ctx, message.SenderID)
+	if err != nil {
+		return false, nil
+	}
+
+	return rc.canDecideAs(ctx, checker, sender, message)
+}
+
+// canDecideAs is canDecide for a checker and a sender that are already loaded
+func (rc *ReviewUC) canDecideAs(ctx context.Context, checker, sender *model.User, message *model.Message) (bool, error) {
+	if message.Status != model.MessageStatusPending {
+		return false, nil
+	}
+
+	// messages created before approval chains existed have a single default step
+	if len(message.Steps) == 0 {
+		message.Steps, _ = rc.messages.workflows.chains.For(model.DefaultMessageType)
+	}
+
+	if err := rc.messages.conflicts.CheckUsers(ctx, checker, sender, message); err != nil {
+		return false, unlessInternal(err)
+	}
+
+	step, err := stepFor(checker, message)
+	if err != nil {
+		return false, unlessInternal(err)
+	}
+
+	return !step.HasVoted(checker.ID), nil
+}
+
+// senders loads the senders of a batch of messages in a single query
+func (rc *ReviewUC) senders(ctx context.Context, messages []model.Message) (map[string]*model.User, error) {
+	senderIDs := make([]string, 0, len(messages))
+	for _, v := range messages {
+		senderIDs = append(senderIDs, v.SenderID)
+	}
+	slices.Sort(senderIDs)
+
+	users, err := rc.messages.userRepo.ListByIDs(ctx, slices.Compact(senderIDs))
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to find the message senders", http.StatusInternalServerError)
+	}
+
+	senders := make(map[string]*model.User, len(users))
+	for i := range users {
+		senders[users[i].ID] = &users[i]
+	}
+
+	return senders, nil
+}
+
+// isAssignedToOther reports whether the message waits for another checker alone
+func isAssignedToOther(checkerID string, message *model.Message) bool {
+	if message.AssigneeID == "" || message.AssigneeID == checkerID {
+		return false
+	}
+
+	return message.Steps[message.CurrentStep].RequiredApprovals() == 1
+}
+
+// unlessInternal drops errors that only mean the checker is not allowed, and keeps server errors
+func unlessInternal(err error) error {
+	var pe *pkg.Error
+	if errors.As(err, &pe) && pe.StatusCode() < http.StatusInternalServerError {
+		return nil
+	}
+
+	return err
+}
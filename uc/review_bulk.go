@@ -0,0 +1,204 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+)
+
+// maxBulkReviewItems bounds the number of messages of a single bulk decision
+const maxBulkReviewItems = 500
+
+// bulkVote is a vote of a bulk decision that passed the checks of a single decision
+type bulkVote struct {
+	before *model.Message
+	vote   *model.ApprovalDecision
+	write  *model.VoteWrite
+	index  int
+}
+
+// Bulk takes the same decision on many messages. Every message is checked on its own like in PATCH /messages/:id,
+// and the result of each message is returned in the order of the request. The votes are written together with the
+// outcomes of their steps in one bulk write, a message that changed meanwhile fails with a version conflict.
+func (rc *ReviewUC) Bulk(ctx context.Context, req *model.BulkReviewRequest) ([]model.BulkReviewResult, error) {
+	if len(req.Items) == 0 {
+		return nil, pkg.NewError(nil, "items are required", http.StatusBadRequest)
+	}
+
+	if len(req.Items) > maxBulkReviewItems {
+		return nil, pkg.NewError(nil, fmt.Sprintf("at most %d messages can be decided at once", maxBulkReviewItems), http.StatusBadRequest)
+	}
+
+	decision := model.MessageUpdateRequest{
+		ReasonCode: req.ReasonCode,
+		Comment:    req.Comment,
+		Status:     req.Status,
+		OnBehalfOf: req.OnBehalfOf,
+	}
+
+	// a decision that is invalid on its own would fail on every message
+	if err := rc.messages.validateDecision(&decision); err != nil {
+		return nil, err
+	}
+
+	results := make([]model.BulkReviewResult, len(req.Items))
+	votes := make([]bulkVote, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		results[i].MessageID = item.MessageID
+
+		if seen[item.MessageID] {
+			results[i] = bulkFailure(item.MessageID, pkg.NewError(nil, "message is listed more than once", http.StatusBadRequest))
+			continue
+		}
+		seen[item.MessageID] = true
+
+		itemReq := decision
+		itemReq.Version = item.Version
+
+		message, vote, err := rc.messages.prepareVote(ctx, item.MessageID, &itemReq)
+		if err != nil {
+			results[i] = bulkFailure(item.MessageID, err)
+			continue
+		}
+
+		// the vote is recognized again after the bulk write, mongo stores milliseconds
+		vote.DecidedAt = vote.DecidedAt.Truncate(time.Millisecond)
+
+		write, err := rc.messages.decide(ctx, message, vote)
+		if err != nil {
+			results[i] = bulkFailure(item.MessageID, err)
+			continue
+		}
+
+		votes = append(votes, bulkVote{before: message, vote: vote, write: write, index: i})
+	}
+
+	if len(votes) == 0 {
+		return results, nil
+	}
+
+	voted, err := rc.addVotes(ctx, votes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range votes {
+		after, ok := voted[v.before.ID]
+		if !ok {
+			results[v.index] = bulkFailure(v.before.ID, &model.VersionConflictError{
+				Resource: model.AuditResourceMessage,
+				ID:       v.before.ID,
+				Version:  v.before.Version,
+			})
+			continue
+		}
+
+		after.AllowedTransitions = rc.messages.workflows.AllowedTransitions(after)
+
+		if err := rc.messages.auditVote(ctx, v.before, after, v.vote); err != nil {
+			return nil, err
+		}
+
+		if after.Status != v.before.Status {
+			rc.messages.workflows.Notify(ctx, after)
+		}
+
+		results[v.index] = model.BulkReviewResult{
+			Message:    after,
+			MessageID:  v.before.ID,
+			StatusCode: http.StatusOK,
+			Success:    true,
+		}
+	}
+
+	return results, nil
+}
+
+// addVotes records the votes with the outcomes of their steps in one bulk write and returns the messages whose
+// vote was recorded, as they are stored afterwards
+func (rc *ReviewUC) addVotes(ctx context.Context, votes []bulkVote) (map[string]*model.Message, error) {
+	writes := make([]model.VoteWrite, 0, len(votes))
+	for _, v := range votes {
+		writes = append(writes, *v.write)
+	}
+
+	if err := rc.messages.msgRepo.AddVotes(ctx, writes); err != nil {
+		return nil, pkg.NewError(err, "failed to record votes", http.StatusInternalServerError)
+	}
+
+	messages, err := rc.readMessages(ctx, votes)
+	if err != nil {
+		return nil, err
+	}
+
+	voted := make(map[string]*model.Message, len(votes))
+	for _, v := range votes {
+		message, ok := messages[v.before.ID]
+		if ok && hasRecordedVote(message, v.before.CurrentStep, v.vote) {
+			voted[v.before.ID] = message
+		}
+	}
+
+	return voted, nil
+}
+
+// readMessages reads the messages of the votes in one query
+func (rc *ReviewUC) readMessages(ctx context.Context, votes []bulkVote) (map[string]*model.Message, error) {
+	ids := make([]string, 0, len(votes))
+	for _, v := range votes {
+		ids = append(ids, v.before.ID)
+	}
+
+	messages, err := rc.messages.msgRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to read messages", http.StatusInternalServerError)
+	}
+
+	res := make(map[string]*model.Message, len(messages))
+	for i := range messages {
+		res[messages[i].ID] = &messages[i]
+	}
+
+	return res, nil
+}
+
+// hasRecordedVote reports whether the vote is recorded on the step of the message
+func hasRecordedVote(message *model.Message, step int, vote *model.ApprovalDecision) bool {
+	if step >= len(message.Steps) {
+		return false
+	}
+
+	return slices.ContainsFunc(message.Steps[step].Votes, func(v model.ApprovalDecision) bool {
+		return v.CheckerID == vote.CheckerID && v.DecidedAt.Equal(vote.DecidedAt)
+	})
+}
+
+// bulkFailure is the result of a message whose decision failed, with the status and reason PATCH /messages/:id would have answered with
+func bulkFailure(messageID string, err error) model.BulkReviewResult {
+	result := model.BulkReviewResult{
+		MessageID:  messageID,
+		Error:      err.Error(),
+		StatusCode: http.StatusInternalServerError,
+	}
+
+	var pe *pkg.Error
+	var ce *model.VersionConflictError
+	switch {
+	case errors.As(err, &ce):
+		result.StatusCode = http.StatusConflict
+		result.Reason = model.ConflictReasonVersion
+	case errors.As(err, &pe):
+		result.Error = pe.Message()
+		result.Reason = pe.Reason()
+		result.StatusCode = pe.StatusCode()
+	}
+
+	return result
+}
@@ -0,0 +1,96 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestBulkReviewReportsEveryItemOnItsOwn(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	// appeals can only be rejected, accepted is not one of their states
+	appeals := model.Workflows{{
+		Name:         "appeal",
+		Version:      1,
+		MessageTypes: []string{"appeal"},
+		States:       []string{"pending", "rejected"},
+		Transitions:  []model.WorkflowTransition{{From: "pending", To: "rejected", Actor: model.ActorChecker}},
+		Steps:        []model.ApprovalStep{{Name: "legal", Role: model.RoleChecker}},
+	}}
+	if err := appeals.Validate(); err != nil {
+		t.Fatalf("validate workflows: %v", err)
+	}
+	f.msgUC.workflows = NewWorkflows(appeals, model.DefaultApprovalChains(), nil, nil, nil)
+
+	accepted := f.send(t, sender, receiver, "")
+	own := f.send(t, checker, receiver, "")
+	stale := f.send(t, sender, receiver, "")
+	appeal := f.send(t, sender, receiver, "appeal")
+
+	reviews := NewReviewUC(f.msgUC, time.Minute)
+	results, err := reviews.Bulk(ownerCtx(checker), &model.BulkReviewRequest{
+		Status: model.MessageStatusAccepted,
+		Items: []model.BulkReviewItem{
+			{MessageID: accepted.ID},
+			{MessageID: own.ID},
+			{MessageID: stale.ID, Version: stale.Version + 1},
+			{MessageID: appeal.ID},
+			{MessageID: accepted.ID},
+		},
+	})
+	if err != nil {
+		t.Fatalf("bulk: %v", err)
+	}
+
+	want := []struct {
+		messageID  string
+		statusCode int
+	}{
+		{accepted.ID, http.StatusOK},
+		{own.ID, http.StatusForbidden},
+		{stale.ID, http.StatusConflict},
+		{appeal.ID, http.StatusUnprocessableEntity},
+		{accepted.ID, http.StatusBadRequest},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("%d results, want %d", len(results), len(want))
+	}
+	for i, v := range want {
+		got := results[i]
+		if got.MessageID != v.messageID || got.StatusCode != v.statusCode || got.Success != (v.statusCode == http.StatusOK) {
+			t.Errorf("item %d: %+v, want message %s with status %d", i, got, v.messageID, v.statusCode)
+		}
+	}
+	if results[2].Reason != model.ConflictReasonVersion {
+		t.Errorf("stale item: reason %q, want %q", results[2].Reason, model.ConflictReasonVersion)
+	}
+	if results[3].Reason != model.TransitionReasonUnknownStatus {
+		t.Errorf("appeal item: reason %q, want %q", results[3].Reason, model.TransitionReasonUnknownStatus)
+	}
+
+	// only the first listing of the accepted message voted, the failed items are left as they were
+	stored, err := f.messages.GetByID(context.Background(), accepted.ID)
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if stored.Status != model.MessageStatusAccepted || len(stored.Steps[0].Votes) != 1 {
+		t.Errorf("accepted message: status %s with votes %+v, want accepted with one vote", model.StatusName(stored.Status), stored.Steps[0].Votes)
+	}
+	for _, v := range []*model.Message{own, stale, appeal} {
+		stored, err := f.messages.GetByID(context.Background(), v.ID)
+		if err != nil {
+			t.Fatalf("get message: %v", err)
+		}
+		if stored.Status != model.MessageStatusPending || stored.Version != v.Version {
+			t.Errorf("message %s: status %s at version %d, want pending at version %d", v.ID, model.StatusName(stored.Status), stored.Version, v.Version)
+		}
+	}
+}
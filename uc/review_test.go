@@ -0,0 +1,85 @@
+package uc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+func TestClaimLeasesAMessageUntilItExpires(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+	reviews := NewReviewUC(f.msgUC, time.Minute)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	holder := f.user(t, model.RoleChecker)
+	other := f.user(t, model.RoleChecker)
+
+	message := f.send(t, sender, receiver, "")
+	accept := &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}
+
+	claimed, err := reviews.Claim(ownerCtx(holder), message.ID)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if claimed.Claim == nil || claimed.Claim.CheckerID != holder.ID {
+		t.Fatalf("claim %+v, want a lease of %s", claimed.Claim, holder.ID)
+	}
+
+	// while the lease is active nobody else can claim, see or decide on the message, the holder can renew it
+	if _, err := reviews.Claim(ownerCtx(other), message.ID); statusCode(err) != http.StatusConflict {
+		t.Errorf("claim by another checker: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+	if queue, err := reviews.Queue(ownerCtx(other), model.PaginationOpts{Limit: 10}); err != nil || slices.Contains(messageIDs(queue), message.ID) {
+		t.Errorf("queue of another checker: %v, %v, want the leased message left out", messageIDs(queue), err)
+	}
+	if _, err := f.msgUC.Update(ownerCtx(other), message.ID, accept); statusCode(err) != http.StatusConflict {
+		t.Errorf("vote of another checker: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+	if _, err := reviews.Claim(ownerCtx(holder), message.ID); err != nil {
+		t.Errorf("renew: %v", err)
+	}
+
+	// the lease runs out
+	past := time.Now().Add(-2 * time.Minute)
+	if _, err := f.messages.Claim(context.Background(), message.ID, &model.ReviewClaim{
+		ClaimedAt: past,
+		ExpiresAt: past.Add(time.Minute),
+		CheckerID: holder.ID,
+	}); err != nil {
+		t.Fatalf("expire lease: %v", err)
+	}
+
+	if queue, err := reviews.Queue(ownerCtx(other), model.PaginationOpts{Limit: 10}); err != nil || !slices.Contains(messageIDs(queue), message.ID) {
+		t.Errorf("queue after expiry: %v, %v, want the message back", messageIDs(queue), err)
+	}
+	if _, err := reviews.Claim(ownerCtx(other), message.ID); err != nil {
+		t.Fatalf("claim after expiry: %v", err)
+	}
+	if err := reviews.Release(ownerCtx(holder), message.ID); statusCode(err) != http.StatusConflict {
+		t.Errorf("release of the lost lease: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+
+	accepted, err := f.msgUC.Update(ownerCtx(other), message.ID, accept)
+	if err != nil {
+		t.Fatalf("vote of the new holder: %v", err)
+	}
+	if accepted.Status != model.MessageStatusAccepted || accepted.Claim != nil {
+		t.Errorf("status %s with claim %+v, want accepted and released", model.StatusName(accepted.Status), accepted.Claim)
+	}
+
+	// a checker may not claim a message they may not decide on
+	own := f.send(t, holder, receiver, "")
+	if _, err := reviews.Claim(ownerCtx(holder), own.ID); statusCode(err) != http.StatusForbidden {
+		t.Errorf("claim of an own message: status %d, want %d", statusCode(err), http.StatusForbidden)
+	}
+
+	// a decided message can't be claimed
+	if _, err := reviews.Claim(ownerCtx(holder), message.ID); statusCode(err) != http.StatusConflict {
+		t.Errorf("claim of the accepted message: status %d, want %d", statusCode(err), http.StatusConflict)
+	}
+}
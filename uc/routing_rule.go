@@ -0,0 +1,468 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/expr"
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
+	"github.com/fleimkeipa/maker-checker/util"
+)
+
+// routingFields are the message fields routing rule expressions can refer to
+var routingFields = expr.Fields{
+	"text":              expr.TypeString,
+	"type":              expr.TypeString,
+	"sender.id":         expr.TypeString,
+	"sender.username":   expr.TypeString,
+	"sender.role":       expr.TypeString,
+	"sender.groups":     expr.TypeList,
+	"sender.team":       expr.TypeString,
+	"receiver.id":       expr.TypeString,
+	"receiver.username": expr.TypeString,
+	"receiver.role":     expr.TypeString,
+	"receiver.groups":   expr.TypeList,
+	"receiver.team":     expr.TypeString,
+}
+
+// defaultSimulatedMessages is the number of recent messages a draft routing rule is simulated against by default
+const defaultSimulatedMessages = 100
+
+// RoutingRuleUC manages the routing rules, every expression is compiled before it is stored.
+// Draft rules are simulated with the routing rules of expressions and the configured fallback policy.
+type RoutingRuleUC struct {
+	repo        interfaces.RoutingRuleInterfaces
+	msgRepo     interfaces.MessageInterfaces
+	userRepo    interfaces.UserInterfaces
+	audit       *AuditUC
+	expressions *ExpressionPolicy
+	fallback    ApprovalPolicy
+}
+
+func NewRoutingRuleUC(repo interfaces.RoutingRuleInterfaces, msgRepo interfaces.MessageInterfaces, userRepo interfaces.UserInterfaces, audit *AuditUC, expressions *ExpressionPolicy, fallback ApprovalPolicy) *RoutingRuleUC {
+	return &RoutingRuleUC{
+		repo:        repo,
+		msgRepo:     msgRepo,
+		userRepo:    userRepo,
+		audit:       audit,
+		expressions: expressions,
+		fallback:    fallback,
+	}
+}
+
+func (rc *RoutingRuleUC) Create(ctx context.Context, req *model.RoutingRuleCreateRequest) (*model.RoutingRule, error) {
+	now := time.Now()
+	ownerID := util.GetOwnerIDFromCtx(ctx)
+
+	rule := model.RoutingRule{
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Name:       req.Name,
+		Expression: req.Expression,
+		Action:     req.Action,
+		Group:      req.Group,
+		Priority:   req.Priority,
+		Version:    1,
+		CreatedBy:  ownerID,
+		UpdatedBy:  ownerID,
+	}
+
+	if err := validateRoutingRule(&rule); err != nil {
+		return nil, err
+	}
+
+	newRule, err := rc.repo.Create(ctx, &rule)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to create routing rule", http.StatusInternalServerError)
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionRoutingRuleCreate,
+		ResourceType: model.AuditResourceRoutingRule,
+		ResourceID:   newRule.ID,
+		After:        newRule,
+	}); err != nil {
+		return nil, err
+	}
+
+	return newRule, nil
+}
+
+// Update replaces the rule with a new version
+func (rc *RoutingRuleUC) Update(ctx context.Context, ruleID string, req *model.RoutingRuleUpdateRequest) (*model.RoutingRule, error) {
+	rule, err := rc.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *rule
+
+	rule.Name = req.Name
+	rule.Expression = req.Expression
+	rule.Action = req.Action
+	rule.Group = req.Group
+	rule.Priority = req.Priority
+
+	if err := validateRoutingRule(rule); err != nil {
+		return nil, err
+	}
+
+	return rc.write(ctx, &before, rule, req.Version, model.AuditActionRoutingRuleUpdate)
+}
+
+// SetDisabled disables or enables the rule, a disabled rule is skipped when messages are routed
+func (rc *RoutingRuleUC) SetDisabled(ctx context.Context, ruleID string, disabled bool, version int) (*model.RoutingRule, error) {
+	rule, err := rc.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Disabled == disabled {
+		return nil, pkg.NewError(nil, fmt.Sprintf("routing rule is already %s", enabledState(disabled)), http.StatusConflict)
+	}
+
+	before := *rule
+	rule.Disabled = disabled
+
+	action := model.AuditActionRoutingRuleEnable
+	if disabled {
+		action = model.AuditActionRoutingRuleDisable
+	}
+
+	return rc.write(ctx, &before, rule, version, action)
+}
+
+func (rc *RoutingRuleUC) GetByID(ctx context.Context, ruleID string) (*model.RoutingRule, error) {
+	rule, err := rc.repo.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, pkg.NewError(err, "routing rule not found", http.StatusNotFound)
+	}
+
+	return rule, nil
+}
+
+// List lists every rule in the order they are evaluated in
+func (rc *RoutingRuleUC) List(ctx context.Context) ([]model.RoutingRule, error) {
+	rules, err := rc.repo.List(ctx)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to list routing rules", http.StatusInternalServerError)
+	}
+
+	return rules, nil
+}
+
+// Versions lists every version of the rule, oldest first
+func (rc *RoutingRuleUC) Versions(ctx context.Context, ruleID string) ([]model.RoutingRule, error) {
+	if _, err := rc.GetByID(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	versions, err := rc.repo.Versions(ctx, ruleID)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to list routing rule versions", http.StatusInternalServerError)
+	}
+
+	return versions, nil
+}
+
+// Simulate evaluates the draft rule, as if it was enabled, against the most recent submitted messages. For every
+// message it compares the decision of the current rules with the decision once the draft rule is added, nothing is stored.
+func (rc *RoutingRuleUC) Simulate(ctx context.Context, req *model.RoutingRuleSimulateRequest) (*model.RoutingRuleSimulation, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSimulatedMessages
+	}
+	if limit < 0 || limit > model.MaxSimulatedMessages {
+		return nil, pkg.NewError(nil, fmt.Sprintf("limit must be between 1 and %d", model.MaxSimulatedMessages), http.StatusBadRequest)
+	}
+
+	draft := model.RoutingRule{
+		ID:         model.DraftRoutingRuleID,
+		Name:       req.Rule.Name,
+		Expression: req.Rule.Expression,
+		Action:     req.Rule.Action,
+		Group:      req.Rule.Group,
+		Priority:   req.Rule.Priority,
+	}
+	if err := validateRoutingRule(&draft); err != nil {
+		return nil, err
+	}
+
+	rules, err := rc.repo.ListEnabled(ctx)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to list routing rules", http.StatusInternalServerError)
+	}
+
+	// a new rule comes after the stored rules of the same priority
+	at := len(rules)
+	for i, v := range rules {
+		if v.Priority > draft.Priority {
+			at = i
+			break
+		}
+	}
+	withDraft := slices.Insert(slices.Clone(rules), at, draft)
+
+	current, err := rc.expressions.matcher(rules)
+	if err != nil {
+		return nil, err
+	}
+	simulated, err := rc.expressions.matcher(withDraft)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := rc.msgRepo.ListRecent(ctx, limit)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to list messages", http.StatusInternalServerError)
+	}
+
+	simulation := model.RoutingRuleSimulation{
+		Rule:     &draft,
+		Messages: len(messages),
+		Results:  make([]model.RoutingRuleSimulationResult, 0, len(messages)),
+	}
+	for i := range messages {
+		result := model.RoutingRuleSimulationResult{MessageID: messages[i].ID}
+
+		subject, err := newPolicySubject(ctx, rc.userRepo, &messages[i])
+		if err == nil {
+			var fallback []model.PolicyMatch
+			fallback, err = rc.fallback.Match(ctx, subject)
+
+			simulatedMatches := simulated(subject)
+			result.Matched = slices.ContainsFunc(simulatedMatches, func(v model.PolicyMatch) bool {
+				return v.RuleID == model.DraftRoutingRuleID
+			})
+			result.Current = firstDecision(append(current(subject), fallback...))
+			result.Simulated = firstDecision(append(simulatedMatches, fallback...))
+			result.Changed = !sameDecision(result.Current, result.Simulated)
+		}
+		if err != nil {
+			result = model.RoutingRuleSimulationResult{MessageID: messages[i].ID, Error: errorMessage(err)}
+		}
+
+		if result.Matched {
+			simulation.Matched++
+		}
+		if result.Changed {
+			simulation.Changed++
+		}
+		simulation.Results = append(simulation.Results, result)
+	}
+
+	return &simulation, nil
+}
+
+// write stores the changed rule as its next version, unless the rule changed since the version the admin read
+func (rc *RoutingRuleUC) write(ctx context.Context, before, rule *model.RoutingRule, version int, action string) (*model.RoutingRule, error) {
+	if version != 0 && version != rule.Version {
+		return nil, &model.VersionConflictError{
+			Resource: model.AuditResourceRoutingRule,
+			ID:       rule.ID,
+			Version:  version,
+		}
+	}
+
+	rule.UpdatedAt = time.Now()
+	rule.UpdatedBy = util.GetOwnerIDFromCtx(ctx)
+
+	if err := rc.repo.Update(ctx, rule); err != nil {
+		return nil, writeError(err, "failed to update routing rule")
+	}
+
+	rule.Version++
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       action,
+		ResourceType: model.AuditResourceRoutingRule,
+		ResourceID:   rule.ID,
+		Before:       before,
+		After:        rule,
+	}); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// validateRoutingRule checks the action and group of the rule and compiles its expression
+func validateRoutingRule(rule *model.RoutingRule) error {
+	if rule.Name == "" {
+		return pkg.NewError(nil, "name is required", http.StatusBadRequest)
+	}
+
+	if !model.IsValidPolicyAction(rule.Action) {
+		return pkg.NewError(nil, "action must be one of accept, checker and senior_checker", http.StatusBadRequest)
+	}
+
+	if rule.Action == model.PolicyActionSenior && rule.Group == "" {
+		return pkg.NewError(nil, "group is required for senior_checker rules", http.StatusBadRequest)
+	}
+
+	if rule.Action == model.PolicyActionAccept && rule.Group != "" {
+		return pkg.NewError(nil, "accept rules don't route to a group", http.StatusBadRequest)
+	}
+
+	if _, err := expr.Compile(rule.Expression, routingFields); err != nil {
+		return pkg.NewError(err, "invalid expression", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func firstDecision(matches []model.PolicyMatch) *model.PolicyDecision {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	return &matches[0].PolicyDecision
+}
+
+func sameDecision(a, b *model.PolicyDecision) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// errorMessage returns the message of a use case error, or the error itself
+func errorMessage(err error) string {
+	var pe *pkg.Error
+	if errors.As(err, &pe) {
+		return pe.Message()
+	}
+
+	return err.Error()
+}
+
+func enabledState(disabled bool) string {
+	if disabled {
+		return "disabled"
+	}
+
+	return "enabled"
+}
+
+// ExpressionPolicy evaluates the enabled routing rules by priority, the first rule whose expression matches decides.
+// The rules are read on every evaluation, so changes apply to the next message right away, and each rule version
+// is compiled only once.
+type ExpressionPolicy struct {
+	repo interfaces.RoutingRuleInterfaces
+
+	mu       sync.Mutex
+	programs map[string]*expr.Program
+}
+
+func NewExpressionPolicy(repo interfaces.RoutingRuleInterfaces) *ExpressionPolicy {
+	return &ExpressionPolicy{
+		repo:     repo,
+		programs: map[string]*expr.Program{},
+	}
+}
+
+func (rc *ExpressionPolicy) Match(ctx context.Context, subject *PolicySubject) ([]model.PolicyMatch, error) {
+	rules, err := rc.repo.ListEnabled(ctx)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to list routing rules", http.StatusInternalServerError)
+	}
+
+	match, err := rc.matcher(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return match(subject), nil
+}
+
+// matcher compiles the rules and returns the function listing the rules that match a subject, in the order of the rules.
+// Stored rule versions are compiled once, versions that are no longer enabled are dropped from the cache.
+func (rc *ExpressionPolicy) matcher(rules []model.RoutingRule) (func(subject *PolicySubject) []model.PolicyMatch, error) {
+	programs, err := rc.compile(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(subject *PolicySubject) []model.PolicyMatch {
+		env := routingEnv(subject)
+
+		var matches []model.PolicyMatch
+		for i, rule := range rules {
+			if !programs[i].Eval(env) {
+				continue
+			}
+
+			matches = append(matches, model.PolicyMatch{
+				PolicyDecision: model.PolicyDecision{
+					RuleID:      rule.ID,
+					RuleVersion: rule.Version,
+					Action:      rule.Action,
+					Group:       rule.Group,
+				},
+				Source:     model.PolicySourceRoutingRule,
+				Name:       rule.Name,
+				Expression: rule.Expression,
+			})
+		}
+
+		return matches
+	}, nil
+}
+
+func (rc *ExpressionPolicy) compile(rules []model.RoutingRule) ([]*expr.Program, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	compiled := make(map[string]*expr.Program, len(rules))
+	programs := make([]*expr.Program, 0, len(rules))
+	for _, v := range rules {
+		key := fmt.Sprintf("%s@%d", v.ID, v.Version)
+
+		program, ok := rc.programs[key]
+		if !ok {
+			var err error
+			// expressions are compiled when they are saved, this only fails for rules stored by an incompatible release
+			program, err = expr.Compile(v.Expression, routingFields)
+			if err != nil {
+				return nil, pkg.NewError(err, fmt.Sprintf("routing rule %s has an invalid expression", v.ID), http.StatusInternalServerError)
+			}
+		}
+
+		// rules that are not stored yet, like the rule of a simulation, are not cached
+		if v.ID != model.DraftRoutingRuleID {
+			compiled[key] = program
+		}
+		programs = append(programs, program)
+	}
+	rc.programs = compiled
+
+	return programs, nil
+}
+
+// routingEnv returns the values of the routing fields for the message of the subject
+func routingEnv(subject *PolicySubject) expr.Env {
+	sender, receiver, message := subject.Sender, subject.Receiver, subject.Message
+
+	return expr.Env{
+		"text":              message.Text,
+		"type":              messageTypeOrDefault(message.Type),
+		"sender.id":         sender.ID,
+		"sender.username":   sender.Username,
+		"sender.role":       sender.Role,
+		"sender.groups":     sender.Groups,
+		"sender.team":       sender.Team,
+		"receiver.id":       receiver.ID,
+		"receiver.username": receiver.Username,
+		"receiver.role":     receiver.Role,
+		"receiver.groups":   receiver.Groups,
+		"receiver.team":     receiver.Team,
+	}
+}
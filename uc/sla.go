@@ -0,0 +1,262 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+)
+
+// slaBatch is the number of due messages read at once by the SLA scheduler
+const slaBatch = 100
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, notification model.Notification) error
+}
+
+// SLAScheduler looks at the pending messages whose SLA needs attention: it reminds their checkers, escalates the
+// current step to the fallback checker group once the deadline passed, and expires messages that are pending for too long.
+// Every escalation is recorded on the message at most once, so several schedulers can run side by side.
+type SLAScheduler struct {
+	messages *MsgUC
+	notifier Notifier
+	interval time.Duration
+}
+
+func NewSLAScheduler(messages *MsgUC, notifier Notifier, interval time.Duration) *SLAScheduler {
+	return &SLAScheduler{
+		messages: messages,
+		notifier: notifier,
+		interval: interval,
+	}
+}
+
+// Run checks the due messages on every interval until the context is done, failed checks are passed to onError
+func (rc *SLAScheduler) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(rc.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := rc.Tick(ctx, now); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Tick handles the escalations that are due at the given time. Each message is looked at once per tick, so the
+// messages that stay due, because they failed or another scheduler got to them first, don't hold up the others.
+// Failed messages are retried on the next tick, as are messages with a second escalation due.
+func (rc *SLAScheduler) Tick(ctx context.Context, now time.Time) error {
+	processedIDs := make([]string, 0)
+	var errs []error
+	for {
+		messages, err := rc.messages.msgRepo.ListDue(ctx, now, processedIDs, slaBatch)
+		if err != nil {
+			errs = append(errs, pkg.NewError(err, "failed to list due messages", http.StatusInternalServerError))
+			return errors.Join(errs...)
+		}
+
+		for i := range messages {
+			processedIDs = append(processedIDs, messages[i].ID)
+			if err := rc.escalate(ctx, &messages[i], now); err != nil {
+				errs = append(errs, fmt.Errorf("message %s: %w", messages[i].ID, err))
+			}
+		}
+
+		if len(messages) < slaBatch {
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// escalate records the escalation that is due on the message and notifies the users concerned
+func (rc *SLAScheduler) escalate(ctx context.Context, message *model.Message, now time.Time) error {
+	if message.SLA == nil {
+		return nil
+	}
+
+	esc := message.SLA.Due(now, message.Escalations)
+	if esc == nil {
+		return nil
+	}
+	esc.CreatedAt = now
+	esc.Step = message.CurrentStep
+
+	nextCheckAt := message.SLA.Next(append(slices.Clone(message.Escalations), *esc))
+
+	switch esc.Level {
+	case model.EscalationExpiry:
+		return rc.expire(ctx, message, esc)
+	case model.EscalationBreach:
+		return rc.breach(ctx, message, esc, nextCheckAt)
+	default:
+		return rc.remind(ctx, message, esc, nextCheckAt)
+	}
+}
+
+// remind reminds the assignee of the message, or every checker who may decide on it if nobody is assigned
+func (rc *SLAScheduler) remind(ctx context.Context, message *model.Message, esc *model.Escalation, nextCheckAt time.Time) error {
+	esc.AssigneeID = message.AssigneeID
+
+	recipientIDs := []string{message.AssigneeID}
+	if message.AssigneeID == "" {
+		candidates, err := rc.messages.assigner.Candidates(ctx, message)
+		if err != nil {
+			return err
+		}
+		recipientIDs = userIDs(candidates)
+	}
+
+	recorded, err := rc.messages.msgRepo.RecordEscalation(ctx, message.ID, esc, nextCheckAt)
+	if err != nil || !recorded {
+		return err
+	}
+
+	rc.notify(ctx, message, model.NotificationSLAReminder, recipientIDs,
+		"message is due at "+message.SLA.DueAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// breach hands the current step over to the escalation group, and assigns it to one of the group's checkers
+func (rc *SLAScheduler) breach(ctx context.Context, message *model.Message, esc *model.Escalation, nextCheckAt time.Time) error {
+	if message.CurrentStep >= len(message.Steps) {
+		return nil
+	}
+
+	before := *message
+	before.Steps = slices.Clone(message.Steps)
+
+	escalated := *message
+	escalated.Steps = slices.Clone(message.Steps)
+	escalated.Steps[escalated.CurrentStep].EscalatedTo = esc.Group
+
+	candidates, err := rc.messages.assigner.Candidates(ctx, &escalated)
+	if err != nil {
+		return err
+	}
+
+	// without an escalation group the breach is only reported to the checkers of the step
+	if esc.Group != "" {
+		candidates = slices.DeleteFunc(candidates, func(v model.User) bool {
+			return !v.InGroup(esc.Group)
+		})
+
+		esc.AssigneeID, err = rc.messages.assigner.AssignAmong(ctx, &escalated, candidates)
+		if err != nil {
+			return err
+		}
+	}
+
+	recorded, err := rc.messages.msgRepo.RecordEscalation(ctx, message.ID, esc, nextCheckAt)
+	if err != nil || !recorded {
+		return err
+	}
+
+	escalated.Escalations = append(slices.Clone(message.Escalations), *esc)
+	sla := *message.SLA
+	sla.Breached = true
+	sla.NextCheckAt = nextCheckAt
+	escalated.SLA = &sla
+	if esc.AssigneeID != "" {
+		escalated.AssigneeID = esc.AssigneeID
+	}
+
+	if err := rc.messages.audit.Record(ctx, model.AuditEvent{
+		ActorID:      model.SystemActorID,
+		Action:       model.AuditActionMessageEscalate,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   message.ID,
+		Before:       &before,
+		After:        &escalated,
+	}); err != nil {
+		return err
+	}
+
+	rc.notify(ctx, message, model.NotificationSLABreach, userIDs(candidates),
+		"message missed its deadline of "+message.SLA.DueAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// expire moves the message to the expired status, a decision that came in first wins
+func (rc *SLAScheduler) expire(ctx context.Context, message *model.Message, esc *model.Escalation) error {
+	if err := rc.messages.workflows.ValidateTransition(message, model.MessageStatusExpired, model.ActorSystem); err != nil {
+		return err
+	}
+
+	err := rc.messages.msgRepo.Finalize(ctx, message.ID, message.Version, message.CurrentStep, model.MessageStatusExpired, message.CurrentStep)
+	var ce *model.VersionConflictError
+	if errors.As(err, &ce) {
+		return nil
+	}
+	if err != nil {
+		return pkg.NewError(err, "failed to expire message", http.StatusInternalServerError)
+	}
+
+	if _, err := rc.messages.msgRepo.RecordEscalation(ctx, message.ID, esc, time.Time{}); err != nil {
+		return err
+	}
+
+	expired := *message
+	expired.Status = model.MessageStatusExpired
+	expired.Version++
+	expired.Escalations = append(slices.Clone(message.Escalations), *esc)
+	expired.AllowedTransitions = rc.messages.workflows.AllowedTransitions(&expired)
+
+	if err := rc.messages.audit.Record(ctx, model.AuditEvent{
+		ActorID:      model.SystemActorID,
+		Action:       model.AuditActionMessageExpire,
+		ResourceType: model.AuditResourceMessage,
+		ResourceID:   message.ID,
+		Before:       message,
+		After:        &expired,
+	}); err != nil {
+		return err
+	}
+
+	rc.messages.workflows.Notify(ctx, &expired)
+	rc.notify(ctx, message, model.NotificationSLAExpiry, []string{message.SenderID},
+		"message expired at "+esc.DueAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// notify sends a notification about an escalation that is recorded already, a failure is reported like the failed
+// notifications of the workflow hooks
+func (rc *SLAScheduler) notify(ctx context.Context, message *model.Message, kind string, recipientIDs []string, text string) {
+	recipientIDs = slices.DeleteFunc(recipientIDs, func(v string) bool { return v == "" })
+	if len(recipientIDs) == 0 {
+		return
+	}
+
+	if err := rc.notifier.Notify(ctx, model.Notification{
+		CreatedAt:    time.Now(),
+		MessageID:    message.ID,
+		Kind:         kind,
+		RecipientIDs: recipientIDs,
+		Text:         text,
+	}); err != nil {
+		rc.messages.workflows.notifyFailed(message, err)
+	}
+}
+
+func userIDs(users []model.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, v := range users {
+		ids = append(ids, v.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,131 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+// failingEscalations fails recording the escalations of one message for the given number of times
+type failingEscalations struct {
+	*memoryMessageRepo
+	messageID string
+	failures  int
+}
+
+func (rc *failingEscalations) RecordEscalation(ctx context.Context, messageID string, esc *model.Escalation, nextCheckAt time.Time) (bool, error) {
+	if messageID == rc.messageID && rc.failures > 0 {
+		rc.failures--
+		return false, errors.New("write failed")
+	}
+
+	return rc.memoryMessageRepo.RecordEscalation(ctx, messageID, esc, nextCheckAt)
+}
+
+func newSLAFixture(t *testing.T) (*msgFixture, *SLAScheduler) {
+	t.Helper()
+
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+	slas := model.SLAPolicies{
+		model.DefaultMessageType: {Deadline: time.Hour, Reminders: []time.Duration{30 * time.Minute}},
+	}
+	f.msgUC.workflows = NewWorkflows(nil, model.DefaultApprovalChains(), slas, nil, nil)
+
+	return f, NewSLAScheduler(f.msgUC, &recordingNotifier{}, time.Minute)
+}
+
+func reminded(t *testing.T, f *msgFixture, messageID string) bool {
+	t.Helper()
+
+	message, err := f.messages.GetByID(context.Background(), messageID)
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+
+	for _, v := range message.Escalations {
+		if v.Level == model.EscalationReminder {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestSLATickWorksThroughEveryDueBatch(t *testing.T) {
+	f, scheduler := newSLAFixture(t)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	f.user(t, model.RoleChecker)
+
+	// a full batch of messages that are looked at first but have nothing due, they stay due after the tick
+	checkedAt := time.Now().Add(-time.Hour)
+	for range slaBatch {
+		if _, err := f.messages.Create(context.Background(), &model.Message{
+			SenderID:   sender.ID,
+			ReceiverID: receiver.ID,
+			Status:     model.MessageStatusPending,
+			SLA:        &model.MessageSLA{DueAt: time.Now().Add(24 * time.Hour), NextCheckAt: checkedAt},
+		}); err != nil {
+			t.Fatalf("create message: %v", err)
+		}
+	}
+
+	messages := make([]*model.Message, 0, slaBatch+1)
+	for range slaBatch + 1 {
+		messages = append(messages, f.send(t, sender, receiver, ""))
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- scheduler.Tick(context.Background(), time.Now().Add(45*time.Minute))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tick did not return")
+	}
+
+	for _, v := range messages {
+		if !reminded(t, f, v.ID) {
+			t.Fatalf("message %s was not reminded", v.ID)
+		}
+	}
+}
+
+func TestSLATickRetriesFailedMessagesOnTheNextTick(t *testing.T) {
+	f, scheduler := newSLAFixture(t)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	f.user(t, model.RoleChecker)
+
+	failing := f.send(t, sender, receiver, "")
+	other := f.send(t, sender, receiver, "")
+	f.msgUC.msgRepo = &failingEscalations{memoryMessageRepo: f.messages, messageID: failing.ID, failures: 1}
+
+	now := time.Now().Add(45 * time.Minute)
+	if err := scheduler.Tick(context.Background(), now); err == nil {
+		t.Fatal("first tick: want the failed message reported")
+	}
+	if reminded(t, f, failing.ID) {
+		t.Error("failed message was reminded")
+	}
+	if !reminded(t, f, other.ID) {
+		t.Error("other message was not reminded after a failure in the same tick")
+	}
+
+	if err := scheduler.Tick(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatalf("second tick: %v", err)
+	}
+	if !reminded(t, f, failing.ID) {
+		t.Error("failed message was not retried on the next tick")
+	}
+}
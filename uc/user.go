@@ -2,9 +2,11 @@ package uc
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/fleimkeipa/maker-checker/changerequest"
 	"github.com/fleimkeipa/maker-checker/model"
 	"github.com/fleimkeipa/maker-checker/pkg"
 	"github.com/fleimkeipa/maker-checker/repositories/interfaces"
@@ -12,62 +14,61 @@ import (
 
 type UserUC struct {
 	userRepo interfaces.UserInterfaces
+	changes  *changerequest.Engine
+	audit    *AuditUC
 }
 
-func NewUserUC(repo interfaces.UserInterfaces) *UserUC {
+func NewUserUC(repo interfaces.UserInterfaces, changes *changerequest.Engine, audit *AuditUC) *UserUC {
 	return &UserUC{
 		userRepo: repo,
+		changes:  changes,
+		audit:    audit,
 	}
 }
 
+// Create creates a user right away, it is used for self registration and the initial admin.
+// Admins go through RequestCreate instead.
 func (rc *UserUC) Create(ctx context.Context, req model.UserCreateRequest) (*model.User, error) {
-	user := model.User{
-		Username: req.Username,
-		Email:    req.Email,
-		Password: req.Password,
+	if req.Password == "" {
+		return nil, pkg.NewError(nil, "password is required", http.StatusBadRequest)
 	}
 
-	hashedPassword, err := model.HashPassword(req.Password)
+	user, err := newUserPayload(req)
 	if err != nil {
-		return nil, pkg.NewError(err, "failed to hash password", http.StatusInternalServerError)
+		return nil, err
 	}
-	user.Password = hashedPassword
 
-	user.CreatedAt = time.Now()
+	return rc.applyCreate(ctx, user)
+}
 
-	newUser, err := rc.userRepo.Create(ctx, &user)
-	if err != nil {
-		return nil, pkg.NewError(err, "failed to create user", http.StatusInternalServerError)
+// RequestCreate proposes a new user, which is created once another admin approves the change request
+func (rc *UserUC) RequestCreate(ctx context.Context, req model.UserCreateRequest) (*model.ChangeRequest, error) {
+	if req.Password == "" {
+		return nil, pkg.NewError(nil, "password is required", http.StatusBadRequest)
 	}
 
-	return newUser, nil
-}
-
-func (rc *UserUC) Update(ctx context.Context, userID string, req model.UserCreateRequest) (*model.User, error) {
-	// user exist control
-	_, err := rc.GetByID(ctx, userID)
+	user, err := newUserPayload(req)
 	if err != nil {
 		return nil, err
 	}
 
-	user := model.User{
-		Username: req.Username,
-		Email:    req.Email,
-		Password: req.Password,
-	}
+	return rc.changes.Submit(ctx, model.ChangeResourceUser, "", model.ChangeOperationCreate, user)
+}
 
-	hashedPassword, err := model.HashPassword(req.Password)
+// RequestUpdate proposes new values for the fields present in the request, which are applied once another admin
+// approves the change request. The omitted fields keep the values the user has when the change is applied.
+func (rc *UserUC) RequestUpdate(ctx context.Context, userID string, req model.UserUpdateRequest) (*model.ChangeRequest, error) {
+	update, err := newUserUpdatePayload(req)
 	if err != nil {
-		return nil, pkg.NewError(err, "failed to hash password", http.StatusInternalServerError)
+		return nil, err
 	}
-	user.Password = hashedPassword
 
-	updatedUser, err := rc.userRepo.Update(ctx, userID, &user)
-	if err != nil {
-		return nil, pkg.NewError(err, "failed to update user", http.StatusInternalServerError)
-	}
+	return rc.changes.Submit(ctx, model.ChangeResourceUser, userID, model.ChangeOperationUpdate, update)
+}
 
-	return updatedUser, nil
+// RequestDelete proposes to delete a user, who is deleted once another admin approves the change request
+func (rc *UserUC) RequestDelete(ctx context.Context, userID string) (*model.ChangeRequest, error) {
+	return rc.changes.Submit(ctx, model.ChangeResourceUser, userID, model.ChangeOperationDelete, nil)
 }
 
 func (rc *UserUC) GetByID(ctx context.Context, id string) (*model.User, error) {
@@ -88,6 +89,38 @@ func (rc *UserUC) GetByUsernameOrEmail(ctx context.Context, usernameOrEmail stri
 	return user, nil
 }
 
+// Login checks the credentials and records the attempt in the audit trail
+func (rc *UserUC) Login(ctx context.Context, req model.Login) (*model.User, error) {
+	user, err := rc.GetByUsernameOrEmail(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := model.ValidateUserPassword(user.Password, req.Password); err != nil {
+		if auditErr := rc.audit.Record(ctx, model.AuditEvent{
+			ActorID:      user.ID,
+			Action:       model.AuditActionUserLoginFailed,
+			ResourceType: model.AuditResourceUser,
+			ResourceID:   user.ID,
+		}); auditErr != nil {
+			return nil, auditErr
+		}
+
+		return nil, pkg.NewError(err, "Invalid password. Please check the password and try again.", http.StatusBadRequest)
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		ActorID:      user.ID,
+		Action:       model.AuditActionUserLogin,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   user.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 func (rc *UserUC) Exists(ctx context.Context, usernameOrEmail string) (bool, error) {
 	exists, err := rc.userRepo.Exists(ctx, usernameOrEmail)
 	if err != nil {
@@ -97,10 +130,159 @@ func (rc *UserUC) Exists(ctx context.Context, usernameOrEmail string) (bool, err
 	return exists, nil
 }
 
-func (rc *UserUC) Delete(ctx context.Context, userID string) error {
+// EnsureAdmin creates an admin user with the given credentials if no user with that username exists yet.
+func (rc *UserUC) EnsureAdmin(ctx context.Context, username, password string) error {
+	exists, err := rc.Exists(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	_, err = rc.Create(ctx, model.UserCreateRequest{
+		Username: username,
+		Password: password,
+		Role:     model.RoleAdmin,
+	})
+
+	return err
+}
+
+func (rc *UserUC) applyCreate(ctx context.Context, user *model.User) (*model.User, error) {
+	user.CreatedAt = time.Now()
+
+	newUser, err := rc.userRepo.Create(ctx, user)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to create user", http.StatusInternalServerError)
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionUserCreate,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   newUser.ID,
+		After:        userSnapshot(newUser),
+	}); err != nil {
+		return nil, err
+	}
+
+	return newUser, nil
+}
+
+func (rc *UserUC) applyUpdate(ctx context.Context, userID string, update *model.UserUpdateRequest) (*model.User, error) {
+	// user exist control
+	existing, err := rc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user := update.Apply(*existing)
+
+	updatedUser, err := rc.userRepo.Update(ctx, userID, &user)
+	if err != nil {
+		return nil, pkg.NewError(err, "failed to update user", http.StatusInternalServerError)
+	}
+
+	if err := rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionUserUpdate,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   userID,
+		Before:       userSnapshot(existing),
+		After:        userSnapshot(updatedUser),
+	}); err != nil {
+		return nil, err
+	}
+
+	return updatedUser, nil
+}
+
+func (rc *UserUC) applyDelete(ctx context.Context, userID string) error {
+	// user exist control
+	existing, err := rc.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
 	if err := rc.userRepo.Delete(ctx, userID); err != nil {
 		return pkg.NewError(err, "failed to delete user", http.StatusInternalServerError)
 	}
 
-	return nil
+	return rc.audit.Record(ctx, model.AuditEvent{
+		Action:       model.AuditActionUserDelete,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   userID,
+		Before:       userSnapshot(existing),
+	})
+}
+
+// newUserPayload validates the request and returns the user to store, with the password hashed
+func newUserPayload(req model.UserCreateRequest) (*model.User, error) {
+	role, err := userRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	user := model.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Role:     role,
+
+		RelatedUserIDs: req.RelatedUserIDs,
+		Groups:         req.Groups,
+		Team:           req.Team,
+	}
+
+	if req.Password != "" {
+		hashedPassword, err := model.HashPassword(req.Password)
+		if err != nil {
+			return nil, pkg.NewError(err, "failed to hash password", http.StatusInternalServerError)
+		}
+		user.Password = hashedPassword
+	}
+
+	return &user, nil
+}
+
+// newUserUpdatePayload validates the request and returns the update to store, with the password hashed
+func newUserUpdatePayload(req model.UserUpdateRequest) (*model.UserUpdateRequest, error) {
+	if req.IsEmpty() {
+		return nil, pkg.NewError(nil, "nothing to update", http.StatusBadRequest)
+	}
+
+	if req.Role != nil && !model.IsValidRole(*req.Role) {
+		return nil, pkg.NewError(errors.New("invalid role: "+*req.Role), "role must be one of maker, checker or admin", http.StatusBadRequest)
+	}
+
+	update := req
+	if req.Password != nil && *req.Password != "" {
+		hashedPassword, err := model.HashPassword(*req.Password)
+		if err != nil {
+			return nil, pkg.NewError(err, "failed to hash password", http.StatusInternalServerError)
+		}
+		update.Password = &hashedPassword
+	}
+
+	return &update, nil
+}
+
+// userRole returns the role to store for a new or updated user, defaulting to maker
+func userRole(role string) (string, error) {
+	if role == "" {
+		return model.RoleMaker, nil
+	}
+
+	if !model.IsValidRole(role) {
+		return "", pkg.NewError(errors.New("invalid role: "+role), "role must be one of maker, checker or admin", http.StatusBadRequest)
+	}
+
+	return role, nil
+}
+
+// userSnapshot returns a copy of the user without the password hash, for the audit trail
+func userSnapshot(user *model.User) *model.User {
+	snapshot := *user
+	snapshot.Password = ""
+
+	return &snapshot
 }
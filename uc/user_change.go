@@ -0,0 +1,119 @@
+package uc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+)
+
+// UserApplier applies approved user change requests. It is registered on the change request engine for the user resource type.
+type UserApplier struct {
+	users *UserUC
+}
+
+func NewUserApplier(users *UserUC) *UserApplier {
+	return &UserApplier{
+		users: users,
+	}
+}
+
+// Validate checks that the proposed user is unique on create, and that the user exists on update and delete
+func (rc *UserApplier) Validate(ctx context.Context, change *model.ChangeRequest) error {
+	switch change.Operation {
+	case model.ChangeOperationCreate:
+		user, err := decodeUserPayload(change)
+		if err != nil {
+			return err
+		}
+
+		exists, err := rc.users.Exists(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return pkg.NewError(nil, "User already exists. Please choose a different username.", http.StatusBadRequest)
+		}
+
+		return nil
+	case model.ChangeOperationUpdate:
+		if _, err := decodeUserUpdatePayload(change); err != nil {
+			return err
+		}
+
+		// user exist control
+		_, err := rc.users.GetByID(ctx, change.ResourceID)
+		return err
+	case model.ChangeOperationDelete:
+		// user exist control
+		_, err := rc.users.GetByID(ctx, change.ResourceID)
+		return err
+	}
+
+	return pkg.NewError(nil, "unknown user change operation: "+change.Operation, http.StatusUnprocessableEntity)
+}
+
+func (rc *UserApplier) Apply(ctx context.Context, change *model.ChangeRequest) error {
+	switch change.Operation {
+	case model.ChangeOperationCreate:
+		user, err := decodeUserPayload(change)
+		if err != nil {
+			return err
+		}
+
+		_, err = rc.users.applyCreate(ctx, user)
+		return err
+	case model.ChangeOperationUpdate:
+		update, err := decodeUserUpdatePayload(change)
+		if err != nil {
+			return err
+		}
+
+		_, err = rc.users.applyUpdate(ctx, change.ResourceID, update)
+		return err
+	case model.ChangeOperationDelete:
+		return rc.users.applyDelete(ctx, change.ResourceID)
+	}
+
+	return pkg.NewError(nil, "unknown user change operation: "+change.Operation, http.StatusUnprocessableEntity)
+}
+
+// Redact removes the proposed password hash from the user of a create and from the fields of an update
+func (rc *UserApplier) Redact(payload json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil || fields == nil {
+		return payload
+	}
+
+	delete(fields, "password")
+
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+
+	return redacted
+}
+
+func decodeUserPayload(change *model.ChangeRequest) (*model.User, error) {
+	var user model.User
+	if err := json.Unmarshal(change.Payload, &user); err != nil {
+		return nil, pkg.NewError(err, "invalid user change payload", http.StatusBadRequest)
+	}
+
+	return &user, nil
+}
+
+// decodeUserUpdatePayload decodes the fields of an update, payloads stored before partial updates hold the whole
+// user and replace every field but an empty password
+func decodeUserUpdatePayload(change *model.ChangeRequest) (*model.UserUpdateRequest, error) {
+	var update model.UserUpdateRequest
+	if err := json.Unmarshal(change.Payload, &update); err != nil {
+		return nil, pkg.NewError(err, "invalid user change payload", http.StatusBadRequest)
+	}
+
+	return &update, nil
+}
@@ -0,0 +1,173 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/fleimkeipa/maker-checker/model"
+	"github.com/fleimkeipa/maker-checker/pkg"
+)
+
+// defaultWorkflow is the state machine of the message types that no workflow definition covers
+var defaultWorkflow = model.DefaultWorkflow()
+
+// Workflows resolves the workflow definition a message follows and runs its notification hooks. Message types that
+// no definition covers follow the default workflow, with the approval chain and SLA policy of their type.
+type Workflows struct {
+	definitions model.Workflows
+	chains      model.ApprovalChains
+	slas        model.SLAPolicies
+	notifier    Notifier
+	// onNotifyError receives the notifications that could not be sent, it may be nil
+	onNotifyError func(error)
+}
+
+func NewWorkflows(definitions model.Workflows, chains model.ApprovalChains, slas model.SLAPolicies, notifier Notifier, onNotifyError func(error)) *Workflows {
+	return &Workflows{
+		definitions:   definitions,
+		chains:        chains,
+		slas:          slas,
+		notifier:      notifier,
+		onNotifyError: onNotifyError,
+	}
+}
+
+// Start returns the workflow a message of the type starts on, with a fresh copy of its approval steps
+func (rc *Workflows) Start(messageType string) (*model.Workflow, error) {
+	if definition, ok := rc.definitions.Latest(messageType); ok {
+		workflow := *definition
+		workflow.Steps = slices.Clone(definition.Steps)
+		return &workflow, nil
+	}
+
+	steps, ok := rc.chains.For(messageType)
+	if !ok {
+		return nil, pkg.NewError(errors.New("unknown message type: "+messageType), "message type has no workflow or approval chain", http.StatusBadRequest)
+	}
+
+	workflow := defaultWorkflow
+	workflow.Steps = steps
+	if policy, ok := rc.slas.For(messageType); ok {
+		workflow.SLA = &policy
+	}
+
+	return &workflow, nil
+}
+
+// Of returns the workflow version the message follows, messages stored before workflows existed follow the default workflow
+func (rc *Workflows) Of(message *model.Message) (*model.Workflow, error) {
+	ref := message.Workflow
+	if ref == nil || ref.Name == model.DefaultWorkflowName {
+		return &defaultWorkflow, nil
+	}
+
+	workflow, ok := rc.definitions.Get(ref.Name, ref.Version)
+	if !ok {
+		return nil, pkg.NewError(fmt.Errorf("workflow %q version %d is not defined", ref.Name, ref.Version), "the workflow of the message is no longer defined", http.StatusInternalServerError)
+	}
+
+	return workflow, nil
+}
+
+// HasMessageType reports whether messages of the type can be submitted
+func (rc *Workflows) HasMessageType(messageType string) bool {
+	if _, ok := rc.definitions.Latest(messageType); ok {
+		return true
+	}
+
+	_, ok := rc.chains.For(messageType)
+
+	return ok
+}
+
+// AllowedTransitions returns the next statuses the message can move to in its workflow, none if the workflow is gone
+func (rc *Workflows) AllowedTransitions(message *model.Message) []model.MessageTransition {
+	workflow, err := rc.Of(message)
+	if err != nil {
+		return []model.MessageTransition{}
+	}
+
+	return workflow.AllowedTransitions(message.Status)
+}
+
+// ValidateTransition checks the workflow of the message for a move to the given status by the actor
+func (rc *Workflows) ValidateTransition(message *model.Message, to int, actor string) error {
+	workflow, err := rc.Of(message)
+	if err != nil {
+		return err
+	}
+
+	return validateTransition(workflow, message.Status, to, actor)
+}
+
+// Notify runs the hooks of the workflow on the status the message entered. The message is stored in that status
+// already, so a failed notification is reported to onNotifyError and doesn't fail the change.
+func (rc *Workflows) Notify(ctx context.Context, message *model.Message) {
+	if rc.notifier == nil {
+		return
+	}
+
+	workflow, err := rc.Of(message)
+	if err != nil {
+		rc.notifyFailed(message, err)
+		return
+	}
+
+	recipientIDs := make([]string, 0)
+	for _, v := range workflow.Recipients(message.Status) {
+		recipientIDs = append(recipientIDs, hookRecipientIDs(v, message)...)
+	}
+
+	slices.Sort(recipientIDs)
+	recipientIDs = slices.Compact(recipientIDs)
+	recipientIDs = slices.DeleteFunc(recipientIDs, func(v string) bool { return v == "" || v == model.SystemActorID })
+	if len(recipientIDs) == 0 {
+		return
+	}
+
+	if err := rc.notifier.Notify(ctx, model.Notification{
+		CreatedAt:    time.Now(),
+		MessageID:    message.ID,
+		Kind:         model.NotificationWorkflowState,
+		RecipientIDs: recipientIDs,
+		Text:         "message is " + model.StatusName(message.Status),
+	}); err != nil {
+		rc.notifyFailed(message, err)
+	}
+}
+
+// notifyFailed reports a notification about the message that could not be sent
+func (rc *Workflows) notifyFailed(message *model.Message, err error) {
+	if rc.onNotifyError != nil {
+		rc.onNotifyError(fmt.Errorf("notify on message %s: %w", message.ID, err))
+	}
+}
+
+// hookRecipientIDs returns the users of the message a hook recipient stands for, the receiver only once the message is visible to them
+func hookRecipientIDs(recipient string, message *model.Message) []string {
+	switch recipient {
+	case model.HookRecipientSender:
+		return []string{message.SenderID}
+	case model.HookRecipientReceiver:
+		if isHiddenFrom(message.ReceiverID, message) {
+			return nil
+		}
+		return []string{message.ReceiverID}
+	case model.HookRecipientAssignee:
+		return []string{message.AssigneeID}
+	case model.HookRecipientCheckers:
+		ids := make([]string, 0)
+		for _, step := range message.Steps {
+			for _, v := range step.Votes {
+				ids = append(ids, v.CheckerID, v.DelegateID)
+			}
+		}
+		return ids
+	}
+
+	return nil
+}
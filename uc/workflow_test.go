@@ -0,0 +1,204 @@
+package uc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/fleimkeipa/maker-checker/model"
+)
+
+type recordingNotifier struct {
+	notifications []model.Notification
+}
+
+func (rc *recordingNotifier) Notify(_ context.Context, notification model.Notification) error {
+	rc.notifications = append(rc.notifications, notification)
+	return nil
+}
+
+type failingNotifier struct{}
+
+func (rc failingNotifier) Notify(context.Context, model.Notification) error {
+	return errors.New("notifier is down")
+}
+
+func invoiceWorkflow(version int, states ...string) model.Workflow {
+	workflow := model.Workflow{
+		Name:         "invoice",
+		Version:      version,
+		MessageTypes: []string{"invoice"},
+		States:       append([]string{"pending", "accepted", "rejected"}, states...),
+		Transitions: []model.WorkflowTransition{
+			{From: "pending", To: "accepted", Actor: model.ActorChecker},
+			{From: "pending", To: "rejected", Actor: model.ActorChecker},
+		},
+		Steps: []model.ApprovalStep{{Name: "finance", Role: model.RoleChecker}},
+	}
+
+	if slices.Contains(states, "changes_requested") {
+		workflow.Transitions = append(workflow.Transitions, model.WorkflowTransition{
+			From: "pending", To: "changes_requested", Actor: model.ActorChecker,
+		})
+	}
+
+	return workflow
+}
+
+func TestMessagesKeepTheWorkflowVersionTheyStartedUnder(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	v1 := model.Workflows{invoiceWorkflow(1)}
+	f.msgUC.workflows = NewWorkflows(v1, model.DefaultApprovalChains(), nil, nil, nil)
+	started := f.send(t, sender, receiver, "invoice")
+
+	// version 2 lets checkers send invoices back, it only applies to invoices submitted from now on
+	v2 := append(v1, invoiceWorkflow(2, "changes_requested"))
+	if err := v2.Validate(); err != nil {
+		t.Fatalf("validate workflows: %v", err)
+	}
+	f.msgUC.workflows = NewWorkflows(v2, model.DefaultApprovalChains(), nil, nil, nil)
+	submitted := f.send(t, sender, receiver, "invoice")
+
+	if *started.Workflow != (model.WorkflowRef{Name: "invoice", Version: 1}) {
+		t.Errorf("first message follows %+v, want invoice version 1", *started.Workflow)
+	}
+	if *submitted.Workflow != (model.WorkflowRef{Name: "invoice", Version: 2}) {
+		t.Errorf("second message follows %+v, want invoice version 2", *submitted.Workflow)
+	}
+
+	req := &model.MessageUpdateRequest{Status: model.MessageStatusChangesRequested, Comment: "add the order number"}
+
+	_, err := f.msgUC.Update(ownerCtx(checker), started.ID, req)
+	if statusCode(err) != http.StatusUnprocessableEntity {
+		t.Errorf("requesting changes under version 1: status %d, want %d", statusCode(err), http.StatusUnprocessableEntity)
+	}
+
+	updated, err := f.msgUC.Update(ownerCtx(checker), submitted.ID, req)
+	if err != nil {
+		t.Fatalf("requesting changes under version 2: %v", err)
+	}
+	if updated.Status != model.MessageStatusChangesRequested {
+		t.Errorf("status %s, want changes_requested", model.StatusName(updated.Status))
+	}
+}
+
+func TestWorkflowHooksNotifyOnEnteringAState(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	workflow := invoiceWorkflow(1)
+	workflow.Hooks = []model.WorkflowHook{
+		{State: "accepted", Notify: []string{model.HookRecipientSender, model.HookRecipientReceiver, model.HookRecipientCheckers}},
+		{State: "rejected", Notify: []string{model.HookRecipientSender}},
+	}
+	notifier := &recordingNotifier{}
+	f.msgUC.workflows = NewWorkflows(model.Workflows{workflow}, model.DefaultApprovalChains(), nil, notifier, nil)
+
+	message := f.send(t, sender, receiver, "invoice")
+	if len(notifier.notifications) != 0 {
+		t.Fatalf("%d notifications on pending, want none", len(notifier.notifications))
+	}
+
+	if _, err := f.msgUC.Update(ownerCtx(checker), message.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted}); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("%d notifications, want 1", len(notifier.notifications))
+	}
+
+	got := notifier.notifications[0]
+	want := []string{sender.ID, receiver.ID, checker.ID}
+	slices.Sort(want)
+	if got.Kind != model.NotificationWorkflowState || !slices.Equal(got.RecipientIDs, want) {
+		t.Errorf("notification %s to %v, want %s to %v", got.Kind, got.RecipientIDs, model.NotificationWorkflowState, want)
+	}
+}
+
+func TestWorkflowTransitionsDecideWhereMessagesCanMove(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	// rejected invoices can be corrected and resubmitted, a move the default workflow doesn't have
+	workflow := invoiceWorkflow(1)
+	workflow.Transitions = append(workflow.Transitions, model.WorkflowTransition{From: "rejected", To: "pending", Actor: model.ActorSender})
+	if err := (model.Workflows{workflow}).Validate(); err != nil {
+		t.Fatalf("validate workflows: %v", err)
+	}
+	f.msgUC.workflows = NewWorkflows(model.Workflows{workflow}, model.DefaultApprovalChains(), nil, nil, nil)
+
+	message := f.send(t, sender, receiver, "invoice")
+	reject := &model.MessageUpdateRequest{Status: model.MessageStatusRejected, ReasonCode: "other", Comment: "wrong amount"}
+	if _, err := f.msgUC.Update(ownerCtx(checker), message.ID, reject); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+
+	// the workflow has no withdrawn state
+	if _, err := f.msgUC.Withdraw(ownerCtx(sender), message.ID, &model.MessageWithdrawRequest{}); statusCode(err) != http.StatusUnprocessableEntity {
+		t.Errorf("withdraw: status %d, want %d", statusCode(err), http.StatusUnprocessableEntity)
+	}
+
+	resubmitted, err := f.msgUC.Resubmit(ownerCtx(sender), message.ID, &model.MessageResubmitRequest{Text: "corrected amount"})
+	if err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	if resubmitted.Status != model.MessageStatusPending || resubmitted.CurrentStep != 0 {
+		t.Errorf("resubmitted: status %s on step %d, want pending on the first step", model.StatusName(resubmitted.Status), resubmitted.CurrentStep)
+	}
+
+	// the default workflow lets the sender withdraw a message that was sent back
+	f.msgUC.workflows = NewWorkflows(nil, model.DefaultApprovalChains(), nil, nil, nil)
+	sentBack := f.send(t, sender, receiver, "")
+	changes := &model.MessageUpdateRequest{Status: model.MessageStatusChangesRequested, Comment: "add the invoice number"}
+	if _, err := f.msgUC.Update(ownerCtx(checker), sentBack.ID, changes); err != nil {
+		t.Fatalf("request changes: %v", err)
+	}
+	withdrawn, err := f.msgUC.Withdraw(ownerCtx(sender), sentBack.ID, &model.MessageWithdrawRequest{})
+	if err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if withdrawn.Status != model.MessageStatusWithdrawn {
+		t.Errorf("withdrawn: status %s, want withdrawn", model.StatusName(withdrawn.Status))
+	}
+}
+
+func TestFailedNotificationsDontFailTheStoredChange(t *testing.T) {
+	f := newMsgFixture(t, model.AssignmentNone, nil)
+
+	sender := f.user(t, model.RoleMaker)
+	receiver := f.user(t, model.RoleMaker)
+	checker := f.user(t, model.RoleChecker)
+
+	workflow := invoiceWorkflow(1)
+	workflow.Hooks = []model.WorkflowHook{{State: "accepted", Notify: []string{model.HookRecipientSender}}}
+
+	var failures []error
+	f.msgUC.workflows = NewWorkflows(model.Workflows{workflow}, model.DefaultApprovalChains(), nil, failingNotifier{}, func(err error) {
+		failures = append(failures, err)
+	})
+
+	message := f.send(t, sender, receiver, "invoice")
+	accepted, err := f.msgUC.Update(ownerCtx(checker), message.ID, &model.MessageUpdateRequest{Status: model.MessageStatusAccepted})
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if accepted.Status != model.MessageStatusAccepted {
+		t.Errorf("status %s, want accepted", model.StatusName(accepted.Status))
+	}
+	if len(failures) != 1 {
+		t.Errorf("%d failures reported, want the failed notification", len(failures))
+	}
+}
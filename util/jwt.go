@@ -22,6 +22,7 @@ func GenerateJWT(user *model.User) (string, error) {
 		"id":       user.ID,
 		"username": user.Username,
 		"email":    user.Email,
+		"role":     user.Role,
 		"iat":      time.Now().Unix(),
 		"eat":      time.Now().Add(time.Hour * 2).Unix(),
 	})
@@ -87,21 +88,37 @@ func GetOwnerFromToken(c echo.Context) (model.TokenOwner, error) {
 		return model.TokenOwner{}, errors.New("invalid email claims")
 	}
 
+	role, ok := claims["role"].(string)
+	if !ok {
+		return model.TokenOwner{}, errors.New("invalid role claims")
+	}
+
 	return model.TokenOwner{
 		ID:       id,
 		Username: username,
 		Email:    email,
+		Role:     role,
 	}, nil
 }
 
 // GetOwnerIDFromCtx returns the owner id from the context string type
 func GetOwnerIDFromCtx(ctx context.Context) string {
+	return GetOwnerFromCtx(ctx).ID
+}
+
+// GetOwnerRoleFromCtx returns the owner role from the context
+func GetOwnerRoleFromCtx(ctx context.Context) string {
+	return GetOwnerFromCtx(ctx).Role
+}
+
+// GetOwnerFromCtx returns the token owner stored on the context, or an empty owner if there is none
+func GetOwnerFromCtx(ctx context.Context) model.TokenOwner {
 	owner, ok := ctx.Value("user").(model.TokenOwner)
 	if ok {
-		return owner.ID
+		return owner
 	}
 
-	return ""
+	return model.TokenOwner{}
 }
 
 // check token validity
@@ -3,10 +3,18 @@ package util
 import (
 	"context"
 	"net/http"
+	"slices"
+
+	"github.com/fleimkeipa/maker-checker/model"
 
 	"github.com/labstack/echo/v4"
 )
 
+// OwnerLookup returns the stored user of a token owner
+type OwnerLookup interface {
+	GetByID(ctx context.Context, id string) (*model.User, error)
+}
+
 // check for valid user token
 func JWTAuthUser(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
@@ -23,6 +31,52 @@ func JWTAuthUser(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// CurrentOwner replaces the token owner on the context with the user as stored now, so that a role change or a
+// deletion approved after the token was issued takes effect right away. It must be registered after JWTAuthUser.
+func CurrentOwner(users OwnerLookup) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			user, err := users.GetByID(ctx, GetOwnerIDFromCtx(ctx))
+			if err != nil || !user.DeletedAt.IsZero() {
+				return c.JSON(http.StatusUnauthorized, echo.Map{
+					"message": "Authentication required",
+					"error":   "the user of the token no longer exists",
+				})
+			}
+
+			owner := model.TokenOwner{
+				ID:       user.ID,
+				Username: user.Username,
+				Email:    user.Email,
+				Role:     user.Role,
+			}
+			c.SetRequest(c.Request().WithContext(context.WithValue(ctx, "user", owner)))
+
+			return next(c)
+		}
+	}
+}
+
+// RequireRoles allows the request only if the token owner has one of the given roles.
+// It must be registered after JWTAuthUser.
+func RequireRoles(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role := GetOwnerRoleFromCtx(c.Request().Context())
+			if !slices.Contains(roles, role) {
+				return c.JSON(http.StatusForbidden, echo.Map{
+					"message": "Permission denied",
+					"error":   "role '" + role + "' is not allowed to perform this action",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
 func setOwnerOnCtx(c echo.Context) error {
 	user, err := GetOwnerFromToken(c)
 	if err != nil {
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestMeta stores the request id and the client ip on the request context.
+// It must be registered after echo's RequestID middleware.
+func RequestMeta(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		ctx = context.WithValue(ctx, "request_id", c.Response().Header().Get(echo.HeaderXRequestID))
+		ctx = context.WithValue(ctx, "client_ip", c.RealIP())
+
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+// GetRequestIDFromCtx returns the request id from the context
+func GetRequestIDFromCtx(ctx context.Context) string {
+	requestID, _ := ctx.Value("request_id").(string)
+	return requestID
+}
+
+// GetClientIPFromCtx returns the client ip from the context
+func GetClientIPFromCtx(ctx context.Context) string {
+	clientIP, _ := ctx.Value("client_ip").(string)
+	return clientIP
+}